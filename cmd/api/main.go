@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/api"
@@ -12,15 +16,19 @@ import (
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/cache"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
 	database "gitlab.ozon.dev/sadsnake2311/homework/internal/db"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/hashchain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/jwtkeys"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/middleware"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/orderevents"
 	auditrepo "gitlab.ozon.dev/sadsnake2311/homework/internal/repository/auditlogrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/authrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/orderrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/reportrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/userorderrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/router"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/scheduler"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage/postgres/auditlogstorage"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage/postgres/authstorage"
@@ -36,7 +44,10 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
 
 	baseLogger, err := zap.NewProduction()
 	logger := baseLogger.Sugar()
@@ -71,11 +82,12 @@ func main() {
 		logger.Error("failed to register metric", zap.Error(err))
 	}
 
-	orderStorage := orderstorage.NewOrderStorage(db)
-	userOrderStorage := userorder.NewUserOrderStorage(db)
+	orderStorage := orderstorage.NewOrderStorage(db, orderevents.Marshal)
+	userOrderStorage := userorder.NewUserOrderStorage(db, orderevents.Marshal)
 	reportStorage := reportorderstorage.NewReportOrderStorage(db)
 	authStorage := authstorage.NewAuthStorage(db)
-	auditStorage := auditlogstorage.NewAuditStorage(db)
+	auditChain := hashchain.New(cfg.AuditHashKey)
+	auditStorage := auditlogstorage.NewAuditStorage(db, auditChain)
 
 	orderRepo := orderrepo.NewOrderRepository(orderStorage, logger)
 	userRepo := userorderrepo.NewUserOrderRepository(userOrderStorage, logger)
@@ -84,43 +96,111 @@ func main() {
 	authRepo := authrepo.NewAuthRepository(authStorage, logger)
 	auditRepo := auditrepo.NewAuditRepository(auditStorage, logger)
 
+	jwtKeys, err := jwtkeys.Load(cfg.JWTKeyFiles, cfg.JWTSigningKeyID, cfg.JWTSecret)
+	if err != nil {
+		logger.Fatal("failed to load jwt keys", zap.Error(err))
+	}
+
+	tokenDenyList := cache.NewRedisTokenDenyList(redisClient)
+	idempotencyStore := cache.NewRedisIdempotencyStore(redisClient)
 	cache := cache.NewRedisCache(redisClient, reportRepo)
 
 	orderService := service.NewOrderService(orderRepo, userRepo, reportRepo, cache, logger)
-	authService := service.NewAuthService(authRepo)
-	auditService := service.NewAuditService(auditRepo)
+	authService := service.NewAuthService(authRepo, tokenDenyList, jwtKeys)
+	auditService := service.NewAuditService(auditRepo, auditChain)
+	idempotencyService := service.NewIdempotencyService(idempotencyStore, cfg.IdempotencyTTL, cfg.IdempotencyLockTTL)
 
-	dbPool := audit.NewWorkerPool(logger)
-	stdoutPool := audit.NewWorkerPool(logger)
-	filterFunc := audit.NewFilterFunc(cfg.AuditFilter)
-	auditPipeline := audit.NewPipeline(auditService, logger, dbPool, stdoutPool)
+	overflowPolicy, err := audit.ParseOverflowPolicy(cfg.AuditOverflow)
+	if err != nil {
+		logger.Fatal("failed to parse audit overflow policy", zap.Error(err))
+	}
+	auditPipeline := audit.NewPipeline(audit.QueueConfig{
+		Capacity:     cfg.AuditQueueSize,
+		Policy:       overflowPolicy,
+		BlockTimeout: cfg.AuditBlockTimeout,
+		SpillDir:     cfg.AuditSpillDir,
+	}, audit.BatchConfig{
+		Size:          cfg.AuditBatchSize,
+		FlushInterval: cfg.AuditFlushInterval,
+	}, logger)
+	sinkDeps := audit.SinkDeps{Config: cfg, AuditService: auditService, Logger: logger}
+	for _, name := range cfg.AuditSinks {
+		filterFunc, err := audit.NewFilterFunc(cfg.AuditSinkFilters[name])
+		if err != nil {
+			logger.Fatalw("failed to compile audit filter", "sink", name, "error", err)
+		}
+		sink, err := audit.NewSink(name, sinkDeps)
+		if err != nil {
+			logger.Fatalw("failed to init audit sink", "sink", name, "error", err)
+		}
+		if err := auditPipeline.AddSink(name, sink, filterFunc); err != nil {
+			logger.Fatalw("failed to init audit queue", "sink", name, "error", err)
+		}
+	}
 
 	apiHandler := api.NewAPIHandler(orderService, auditPipeline)
 	authHandler := api.NewAuthHandler(authService, logger)
+	auditHandler := api.NewAuditHandler(auditService)
 
-	kafkaProducer, err := kafka.NewProducer(cfg.KafkaBrokers, logger)
-	if err != nil {
-		logger.Fatalw("failed to init Kafka Producer", "error", err)
+	kafkaProducers := make([]audit.TaskProducer, 0, cfg.OutboxWorkers)
+	for i := 0; i < cfg.OutboxWorkers; i++ {
+		producer, err := kafka.NewProducer(cfg.KafkaBrokers, fmt.Sprintf("%s-%d", cfg.KafkaTxIDPrefix, i), logger)
+		if err != nil {
+			logger.Fatalw("failed to init Kafka Producer", "error", err)
+		}
+		defer producer.Close()
+		kafkaProducers = append(kafkaProducers, producer)
 	}
 
-	outboxWorker := audit.NewOutboxWorker(auditService, kafkaProducer, logger)
+	outboxWorker := audit.NewOutboxWorker(
+		auditService,
+		kafkaProducers,
+		logger,
+		audit.RetryPolicy{
+			MaxAttempts: cfg.OutboxMaxAttempts,
+			BaseDelay:   cfg.OutboxRetryBase,
+			MaxDelay:    cfg.OutboxRetryMax,
+		},
+		cfg.OutboxPollInterval,
+		cfg.OutboxBatchSize,
+	)
 	go outboxWorker.Run(ctx)
 
-	auditPipeline.StartWorkers(ctx, filterFunc)
+	auditPipeline.StartWorkers(ctx)
 
 	orderService.InitCache(ctx)
 	go orderService.CacheRefresh(ctx)
 
-	router := router.SetupRouter(apiHandler, authHandler, logger, auditPipeline)
+	returnJob := scheduler.NewReturnJob(
+		orderService,
+		auditPipeline,
+		logger,
+		cfg.ReturnJobInterval,
+		cfg.ReturnJobLimit,
+		cfg.ReturnJobDryRun,
+	)
+	go returnJob.Run(ctx)
+
+	router := router.SetupRouter(apiHandler, authHandler, auditHandler, authService, idempotencyService, logger, auditPipeline)
+	// Без доверенных прокси X-Forwarded-For игнорируется и IP клиента — адрес соединения
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatalw("invalid trusted proxies", "error", err)
+	}
 	router.Use(middleware.AuditMiddleware(auditPipeline))
 
+	httpServer := &http.Server{Addr: cfg.HTTPPort, Handler: router}
 	go func() {
-		router.Run(cfg.HTTPPort)
+		logger.Info("starting HTTP server", zap.String("port", cfg.HTTPPort))
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("failed to start HTTP server", zap.Error(err))
+		}
 	}()
 
 	grpcServer := grpc.NewServer(
 		orderService,
 		authService,
+		auditService,
+		idempotencyService,
 		auditPipeline,
 		logger,
 	)
@@ -133,7 +213,33 @@ func main() {
 	}()
 
 	<-ctx.Done()
-	grpcServer.Stop()
+
+	// Обработчики запросов пишут события аудита, поэтому очереди аудита
+	// закрываются только после остановки обоих серверов
+	logger.Info("stopping servers...")
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Errorw("failed to shut down HTTP server", "error", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		grpcServer.GracefulStop(shutdownCtx)
+	}()
+	wg.Wait()
+
+	logger.Info("draining audit queues...")
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.AuditDrainTimeout)
+	defer cancel()
+	if err := auditPipeline.Shutdown(drainCtx); err != nil {
+		logger.Errorw("failed to drain audit pipeline", "error", err)
+	}
 
 	logger.Info("waiting for logger to shut down...")
 
@@ -2,16 +2,40 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"os"
+	"net/http"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+	database "gitlab.ozon.dev/sadsnake2311/homework/internal/db"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/auditeventrepo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage/postgres/auditeventstorage"
 	"go.uber.org/zap"
 )
 
 func main() {
+	replayFrom := flag.String("replay-from", "", "перечитать записи начиная с времени (RFC3339)")
+	replayOffsets := flag.String("replay-offsets", "", "перечитать записи начиная со смещений партиций: 0:120,1:80")
+	replayUntil := flag.String("replay-until", "", "конец повтора по времени (RFC3339), по умолчанию конец топика на момент старта")
+	replayGroup := flag.String("replay-group", "", "группа для повтора, по умолчанию новая <KAFKA_CONSUMER_GROUP>-replay-<время>")
+	flag.Parse()
+
+	replay, err := kafka.ParseReplayRange(*replayFrom, *replayOffsets, *replayUntil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	baseLogger, err := zap.NewProduction()
 	if err != nil {
 		log.Fatalf("failed to init logger: %v", err)
@@ -19,28 +43,110 @@ func main() {
 	logger := baseLogger.Sugar()
 	defer logger.Sync()
 
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("invalid config", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	kafkaConsumer, err := NewConsumer(cfg.KafkaBrokers, cfg.KafkaConsumerGroup, cfg.KafkaTopic, logger)
+	db, err := database.NewDatabase(ctx, cfg.DatabaseURL)
 	if err != nil {
-		logger.Fatalw("failed to init Kafka Consumer", "error", err)
+		logger.Fatalw("failed to init database", "error", err)
+	}
+	defer db.Close()
+
+	if err := metrics.RegisterMetrics(); err != nil {
+		logger.Errorw("failed to register metrics", "error", err)
+	}
+	// Повтор — разовая задача, ее метрики не собираются
+	if replay == nil {
+		metricsServer := &http.Server{Addr: cfg.ConsumerMetricsPort, Handler: promhttp.Handler()}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Errorw("metrics server stopped", "error", err)
+			}
+		}()
+		defer metricsServer.Close()
 	}
-	defer kafkaConsumer.Close()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	eventService := service.NewAuditEventService(
+		auditeventrepo.NewAuditEventRepository(auditeventstorage.NewAuditEventStorage(db), logger),
+	)
+	materializer := audit.NewMaterializer(eventService)
+
+	handlers := audit.NewHandlerRegistry()
+	handlers.Register(domain.EventStatusChange, materializer.Handle)
+	handlers.Register(domain.EventAPIRequest, materializer.Handle)
+	handlers.Register(domain.EventAPIResponse, materializer.Handle)
+
+	// Записи, которые не прошли при первой обработке, уже лежат в DLQ:
+	// повтор пишет свои в отдельный топик, чтобы не задваивать их
+	dlqTopic := cfg.KafkaDLQTopic
+	if replay != nil {
+		dlqTopic = cfg.KafkaReplayDLQTopic
+	}
+	dlqWriter, err := kafka.NewWriter(cfg.KafkaBrokers, dlqTopic, logger)
+	if err != nil {
+		logger.Fatalw("failed to init Kafka DLQ writer", "error", err)
+	}
+	defer dlqWriter.Close()
+
+	var client kafka.Client
+	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	if replay != nil {
+		groupID := *replayGroup
+		if groupID == "" {
+			groupID = fmt.Sprintf("%s-replay-%d", cfg.KafkaConsumerGroup, time.Now().Unix())
+		}
+
+		replayClient, err := newReplayClient(ctx, cfg, *replay, groupID, logger)
+		if err != nil {
+			logger.Fatalw("failed to init Kafka replay consumer", "error", err)
+		}
+		defer func() {
+			logger.Infow("replay progress", "group", groupID, "offsets", replayClient.Progress())
+		}()
 
-	kafkaConsumer.Start(ctx)
+		go func() {
+			select {
+			case <-replayClient.Done():
+				logger.Info("replay caught up")
+				cancel()
+			case <-runCtx.Done():
+			}
+		}()
+		client = replayClient
+	} else {
+		groupClient, err := kafka.NewGroupClient(cfg.KafkaBrokers, cfg.KafkaConsumerGroup, cfg.KafkaTopic, logger)
+		if err != nil {
+			logger.Fatalw("failed to init Kafka Consumer", "error", err)
+		}
+		client = groupClient
+	}
+	defer client.Close()
 
-	select {
-	case <-sigChan:
-		cancel()
-	case <-ctx.Done():
+	retry := audit.RetryPolicy{
+		BaseDelay: cfg.ConsumerRetryBase,
+		MaxDelay:  cfg.ConsumerRetryMax,
+	}
+	consumer := kafka.NewConsumer(
+		client,
+		handlers.Handle,
+		kafka.Retry{
+			AlertAfter:  cfg.ConsumerAlertAttempts,
+			MaxAttempts: cfg.ConsumerMaxAttempts,
+			Delay:       retry.Delay,
+		},
+		kafka.NewDeadLetterWriter(dlqWriter),
+		logger,
+	)
+	if err := consumer.Run(runCtx); err != nil {
+		logger.Errorw("kafka consumer stopped", "error", err)
 	}
 
-	kafkaConsumer.Wait()
 	logger.Info("consumer shutdown")
 }
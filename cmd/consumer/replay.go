@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+	"go.uber.org/zap"
+)
+
+func newReplayClient(ctx context.Context, cfg *config.Config, r kafka.ReplayRange, groupID string, logger *zap.SugaredLogger) (*kafka.ReplayClient, error) {
+	admin, err := kgo.NewClient(kgo.SeedBrokers(cfg.KafkaBrokers...))
+	if err != nil {
+		return nil, err
+	}
+	defer admin.Close()
+
+	plan, err := kafka.PlanReplay(ctx, admin, cfg.KafkaTopic, r)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось определить смещения: %w", err)
+	}
+	for partition, p := range plan {
+		logger.Infow("replay range", "partition", partition, "start", p.Start, "end", p.End)
+	}
+
+	return kafka.NewReplayClient(cfg.KafkaBrokers, groupID, cfg.KafkaTopic, plan, logger)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+	"go.uber.org/zap"
+)
+
+const usage = `Использование:
+  dlq [-limit N] [-idle 5s] [-dry-run]
+
+Возвращает записи из KAFKA_DLQ_TOPIC в KAFKA_TOPIC. Прочитанные записи
+коммитятся группой KAFKA_DLQ_GROUP, поэтому повторный запуск продолжает
+с места остановки. Команда завершается, когда новых записей нет дольше -idle.
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	limit := flag.Int("limit", 0, "вернуть не больше N записей (0 — все)")
+	idle := flag.Duration("idle", 5*time.Second, "сколько ждать новых записей перед выходом")
+	dryRun := flag.Bool("dry-run", false, "только показать записи, не отправлять и не коммитить")
+	flag.Parse()
+
+	baseLogger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("failed to init logger: %v", err)
+	}
+	logger := baseLogger.Sugar()
+	defer logger.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("invalid config", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := kafka.NewGroupClient(cfg.KafkaBrokers, cfg.KafkaDLQGroup, cfg.KafkaDLQTopic, logger,
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		logger.Fatalw("failed to init Kafka DLQ consumer", "error", err)
+	}
+	defer client.Close()
+
+	writer, err := kafka.NewWriter(cfg.KafkaBrokers, cfg.KafkaTopic, logger)
+	if err != nil {
+		logger.Fatalw("failed to init Kafka writer", "error", err)
+	}
+	defer writer.Close()
+
+	reinjected, err := reinject(ctx, client, writer, *limit, *idle, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	if *dryRun {
+		fmt.Printf("найдено записей: %d\n", reinjected)
+	} else {
+		fmt.Printf("возвращено в %s: %d\n", cfg.KafkaTopic, reinjected)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func reinject(ctx context.Context, client *kgo.Client, writer *kafka.Writer, limit int, idle time.Duration, dryRun bool) (int, error) {
+	count := 0
+	for limit == 0 || count < limit {
+		pollCtx, cancel := context.WithTimeout(ctx, idle)
+		fetches := client.PollFetches(pollCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return count, nil
+		}
+		for _, fetchErr := range fetches.Errors() {
+			if errors.Is(fetchErr.Err, context.DeadlineExceeded) {
+				continue
+			}
+			return count, fmt.Errorf("ошибка чтения %s/%d: %w", fetchErr.Topic, fetchErr.Partition, fetchErr.Err)
+		}
+		if fetches.Empty() {
+			return count, nil
+		}
+
+		for _, record := range fetches.Records() {
+			if limit > 0 && count >= limit {
+				break
+			}
+
+			fmt.Printf("%s/%d@%d key=%s error=%q\n",
+				kafka.RecordHeader(record, kafka.HeaderDLQTopic),
+				record.Partition,
+				record.Offset,
+				record.Key,
+				kafka.RecordHeader(record, kafka.HeaderDLQError),
+			)
+
+			if !dryRun {
+				if err := writer.WriteRecord(ctx, &kgo.Record{
+					Key:     record.Key,
+					Value:   record.Value,
+					Headers: kafka.StripDeadLetterHeaders(record.Headers),
+				}); err != nil {
+					return count, err
+				}
+				client.MarkCommitRecords(record)
+			}
+			count++
+		}
+
+		if !dryRun {
+			if err := client.CommitUncommittedOffsets(ctx); err != nil {
+				return count, fmt.Errorf("не удалось закоммитить смещения: %w", err)
+			}
+		}
+	}
+	return count, nil
+}
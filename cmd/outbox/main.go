@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+	database "gitlab.ozon.dev/sadsnake2311/homework/internal/db"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/hashchain"
+	auditrepo "gitlab.ozon.dev/sadsnake2311/homework/internal/repository/auditlogrepo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage/postgres/auditlogstorage"
+	"go.uber.org/zap"
+)
+
+const usage = `Использование:
+  outbox list [-limit N] [-cursor ID]   список задач без оставшихся попыток
+  outbox show ID                        содержимое задачи
+  outbox requeue ID [ID...]             вернуть задачи в очередь
+  outbox requeue -all                   вернуть в очередь все такие задачи
+  outbox verify                         проверить цепочку хешей задач
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	baseLogger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("failed to init logger: %v", err)
+	}
+	logger := baseLogger.Sugar()
+	defer logger.Sync()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("invalid config", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db, err := database.NewDatabase(ctx, cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatalw("failed to init database", "error", err)
+	}
+	defer db.Close()
+
+	auditChain := hashchain.New(cfg.AuditHashKey)
+	auditService := service.NewAuditService(
+		auditrepo.NewAuditRepository(auditlogstorage.NewAuditStorage(db, auditChain), logger),
+		auditChain,
+	)
+
+	switch os.Args[1] {
+	case "list":
+		err = list(ctx, auditService, os.Args[2:])
+	case "show":
+		err = show(ctx, auditService, os.Args[2:])
+	case "requeue":
+		err = requeue(ctx, auditService, os.Args[2:])
+	case "verify":
+		err = verify(ctx, auditService)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func list(ctx context.Context, s service.AuditService, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	limit := fs.Int("limit", 50, "количество задач")
+	cursor := fs.Int("cursor", 0, "ID, после которого продолжить")
+	fs.Parse(args)
+
+	var cursorPtr *int
+	if *cursor > 0 {
+		cursorPtr = cursor
+	}
+
+	tasks, nextCursor, err := s.ListDeadTasks(ctx, *limit, cursorPtr)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTOPIC\tATTEMPTS\tCREATED\tUPDATED")
+	for _, task := range tasks {
+		topic := task.Topic
+		if topic == "" {
+			topic = "audit"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n",
+			task.ID,
+			topic,
+			task.AttemptNumber,
+			task.CreatedAt.Format(time.RFC3339),
+			task.UpdatedAt.Format(time.RFC3339),
+		)
+	}
+	w.Flush()
+
+	if nextCursor != "" {
+		fmt.Printf("\nследующая страница: outbox list -limit %d -cursor %s\n", *limit, nextCursor)
+	}
+	return nil
+}
+
+func show(ctx context.Context, s service.AuditService, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("нужно указать ID задачи")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("неверный ID задачи: %s", args[0])
+	}
+
+	task, err := s.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id: %d\nstatus: %s\nattempts: %d\ncreated_at: %s\nupdated_at: %s\n",
+		task.ID,
+		task.Status,
+		task.AttemptNumber,
+		task.CreatedAt.Format(time.RFC3339),
+		task.UpdatedAt.Format(time.RFC3339),
+	)
+	if task.Topic != "" {
+		fmt.Printf("topic: %s\nkey: %s\n", task.Topic, task.MessageKey)
+	}
+
+	var payload any
+	if err := json.Unmarshal(task.AuditLog, &payload); err != nil {
+		fmt.Printf("payload: %s\n", task.AuditLog)
+		return nil
+	}
+	pretty, _ := json.MarshalIndent(payload, "", "  ")
+	fmt.Printf("payload:\n%s\n", pretty)
+	return nil
+}
+
+func requeue(ctx context.Context, s service.AuditService, args []string) error {
+	fs := flag.NewFlagSet("requeue", flag.ExitOnError)
+	all := fs.Bool("all", false, "вернуть в очередь все задачи без оставшихся попыток")
+	fs.Parse(args)
+
+	var ids []int
+	for _, arg := range fs.Args() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("неверный ID задачи: %s", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 && !*all {
+		return fmt.Errorf("нужно указать ID задач или -all")
+	}
+	if *all {
+		ids = nil
+	}
+
+	requeued, err := s.RequeueDeadTasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("возвращено в очередь: %d\n", requeued)
+	return nil
+}
+
+func verify(ctx context.Context, s service.AuditService) error {
+	report, err := s.VerifyChain(ctx)
+	if err != nil {
+		return err
+	}
+
+	if report.Checked == 0 && report.Valid {
+		fmt.Println("цепочка пуста")
+		return nil
+	}
+
+	fmt.Printf("проверено звеньев: %d (id %d..%d)\n", report.Checked, report.FirstID, report.LastID)
+	if report.Valid {
+		// Голову стоит сохранять вне базы: по ней видно удаление хвоста
+		// вместе с подменой audit_chain_head
+		fmt.Printf("цепочка цела, голова: %s\n", report.Head)
+		return nil
+	}
+
+	fmt.Printf("ожидался хеш: %s\nсохранен хеш: %s\n", report.Broken.Expected, report.Broken.Actual)
+	return fmt.Errorf("цепочка нарушена на задаче %d: %s", report.Broken.ID, report.Broken.Reason)
+}
@@ -1,8 +1,6 @@
 package api
 
 import (
-	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
@@ -28,7 +27,7 @@ type AcceptOrderRequest struct {
 	ID          string               `json:"id" binding:"required"`
 	RecipientID string               `json:"recipient_id" binding:"required"`
 	Expiry      string               `json:"expiry" binding:"required"`
-	BasePrice   float64              `json:"base_price" binding:"required"`
+	BasePrice   domain.Money         `json:"base_price"`
 	Weight      float64              `json:"weight" binding:"required"`
 	Packaging   domain.PackagingType `json:"packaging" binding:"required"`
 }
@@ -37,18 +36,25 @@ type IssueRefundRequest struct {
 	Command  string   `json:"command" binding:"required"`
 	UserID   string   `json:"user_id" binding:"required"`
 	OrderIDs []string `json:"order_ids" binding:"required"`
+	// Atomic: при ошибке в любом заказе не обрабатывается ни один
+	Atomic bool `json:"atomic"`
 }
 
 func (h *APIHandler) AcceptOrder(c *gin.Context) {
 	var req AcceptOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%v: %v", domain.ErrWrongJSON, err)})
+		apperr.JSON(c, domain.ErrWrongJSON.With("detail", err.Error()))
+		return
+	}
+
+	if req.BasePrice.IsZero() {
+		apperr.JSON(c, domain.ErrMissingField.With("field", "base_price"))
 		return
 	}
 
 	expiry, err := time.Parse("2006-01-02", req.Expiry)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат времени"})
+		apperr.JSON(c, domain.ErrInvalidTime.With("field", "expiry"))
 		return
 	}
 
@@ -65,46 +71,28 @@ func (h *APIHandler) AcceptOrder(c *gin.Context) {
 
 	err = h.service.AcceptOrder(c.Request.Context(), order)
 	if err != nil {
-		if errors.Is(err, domain.ErrDatabase) {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		if errors.Is(err, domain.ErrDuplicateOrder) {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
-	h.pipeline.SendEvent(domain.EventStatusChange, map[string]any{
-		"order_id": req.ID,
-		"status":   domain.StatusStored,
-	})
+	h.pipeline.SendEvent(c.Request.Context(), domain.EventStatusChange, audit.StatusChanged(req.ID, domain.StatusStored))
 	c.JSON(http.StatusCreated, gin.H{"message": "заказ принят"})
 }
 
 func (h *APIHandler) ReturnOrder(c *gin.Context) {
 	orderID := c.Param("id")
 	if orderID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "нужно указать order id"})
+		apperr.JSON(c, domain.ErrMissingField.With("field", "order id"))
 		return
 	}
 
 	err := h.service.ReturnOrder(c.Request.Context(), orderID)
 	if err != nil {
-		if errors.Is(err, domain.ErrDatabase) {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
-	h.pipeline.SendEvent(domain.EventStatusChange, map[string]any{
-		"order_id": orderID,
-		"status":   "Deleted",
-	})
+	h.pipeline.SendEvent(c.Request.Context(), domain.EventStatusChange, audit.StatusChanged(orderID, domain.StatusReturnedToCourier))
 	c.JSON(http.StatusOK, gin.H{"message": "заказ удален"})
 }
 
@@ -112,7 +100,7 @@ func (h *APIHandler) IssueRefundOrders(c *gin.Context) {
 	var req IssueRefundRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%v: %v", domain.ErrWrongJSON, err)})
+		apperr.JSON(c, domain.ErrWrongJSON.With("detail", err.Error()))
 		return
 	}
 
@@ -123,42 +111,60 @@ func (h *APIHandler) IssueRefundOrders(c *gin.Context) {
 
 	switch req.Command {
 	case "issue":
-		result, err = h.service.IssueOrders(c.Request.Context(), req.UserID, req.OrderIDs)
+		result, err = h.service.IssueOrders(c.Request.Context(), req.UserID, req.OrderIDs, req.Atomic)
 		status = domain.StatusIssued
 	case "refund":
-		result, err = h.service.RefundOrders(c.Request.Context(), req.UserID, req.OrderIDs)
+		result, err = h.service.RefundOrders(c.Request.Context(), req.UserID, req.OrderIDs, req.Atomic)
 		status = domain.StatusRefunded
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "неверная команда"})
+		apperr.JSON(c, domain.ErrInvalidCommand)
 		return
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		apperr.JSON(c, err)
 		return
 	}
 
 	for _, id := range result.ProcessedOrderIDs {
-		h.pipeline.SendEvent(domain.EventStatusChange, map[string]any{
-			"order_id": id,
-			"status":   status,
-		})
+		h.pipeline.SendEvent(c.Request.Context(), domain.EventStatusChange, audit.StatusChanged(id, status))
+	}
+
+	lang := apperr.RequestLang(c)
+	var errMessage string
+	if result.Error != nil {
+		errMessage = apperr.Message(lang, result.Error)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"processed_order_ids": result.ProcessedOrderIDs,
 		"failed_order_ids":    result.FailedOrderIds,
-		"error":               result.Error,
+		"error":               errMessage,
+		"results":             apperr.LocalizeResults(lang, result.Results),
 	})
 
 }
 
+func (h *APIHandler) GetOrderStatusHistory(c *gin.Context) {
+	orderID := c.Param("id")
+	if orderID == "" {
+		apperr.JSON(c, domain.ErrMissingField.With("field", "order id"))
+		return
+	}
+
+	history, err := h.service.GetOrderStatusHistory(c.Request.Context(), orderID)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
 func (h *APIHandler) GetUserOrders(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "требуется указать user id"})
+		apperr.JSON(c, domain.ErrMissingField.With("field", "user id"))
 		return
 	}
 
@@ -167,7 +173,7 @@ func (h *APIHandler) GetUserOrders(c *gin.Context) {
 		var err error
 		limit, err = strconv.Atoi(limitParam)
 		if err != nil || limit < 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат лимита"})
+			apperr.JSON(c, domain.ErrInvalidLimit)
 			return
 		}
 	}
@@ -177,7 +183,7 @@ func (h *APIHandler) GetUserOrders(c *gin.Context) {
 	if cursor != "" {
 		cursorVal, err := strconv.Atoi(cursor)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат курсора"})
+			apperr.JSON(c, domain.ErrInvalidCursor)
 			return
 		}
 		cursorInt = &cursorVal
@@ -187,7 +193,7 @@ func (h *APIHandler) GetUserOrders(c *gin.Context) {
 
 	orders, nextCursor, err := h.service.GetUserOrders(c.Request.Context(), userID, limit, cursorInt, status)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -204,7 +210,7 @@ func (h *APIHandler) GetRefundedOrders(c *gin.Context) {
 		var err error
 		limit, err = strconv.Atoi(limitParam)
 		if err != nil || limit < 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат лимита"})
+			apperr.JSON(c, domain.ErrInvalidLimit)
 			return
 		}
 	}
@@ -214,7 +220,7 @@ func (h *APIHandler) GetRefundedOrders(c *gin.Context) {
 	if cursor != "" {
 		cursorVal, err := strconv.Atoi(cursor)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат курсора"})
+			apperr.JSON(c, domain.ErrInvalidCursor)
 			return
 		}
 		cursorInt = &cursorVal
@@ -222,7 +228,7 @@ func (h *APIHandler) GetRefundedOrders(c *gin.Context) {
 
 	orders, nextCursor, err := h.service.GetRefundedOrders(c.Request.Context(), limit, cursorInt)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -239,7 +245,7 @@ func (h *APIHandler) GetOrderHistory(c *gin.Context) {
 		var err error
 		limit, err = strconv.Atoi(limitParam)
 		if err != nil || limit < 1 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверный лимит"})
+			apperr.JSON(c, domain.ErrInvalidLimit)
 			return
 		}
 	}
@@ -252,27 +258,27 @@ func (h *APIHandler) GetOrderHistory(c *gin.Context) {
 	if cursor != "" {
 		parts := strings.Split(cursor, ",")
 		if len(parts) != 2 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверный формат курсора"})
+			apperr.JSON(c, domain.ErrInvalidCursor)
 			return
 		}
 
 		var err error
 		lastUpdatedCursor, err = time.Parse(time.RFC3339Nano, parts[0])
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверное время курсора"})
+			apperr.JSON(c, domain.ErrInvalidCursor)
 			return
 		}
 
 		idCursor, err = strconv.Atoi(parts[1])
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "неверное id курсора"})
+			apperr.JSON(c, domain.ErrInvalidCursor)
 			return
 		}
 	}
 
 	orders, nextCursor, err := h.service.GetOrderHistory(c.Request.Context(), limit, lastUpdatedCursor, idCursor)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -286,13 +292,13 @@ func (h *APIHandler) GetOrderHistory(c *gin.Context) {
 func (h *APIHandler) GetUserActiveOrders(c *gin.Context) {
 	userID := c.Param("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "требуется указать user id"})
+		apperr.JSON(c, domain.ErrMissingField.With("field", "user id"))
 		return
 	}
 
 	orders, err := h.service.GetUserActiveOrders(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -303,7 +309,7 @@ func (h *APIHandler) GetAllActiveOrders(c *gin.Context) {
 
 	orders, err := h.service.GetAllActiveOrders(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -314,7 +320,7 @@ func (h *APIHandler) GetOrderHistoryV2(c *gin.Context) {
 
 	orders, err := h.service.GetOrderHistoryV2(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+)
+
+type AuditHandler struct {
+	service service.AuditService
+}
+
+func NewAuditHandler(service service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) GetEvents(c *gin.Context) {
+	filter := domain.AuditEventFilter{
+		Type:      domain.EventType(c.Query("type")),
+		OrderID:   c.Query("order_id"),
+		User:      c.Query("user"),
+		RequestID: c.Query("request_id"),
+		Path:      c.Query("path"),
+		Status:    c.Query("status"),
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 {
+			apperr.JSON(c, domain.ErrInvalidLimit)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		cursorVal, err := strconv.Atoi(cursor)
+		if err != nil {
+			apperr.JSON(c, domain.ErrInvalidCursor)
+			return
+		}
+		filter.Cursor = &cursorVal
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		apperr.JSON(c, domain.ErrInvalidTime.With("field", "from"))
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		apperr.JSON(c, domain.ErrInvalidTime.With("field", "to"))
+		return
+	}
+
+	events, nextCursor, err := h.service.GetEvents(c.Request.Context(), filter)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	response := gin.H{"events": events}
+	if nextCursor != "" {
+		response["next_cursor"] = nextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func parseTimeQuery(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+type DeadTaskResponse struct {
+	ID            int             `json:"id"`
+	Topic         string          `json:"topic,omitempty"`
+	Key           string          `json:"key,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	AttemptNumber int             `json:"attempt_number"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+func newDeadTaskResponse(task domain.AuditTask) DeadTaskResponse {
+	return DeadTaskResponse{
+		ID:            task.ID,
+		Topic:         task.Topic,
+		Key:           task.MessageKey,
+		Payload:       task.AuditLog,
+		AttemptNumber: task.AttemptNumber,
+		CreatedAt:     task.CreatedAt,
+		UpdatedAt:     task.UpdatedAt,
+	}
+}
+
+func (h *AuditHandler) ListDeadTasks(c *gin.Context) {
+	limit := 0
+	if limitParam := c.Query("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 {
+			apperr.JSON(c, domain.ErrInvalidLimit)
+			return
+		}
+	}
+
+	var cursorInt *int
+	if cursor := c.Query("cursor"); cursor != "" {
+		cursorVal, err := strconv.Atoi(cursor)
+		if err != nil {
+			apperr.JSON(c, domain.ErrInvalidCursor)
+			return
+		}
+		cursorInt = &cursorVal
+	}
+
+	tasks, nextCursor, err := h.service.ListDeadTasks(c.Request.Context(), limit, cursorInt)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	result := make([]DeadTaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, newDeadTaskResponse(task))
+	}
+
+	response := gin.H{"tasks": result}
+	if nextCursor != "" {
+		response["next_cursor"] = nextCursor
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuditHandler) GetDeadTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperr.JSON(c, domain.ErrInvalidTaskID)
+		return
+	}
+
+	task, err := h.service.GetTask(c.Request.Context(), id)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+	// Задачи, у которых еще есть попытки, через этот адрес не показываются
+	if task.Status != domain.StatusNoAttemptsLeft {
+		apperr.JSON(c, domain.ErrAuditTaskNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, newDeadTaskResponse(task))
+}
+
+func (h *AuditHandler) RequeueDeadTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apperr.JSON(c, domain.ErrInvalidTaskID)
+		return
+	}
+
+	requeued, err := h.service.RequeueDeadTasks(c.Request.Context(), []int{id})
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+	if requeued == 0 {
+		apperr.JSON(c, domain.ErrAuditTaskNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requeued": requeued})
+}
+
+type RequeueDeadTasksRequest struct {
+	IDs []int `json:"ids"`
+	All bool  `json:"all"`
+}
+
+// RequeueDeadTasks возвращает в очередь перечисленные задачи, а при all=true — все
+func (h *AuditHandler) RequeueDeadTasks(c *gin.Context) {
+	var req RequeueDeadTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.JSON(c, domain.ErrWrongJSON)
+		return
+	}
+	if len(req.IDs) == 0 && !req.All {
+		apperr.JSON(c, domain.ErrMissingField.With("field", "ids/all"))
+		return
+	}
+	if req.All {
+		req.IDs = nil
+	}
+
+	requeued, err := h.service.RequeueDeadTasks(c.Request.Context(), req.IDs)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requeued": requeued})
+}
+
+// VerifyChain проверяет цепочку хешей задач аудита и возвращает первое
+// нарушенное звено, если оно есть.
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	report, err := h.service.VerifyChain(c.Request.Context())
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
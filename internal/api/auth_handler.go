@@ -1,10 +1,10 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"go.uber.org/zap"
@@ -27,7 +27,7 @@ type SignupUserRequest struct {
 func (h *AuthHandler) Signup(c *gin.Context) {
 	var req SignupUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrWrongJSON.Error()})
+		apperr.JSON(c, domain.ErrWrongJSON)
 		return
 	}
 
@@ -37,14 +37,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 	}
 	err := h.service.Register(c.Request.Context(), &user)
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrUserAlreadyExists):
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		case errors.Is(err, domain.ErrDatabase), errors.Is(err, domain.ErrHashPassword):
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		apperr.JSON(c, err)
 		return
 	}
 
@@ -54,27 +47,93 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var user domain.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperr.JSON(c, domain.ErrWrongJSON.With("detail", err.Error()))
 		return
 	}
 
 	err := h.service.Login(c.Request.Context(), &user)
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrDatabase):
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		apperr.JSON(c, err)
 		return
 	}
 
-	token, err := h.service.GenerateToken(user.Email)
+	tokens, err := h.service.IssueTokens(c.Request.Context(), &user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrTokenGeneration})
+		apperr.JSON(c, domain.ErrTokenGeneration)
+		return
 	}
 
-	c.SetCookie("jwt", token, int(domain.TokenExpiration.Seconds()), "/", "", false, true)
+	setTokenCookies(c, tokens)
 
 	c.JSON(http.StatusOK, gin.H{"message": "вход успешен"})
 }
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshCookie)
+	if err != nil {
+		apperr.JSON(c, domain.ErrInvalidRefreshToken)
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), refreshToken)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	setTokenCookies(c, tokens)
+
+	c.JSON(http.StatusOK, gin.H{"message": "токены обновлены"})
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	accessToken, _ := c.Cookie(accessCookie)
+	refreshToken, _ := c.Cookie(refreshCookie)
+
+	if err := h.service.Logout(c.Request.Context(), accessToken, refreshToken); err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	c.SetCookie(accessCookie, "", -1, "/", "", false, true)
+	c.SetCookie(refreshCookie, "", -1, refreshCookiePath, "", false, true)
+
+	c.JSON(http.StatusOK, gin.H{"message": "выход выполнен"})
+}
+
+const (
+	accessCookie      = "jwt"
+	refreshCookie     = "refresh_token"
+	refreshCookiePath = "/users"
+)
+
+func setTokenCookies(c *gin.Context, tokens domain.TokenPair) {
+	c.SetCookie(accessCookie, tokens.AccessToken, int(domain.TokenExpiration.Seconds()), "/", "", false, true)
+	c.SetCookie(refreshCookie, tokens.RefreshToken, int(domain.RefreshTokenExpiration.Seconds()), refreshCookiePath, "", false, true)
+}
+
+type AssignRoleRequest struct {
+	Email string      `json:"email" binding:"required,email"`
+	Role  domain.Role `json:"role" binding:"required"`
+}
+
+func (h *AuthHandler) AssignRole(c *gin.Context) {
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.JSON(c, domain.ErrWrongJSON)
+		return
+	}
+
+	err := h.service.AssignRole(c.Request.Context(), req.Email, req.Role)
+	if err != nil {
+		apperr.JSON(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "роль назначена"})
+}
+
+// JWKS публикует публичные ключи, которыми другие сервисы проверяют наши токены
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.JWKS())
+}
@@ -0,0 +1,207 @@
+// Package apperr отдает ошибки каталога domain клиентам REST и gRPC:
+// один код ошибки — один статус HTTP, один код gRPC и текст на языке
+// клиента. Русский текст — текст самой ошибки, английский хранится здесь.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain — поле domain в errdetails.ErrorInfo.
+const ErrorDomain = "pvz.homework"
+
+type entry struct {
+	http int
+	grpc codes.Code
+	en   string
+}
+
+var catalog = map[domain.ErrorCode]entry{
+	domain.CodeOrderNotFound:            {http.StatusNotFound, codes.NotFound, "order with this ID does not exist"},
+	domain.CodeOrderAlreadyExists:       {http.StatusConflict, codes.AlreadyExists, "an order with this ID already exists"},
+	domain.CodeOrderExpired:             {http.StatusConflict, codes.FailedPrecondition, "the order storage period has expired"},
+	domain.CodeOrderNotExpired:          {http.StatusConflict, codes.FailedPrecondition, "the order storage period has not expired yet"},
+	domain.CodeOrderNotStored:           {http.StatusConflict, codes.FailedPrecondition, "the order is not at the pickup point"},
+	domain.CodeOrderNotIssued:           {http.StatusConflict, codes.FailedPrecondition, "the order has not been issued yet"},
+	domain.CodeOrderRefundPeriodExpired: {http.StatusConflict, codes.FailedPrecondition, "the refund period for the order has expired"},
+	domain.CodeOrderNotOwned:            {http.StatusConflict, codes.FailedPrecondition, "order {order_id} does not belong to user {user_id}"},
+	domain.CodeOrderInvalidTransition:   {http.StatusConflict, codes.FailedPrecondition, "cannot move the order from status \"{from}\" to \"{to}\""},
+	domain.CodeOrderIDsEmpty:            {http.StatusBadRequest, codes.InvalidArgument, "the order ID list is empty"},
+	domain.CodeOrdersNotReady:           {http.StatusConflict, codes.FailedPrecondition, "the orders are not ready to be issued or refunded"},
+	domain.CodeUserNoActiveOrders:       {http.StatusNotFound, codes.NotFound, "the user has no active orders"},
+	domain.CodeOrderBatchRolledBack:     {http.StatusConflict, codes.Aborted, "changes were rolled back because another order failed"},
+	domain.CodeInvalidWeight:            {http.StatusBadRequest, codes.InvalidArgument, "the weight is too large for this packaging"},
+	domain.CodeUnknownPackaging:         {http.StatusBadRequest, codes.InvalidArgument, "unknown packaging type"},
+	domain.CodePackagingCombination:     {http.StatusBadRequest, codes.InvalidArgument, "main packaging types cannot be combined"},
+	domain.CodeInvalidMoney:             {http.StatusBadRequest, codes.InvalidArgument, "invalid amount"},
+	domain.CodeUnknownCurrency:          {http.StatusBadRequest, codes.InvalidArgument, "unknown currency"},
+
+	domain.CodeInvalidRequest: {http.StatusBadRequest, codes.InvalidArgument, "the request body contains errors"},
+	domain.CodeInvalidCommand: {http.StatusBadRequest, codes.InvalidArgument, "unknown command"},
+	domain.CodeInvalidLimit:   {http.StatusBadRequest, codes.InvalidArgument, "invalid limit"},
+	domain.CodeInvalidCursor:  {http.StatusBadRequest, codes.InvalidArgument, "invalid cursor"},
+	domain.CodeInvalidTime:    {http.StatusBadRequest, codes.InvalidArgument, "invalid time format for {field}"},
+	domain.CodeMissingField:   {http.StatusBadRequest, codes.InvalidArgument, "{field} is required"},
+	domain.CodeInvalidTaskID:  {http.StatusBadRequest, codes.InvalidArgument, "invalid task ID"},
+
+	domain.CodeUserAlreadyExists:   {http.StatusConflict, codes.AlreadyExists, "a user with this email is already registered"},
+	domain.CodeInvalidCredentials:  {http.StatusUnauthorized, codes.Unauthenticated, "invalid email or password"},
+	domain.CodeEmptyPassword:       {http.StatusBadRequest, codes.InvalidArgument, "the password must not be empty"},
+	domain.CodeUserNotFound:        {http.StatusNotFound, codes.NotFound, "user not found"},
+	domain.CodeInvalidRole:         {http.StatusBadRequest, codes.InvalidArgument, "unknown role"},
+	domain.CodeForbidden:           {http.StatusForbidden, codes.PermissionDenied, "insufficient permissions"},
+	domain.CodeUnauthenticated:     {http.StatusUnauthorized, codes.Unauthenticated, "login required"},
+	domain.CodeInvalidToken:        {http.StatusUnauthorized, codes.Unauthenticated, "the token is invalid"},
+	domain.CodeTokenRevoked:        {http.StatusUnauthorized, codes.Unauthenticated, "the token has been revoked"},
+	domain.CodeInvalidRefreshToken: {http.StatusUnauthorized, codes.Unauthenticated, "the refresh token is invalid"},
+
+	domain.CodeInvalidAuditFilter: {http.StatusBadRequest, codes.InvalidArgument, "invalid audit filter parameters"},
+	domain.CodeAuditTaskNotFound:  {http.StatusNotFound, codes.NotFound, "audit task with this ID does not exist"},
+
+	domain.CodeInvalidIdempotencyKey:  {http.StatusBadRequest, codes.InvalidArgument, "invalid idempotency key"},
+	domain.CodeIdempotencyKeyReused:   {http.StatusConflict, codes.AlreadyExists, "the idempotency key was already used for another request"},
+	domain.CodeIdempotencyInProgress:  {http.StatusConflict, codes.Aborted, "a request with this idempotency key is still in progress"},
+	domain.CodeIdempotencyUnavailable: {http.StatusServiceUnavailable, codes.Unavailable, "failed to check the idempotency key"},
+
+	domain.CodeDatabase:        {http.StatusInternalServerError, codes.Internal, "database error"},
+	domain.CodeCache:           {http.StatusInternalServerError, codes.Internal, "cache error"},
+	domain.CodeHashPassword:    {http.StatusInternalServerError, codes.Internal, "failed to hash the password"},
+	domain.CodeTokenGeneration: {http.StatusInternalServerError, codes.Internal, "failed to generate a token"},
+	domain.CodeInternal:        {http.StatusInternalServerError, codes.Internal, "internal error"},
+}
+
+// Codes возвращает все коды каталога.
+func Codes() []domain.ErrorCode {
+	result := make([]domain.ErrorCode, 0, len(catalog))
+	for code := range catalog {
+		result = append(result, code)
+	}
+	return result
+}
+
+type Lang string
+
+const (
+	LangRU Lang = "ru"
+	LangEN Lang = "en"
+)
+
+// Первый язык — язык по умолчанию.
+var matcher = language.NewMatcher([]language.Tag{language.Russian, language.English})
+
+// ParseLang выбирает язык по значению Accept-Language.
+func ParseLang(acceptLanguage string) Lang {
+	_, index := language.MatchStrings(matcher, acceptLanguage)
+	if index == 1 {
+		return LangEN
+	}
+	return LangRU
+}
+
+// RequestLang — язык клиента REST.
+func RequestLang(c *gin.Context) Lang {
+	return ParseLang(c.GetHeader("Accept-Language"))
+}
+
+// ContextLang — язык клиента gRPC из метаданных accept-language.
+func ContextLang(ctx context.Context) Lang {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("accept-language")
+	if len(values) == 0 {
+		return LangRU
+	}
+	return ParseLang(values[0])
+}
+
+// Resolve находит в цепочке err ошибку каталога. Ошибка вне каталога
+// считается внутренней: ее текст клиенту не отдается.
+func Resolve(err error) domain.CodedError {
+	var coded domain.CodedError
+	if errors.As(err, &coded) {
+		if _, ok := catalog[coded.Code()]; ok {
+			return coded
+		}
+	}
+	return domain.ErrInternal
+}
+
+// Message — текст ошибки на языке lang.
+func Message(lang Lang, err error) string {
+	coded := Resolve(err)
+	if lang == LangEN {
+		return domain.ExpandParams(catalog[coded.Code()].en, coded.Params())
+	}
+	return coded.Error()
+}
+
+// LocalizeResults переводит тексты ошибок в итогах обработки заказов.
+func LocalizeResults(lang Lang, results []domain.OrderResult) []domain.OrderResult {
+	localized := make([]domain.OrderResult, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			r.Message = Message(lang, r.Err)
+		}
+		localized[i] = r
+	}
+	return localized
+}
+
+// Body — тело ответа REST с ошибкой.
+type Body struct {
+	Error  string            `json:"error"`
+	Code   domain.ErrorCode  `json:"code"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// HTTP возвращает статус и тело ответа REST для ошибки.
+func HTTP(lang Lang, err error) (int, Body) {
+	coded := Resolve(err)
+	return catalog[coded.Code()].http, Body{
+		Error:  Message(lang, coded),
+		Code:   coded.Code(),
+		Params: coded.Params(),
+	}
+}
+
+// JSON прерывает обработку запроса REST и отвечает ошибкой на языке из
+// Accept-Language.
+func JSON(c *gin.Context, err error) {
+	code, body := HTTP(RequestLang(c), err)
+	c.AbortWithStatusJSON(code, body)
+}
+
+// GRPC возвращает ошибку gRPC с кодом из каталога. В деталях статуса —
+// ErrorInfo с кодом ошибки и параметрами и LocalizedMessage, текст статуса
+// совпадает с текстом LocalizedMessage.
+func GRPC(ctx context.Context, err error) error {
+	lang := ContextLang(ctx)
+	coded := Resolve(err)
+	message := Message(lang, coded)
+
+	st := status.New(catalog[coded.Code()].grpc, message)
+	detailed, detailsErr := st.WithDetails(
+		&errdetails.ErrorInfo{
+			Reason:   string(coded.Code()),
+			Domain:   ErrorDomain,
+			Metadata: coded.Params(),
+		},
+		&errdetails.LocalizedMessage{
+			Locale:  string(lang),
+			Message: message,
+		},
+	)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,187 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+)
+
+type node interface {
+	eval(fields map[string]any) any
+}
+
+type literalNode struct{ value any }
+
+func (n literalNode) eval(map[string]any) any { return n.value }
+
+type fieldNode struct{ path []string }
+
+func (n fieldNode) eval(fields map[string]any) any {
+	var current any = fields
+	for _, key := range n.path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return normalize(current)
+}
+
+type listNode struct{ items []node }
+
+func (n listNode) eval(fields map[string]any) any {
+	values := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		values = append(values, item.eval(fields))
+	}
+	return values
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(fields map[string]any) any { return !truthy(n.operand.eval(fields)) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(fields map[string]any) any {
+	return truthy(n.left.eval(fields)) && truthy(n.right.eval(fields))
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(fields map[string]any) any {
+	return truthy(n.left.eval(fields)) || truthy(n.right.eval(fields))
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(fields map[string]any) any {
+	left := n.left.eval(fields)
+	right := n.right.eval(fields)
+
+	switch n.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	case "<", "<=", ">", ">=":
+		cmp, ok := compare(left, right)
+		if !ok {
+			return false
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		default:
+			return cmp >= 0
+		}
+	case "in":
+		for _, item := range right.([]any) {
+			if equal(left, item) {
+				return true
+			}
+		}
+		return false
+	case "startsWith", "endsWith", "contains":
+		l, lok := left.(string)
+		r, rok := right.(string)
+		if !lok || !rok {
+			return false
+		}
+		switch n.op {
+		case "startsWith":
+			return strings.HasPrefix(l, r)
+		case "endsWith":
+			return strings.HasSuffix(l, r)
+		default:
+			return strings.Contains(l, r)
+		}
+	}
+	return false
+}
+
+// normalize приводит числа к float64, а именованные строковые типы
+// (например, domain.OrderStatus) к string, чтобы значения из события
+// сравнивались с литералами выражения независимо от исходного типа.
+func normalize(v any) any {
+	switch v.(type) {
+	case nil, string, float64, bool, map[string]any, []any:
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	switch a.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	switch b.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return a == b
+}
+
+func compare(a, b any) (int, bool) {
+	switch l := a.(type) {
+	case float64:
+		r, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		r, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(l, r), true
+	}
+	return 0, false
+}
+
+func truthy(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case float64:
+		return t != 0
+	case []any:
+		return len(t) > 0
+	case map[string]any:
+		return len(t) > 0
+	}
+	return true
+}
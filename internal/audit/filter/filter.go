@@ -0,0 +1,76 @@
+// Package filter реализует язык выражений для фильтрации событий аудита:
+//
+//	type == "status_change" && data.status in ["issued", "refunded"]
+//	data.path startsWith "/orders" && data.status >= 500
+//	!(user == "") || request_id != null
+//
+// Поля записываются через точку и ищутся во вложенных map. Поддерживаются
+// сравнения ==, !=, <, <=, >, >= (числа и строки), in, startsWith, endsWith,
+// contains, логические &&, ||, ! и скобки. Поле без сравнения истинно, если
+// оно есть и не пустое. Отсутствующее поле равно null.
+package filter
+
+import "strings"
+
+// Expr — скомпилированное выражение. Безопасно для одновременного использования.
+type Expr struct {
+	root node
+	src  string
+}
+
+func Compile(src string) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "лишний фрагмент, начиная с %s", t)
+	}
+
+	return &Expr{root: root, src: src}, nil
+}
+
+// Match вычисляет выражение над полями события.
+func (e *Expr) Match(fields map[string]any) bool {
+	return truthy(e.root.eval(fields))
+}
+
+// Fields возвращает поля, к которым обращается выражение, в порядке
+// появления, например "type" или "data.status".
+func (e *Expr) Fields() []string {
+	var fields []string
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case fieldNode:
+			fields = append(fields, strings.Join(n.path, "."))
+		case listNode:
+			for _, item := range n.items {
+				walk(item)
+			}
+		case notNode:
+			walk(n.operand)
+		case andNode:
+			walk(n.left)
+			walk(n.right)
+		case orNode:
+			walk(n.left)
+			walk(n.right)
+		case compareNode:
+			walk(n.left)
+			walk(n.right)
+		}
+	}
+	walk(e.root)
+	return fields
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
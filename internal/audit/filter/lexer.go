@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "конец выражения"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Операторы из двух символов проверяются раньше односимвольных
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!"}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(src); {
+		c, size := utf8.DecodeRuneInString(src[i:])
+
+		switch {
+		case c == utf8.RuneError && size == 1:
+			return nil, &SyntaxError{Pos: i, Msg: "неверная кодировка, ожидается UTF-8"}
+
+		case unicode.IsSpace(c):
+			i += size
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case c == '"':
+			// Кавычка и обратный слеш однобайтовые и не встречаются внутри
+			// многобайтовых символов UTF-8, поэтому строку можно искать по байтам
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, &SyntaxError{Pos: i, Msg: "незакрытая строка"}
+			}
+			value, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, &SyntaxError{Pos: i, Msg: "неверная строка"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: src[i : end+1], value: value, pos: i})
+			i = end + 1
+
+		case isDigit(src[i]) || (c == '-' && i+1 < len(src) && isDigit(src[i+1])):
+			end := i + 1
+			for end < len(src) && (isDigit(src[end]) || src[end] == '.') {
+				end++
+			}
+			value, err := strconv.ParseFloat(src[i:end], 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("неверное число %q", src[i:end])}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:end], value: value, pos: i})
+			i = end
+
+		case unicode.IsLetter(c) || c == '_':
+			end := i + size
+			for end < len(src) {
+				next, nextSize := utf8.DecodeRuneInString(src[end:])
+				if !isIdentChar(next) {
+					break
+				}
+				end += nextSize
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[i:end], pos: i})
+			i = end
+
+		default:
+			op := matchOperator(src[i:])
+			if op == "" {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("неожиданный символ %q", c)}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.'
+}
+
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
@@ -0,0 +1,182 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError — ошибка разбора выражения с позицией (в байтах, с нуля) в исходной строке.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("позиция %d: %s", e.Pos, e.Msg)
+}
+
+// Операторы сравнения, записываемые словом
+var wordOperators = map[string]bool{
+	"in":         true,
+	"startsWith": true,
+	"endsWith":   true,
+	"contains":   true,
+}
+
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t := p.peek(); t.kind == tokenOperator && t.text == "!" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	isComparison := (t.kind == tokenOperator && comparisonOperators[t.text]) ||
+		(t.kind == tokenIdent && wordOperators[t.text])
+	if !isComparison {
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.text == "in" {
+		if _, ok := right.(listNode); !ok {
+			return nil, p.errorf(t, "справа от in должен быть список [...]")
+		}
+	}
+
+	return compareNode{op: t.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenString, tokenNumber:
+		return literalNode{t.value}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "null":
+			return literalNode{nil}, nil
+		}
+		if wordOperators[t.text] {
+			return nil, p.errorf(t, "ожидалось поле или значение, получено %s", t)
+		}
+		if strings.HasPrefix(t.text, ".") || strings.HasSuffix(t.text, ".") || strings.Contains(t.text, "..") {
+			return nil, p.errorf(t, "неверное имя поля %s", t)
+		}
+		return fieldNode{path: strings.Split(t.text, ".")}, nil
+
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.errorf(closing, "ожидалась ), получено %s", closing)
+		}
+		return inner, nil
+
+	case tokenLBracket:
+		var items []node
+		if p.peek().kind == tokenRBracket {
+			p.next()
+			return listNode{items}, nil
+		}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+
+			sep := p.next()
+			if sep.kind == tokenRBracket {
+				return listNode{items}, nil
+			}
+			if sep.kind != tokenComma {
+				return nil, p.errorf(sep, "ожидалась , или ], получено %s", sep)
+			}
+		}
+
+	default:
+		return nil, p.errorf(t, "ожидалось поле или значение, получено %s", t)
+	}
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+)
+
+// EventHandler обрабатывает события одного типа из топика аудита. При
+// повторной обработке записи получает те же события, поэтому должен быть
+// идемпотентным.
+type EventHandler func(ctx context.Context, events []domain.AuditEvent) error
+
+// HandlerRegistry раздает события из Kafka обработчикам по типу события.
+type HandlerRegistry struct {
+	handlers map[domain.EventType]EventHandler
+}
+
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{handlers: make(map[domain.EventType]EventHandler)}
+}
+
+func (r *HandlerRegistry) Register(eventType domain.EventType, handler EventHandler) {
+	if _, exists := r.handlers[eventType]; exists {
+		panic("audit: handler for " + string(eventType) + " already registered")
+	}
+	r.handlers[eventType] = handler
+}
+
+// Handle подходит как kafka.BatchHandler. Запись, которую не удалось
+// разобрать или которая не прошла проверку схемы, и событие без
+// обработчика — постоянные ошибки: повтор их не
+// исправит, и такие записи уходят в топик недоставленных.
+func (r *HandlerRegistry) Handle(ctx context.Context, records []*kgo.Record) error {
+	byType := make(map[domain.EventType][]domain.AuditEvent)
+	for _, record := range records {
+		event, err := DecodeRecord(record)
+		if err != nil {
+			return kafka.Permanent(err)
+		}
+		if _, ok := r.handlers[event.Type]; !ok {
+			return kafka.Permanent(fmt.Errorf("нет обработчика для событий типа %q", event.Type))
+		}
+		byType[event.Type] = append(byType[event.Type], event)
+	}
+
+	for eventType, events := range byType {
+		if err := r.handlers[eventType](ctx, events); err != nil {
+			return fmt.Errorf("обработчик %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
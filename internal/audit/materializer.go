@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/schema"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+)
+
+// Materializer сохраняет события из топика аудита в audit_events. ID
+// события — ID задачи outbox из ключа записи, по нему отсеиваются повторные
+// доставки.
+type Materializer struct {
+	service service.AuditEventService
+}
+
+func NewMaterializer(service service.AuditEventService) *Materializer {
+	return &Materializer{service: service}
+}
+
+// Handle подходит как EventHandler для любого типа событий.
+func (m *Materializer) Handle(ctx context.Context, events []domain.AuditEvent) error {
+	inserted, err := m.service.SaveEvents(ctx, events)
+	if err != nil {
+		return err
+	}
+
+	metrics.AddAuditConsumerEvents("stored", inserted)
+	metrics.AddAuditConsumerEvents("duplicate", len(events)-inserted)
+	return nil
+}
+
+// recordEvent — domain.Event в том виде, в каком его пишет outbox.
+type recordEvent struct {
+	Type          domain.EventType
+	EventID       string
+	SchemaVersion int
+	Data          json.RawMessage
+	Time          time.Time
+	User          string
+	RequestID     string
+	TraceID       string
+	ClientIP      string
+	UserAgent     string
+}
+
+// DecodeRecord разбирает запись outbox: ключ — ID задачи, значение —
+// событие в JSON. Данные события проверяются по схеме его версии.
+func DecodeRecord(record *kgo.Record) (domain.AuditEvent, error) {
+	id, err := strconv.Atoi(string(record.Key))
+	if err != nil {
+		return domain.AuditEvent{}, fmt.Errorf("ключ записи %q не ID задачи", record.Key)
+	}
+
+	var event recordEvent
+	if err := json.Unmarshal(record.Value, &event); err != nil {
+		return domain.AuditEvent{}, fmt.Errorf("неверное событие: %w", err)
+	}
+	if event.Type == "" || event.Time.IsZero() {
+		return domain.AuditEvent{}, fmt.Errorf("у события нет типа или времени")
+	}
+	if _, err := schema.Decode(event.Type, event.EventID, event.SchemaVersion, event.Data); err != nil {
+		metrics.IncAuditEventsInvalid("consumer", string(event.Type))
+		return domain.AuditEvent{}, err
+	}
+
+	return domain.AuditEvent{
+		ID:            id,
+		Type:          event.Type,
+		EventID:       event.EventID,
+		SchemaVersion: event.SchemaVersion,
+		Data:          event.Data,
+		Time:          event.Time,
+		User:          event.User,
+		RequestID:     event.RequestID,
+		TraceID:       event.TraceID,
+		ClientIP:      event.ClientIP,
+		UserAgent:     event.UserAgent,
+	}, nil
+}
@@ -2,115 +2,248 @@ package audit
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"go.uber.org/zap"
 )
 
+// TaskProducer отправляет задачи outbox в Kafka транзакциями, реализуется
+// kafka.Producer.
+type TaskProducer interface {
+	SendTransactional(ctx context.Context, message kafka.Message) error
+	SendTransactionalBatch(ctx context.Context, messages []kafka.Message) error
+}
+
+// OutboxWorker отправляет задачи аудита в Kafka. Обработчики просыпаются по
+// NOTIFY о новой задаче, опрос по pollInterval остается запасным вариантом
+// на случай потери соединения с LISTEN и для повторных попыток.
 type OutboxWorker struct {
-	service       service.AuditService
-	kafkaProducer *kafka.Producer
-	logger        *zap.SugaredLogger
-	pollInterval  time.Duration
-	batchSize     int
-	retryDelay    time.Duration
-	maxAttempts   int
+	service         service.AuditService
+	producers       []TaskProducer
+	logger          *zap.SugaredLogger
+	pollInterval    time.Duration
+	metricsInterval time.Duration
+	batchSize       int
+	lease           time.Duration
+	retry           RetryPolicy
 }
 
+// NewOutboxWorker запускает по одному обработчику на каждого продюсера.
 func NewOutboxWorker(
 	service service.AuditService,
-	producer *kafka.Producer,
+	producers []TaskProducer,
 	logger *zap.SugaredLogger,
+	retry RetryPolicy,
+	pollInterval time.Duration,
+	batchSize int,
 ) *OutboxWorker {
 	return &OutboxWorker{
-		service:       service,
-		kafkaProducer: producer,
-		logger:        logger,
-		pollInterval:  500 * time.Millisecond,
-		batchSize:     100,
-		retryDelay:    2 * time.Second,
-		maxAttempts:   3,
+		service:         service,
+		producers:       producers,
+		logger:          logger,
+		pollInterval:    pollInterval,
+		metricsInterval: 10 * time.Second,
+		batchSize:       batchSize,
+		lease:           time.Minute,
+		retry:           retry,
 	}
 }
 
 func (w *OutboxWorker) Run(ctx context.Context) {
-	w.logger.Info("outbox worker started")
+	w.logger.Infow("outbox worker started", "workers", len(w.producers))
 	defer w.logger.Info("outbox worker stopped")
 
+	var wg sync.WaitGroup
+	wakeups := make([]chan struct{}, len(w.producers))
+	for i, producer := range w.producers {
+		wakeups[i] = make(chan struct{}, 1)
+
+		wg.Add(1)
+		go func(producer TaskProducer, wakeup <-chan struct{}) {
+			defer wg.Done()
+			w.runProcessor(ctx, producer, wakeup)
+		}(producer, wakeups[i])
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.listen(ctx, wakeups)
+	}()
+
+	w.runMetrics(ctx)
+	wg.Wait()
+}
+
+// listen будит обработчики на каждое уведомление. Если соединение с LISTEN
+// оборвалось, переподключается, а обработчики пока работают по опросу.
+func (w *OutboxWorker) listen(ctx context.Context, wakeups []chan struct{}) {
+	notify := func() {
+		for _, wakeup := range wakeups {
+			select {
+			case wakeup <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	for {
+		err := w.service.ListenNewTasks(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Errorw("outbox listener failed, falling back to polling", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+func (w *OutboxWorker) runProcessor(ctx context.Context, producer TaskProducer, wakeup <-chan struct{}) {
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
 	for {
+		w.drain(ctx, producer)
+
 		select {
 		case <-ctx.Done():
 			return
+		case <-wakeup:
 		case <-ticker.C:
-			w.processBatch(ctx)
 		}
 	}
 }
 
-func (w *OutboxWorker) processBatch(ctx context.Context) {
-	tx, err := w.service.BeginTx(ctx)
-	if err != nil {
-		w.logger.Errorw("failed to start tx", "error", err)
-		return
+// drain обрабатывает пачки, пока они приходят полными, чтобы под нагрузкой
+// не ждать следующего пробуждения.
+func (w *OutboxWorker) drain(ctx context.Context, producer TaskProducer) {
+	for ctx.Err() == nil {
+		if w.processBatch(ctx, producer) < w.batchSize {
+			return
+		}
+	}
+}
+
+func (w *OutboxWorker) runMetrics(ctx context.Context) {
+	ticker := time.NewTicker(w.metricsInterval)
+	defer ticker.Stop()
+
+	for {
+		w.updateGauges(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
-	defer tx.Rollback(ctx)
+}
 
-	tasks, err := w.service.FetchPendingTasksTx(ctx, tx, w.batchSize)
+func (w *OutboxWorker) updateGauges(ctx context.Context) {
+	count, err := w.service.CountDeadTasks(ctx)
 	if err != nil {
-		w.logger.Errorw("failed to fetch tasks", "error", err)
-		return
+		w.logger.Errorw("failed to count dead tasks", "error", err)
+	} else {
+		metrics.SetOutboxDeadTasks(count)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		w.logger.Errorw("failed to commit tx", "error", err)
-		return
+	lag, err := w.service.OldestPendingTaskAge(ctx)
+	if err != nil {
+		w.logger.Errorw("failed to get outbox lag", "error", err)
+	} else {
+		metrics.SetOutboxLag(lag)
 	}
+}
 
+// processBatch отправляет всю пачку одной транзакцией Kafka и одним запросом
+// помечает задачи завершенными. Если транзакция не прошла, задачи
+// отправляются по одной, чтобы ошибка одной не задерживала остальные и
+// каждой велся свой счетчик попыток.
+func (w *OutboxWorker) processBatch(ctx context.Context, producer TaskProducer) int {
+	tasks, err := w.service.ClaimPendingTasks(ctx, w.batchSize, w.lease)
+	if err != nil {
+		w.logger.Errorw("failed to claim tasks", "error", err)
+		return 0
+	}
 	if len(tasks) == 0 {
-		return
+		return 0
 	}
 
+	if err := w.sendBatch(ctx, producer, tasks); err == nil {
+		return len(tasks)
+	}
+
+	w.logger.Warnw("batch transaction failed, retrying tasks one by one", "size", len(tasks))
+
 	for _, task := range tasks {
 		select {
 		case <-ctx.Done():
-			return
+			return len(tasks)
 		default:
-			if err := w.processTask(ctx, task); err != nil {
+			if err := w.processTask(ctx, producer, task); err != nil {
 				w.logger.Errorw("failed to process a task",
 					"task_id", task.ID,
 					"error", err)
 			}
 		}
 	}
+
+	return len(tasks)
 }
 
-func (w *OutboxWorker) processTask(ctx context.Context, task domain.AuditTask) error {
-	now := time.Now().UTC()
+func (w *OutboxWorker) sendBatch(ctx context.Context, producer TaskProducer, tasks []domain.AuditTask) error {
+	messages := make([]kafka.Message, 0, len(tasks))
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		messages = append(messages, taskMessage(task))
+		ids = append(ids, task.ID)
+	}
 
-	task.Status = domain.StatusProcessing
-	task.UpdatedAt = now
-	if err := w.service.UpdateTask(ctx, task); err != nil {
+	kafkaCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := producer.SendTransactionalBatch(kafkaCtx, messages); err != nil {
 		return err
 	}
 
+	// События уже в Kafka. Если отметка не сохранится, задачи будут отправлены
+	// повторно после истечения аренды, поэтому ошибку только логируем.
+	if err := w.service.FinishTasks(ctx, ids); err != nil {
+		w.logger.Errorw("failed to mark batch finished", "error", err, "size", len(ids))
+	}
+	return nil
+}
+
+func (w *OutboxWorker) processTask(ctx context.Context, producer TaskProducer, task domain.AuditTask) error {
+	now := time.Now().UTC()
+
 	kafkaCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	err := w.kafkaProducer.SendTransactional(kafkaCtx, task.ID, task.AuditLog)
+	err := producer.SendTransactional(kafkaCtx, taskMessage(task))
 	if err != nil {
 		task.AttemptNumber++
 		task.Status = domain.StatusFailed
-		task.NextRetry = now.Add(w.retryDelay)
+		task.NextRetry = now.Add(w.retry.Delay(task.AttemptNumber))
 		task.UpdatedAt = now
 
-		if task.AttemptNumber >= w.maxAttempts {
+		if task.AttemptNumber >= w.retry.MaxAttempts {
 			task.Status = domain.StatusNoAttemptsLeft
+			metrics.IncOutboxDeadTasks()
+			w.logger.Warnw("task moved to dead letter",
+				"task_id", task.ID,
+				"attempts", task.AttemptNumber,
+				"error", err)
 		}
 
 		return w.service.UpdateTask(ctx, task)
@@ -121,3 +254,18 @@ func (w *OutboxWorker) processTask(ctx context.Context, task domain.AuditTask) e
 	task.FinishedAt = now
 	return w.service.UpdateTask(ctx, task)
 }
+
+// taskMessage — запись Kafka для задачи. У событий аудита ключ — ID задачи,
+// у событий заказа — ID заказа, чтобы события одного заказа попадали в одну
+// партицию.
+func taskMessage(task domain.AuditTask) kafka.Message {
+	key := task.MessageKey
+	if key == "" {
+		key = strconv.Itoa(task.ID)
+	}
+	return kafka.Message{
+		Topic: task.Topic,
+		Key:   []byte(key),
+		Value: task.AuditLog,
+	}
+}
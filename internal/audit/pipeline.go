@@ -2,81 +2,239 @@ package audit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/filter"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/schema"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
-	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	eventsv1 "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/events/v1"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 type FilterFunc func(domain.Event) bool
 
+type sinkRoute struct {
+	name   string
+	sink   Sink
+	filter FilterFunc
+	pool   *WorkerPool
+}
+
+// Pipeline раздает события подключенным получателям. У каждого получателя
+// свой фильтр и своя очередь, поэтому медленный получатель не задерживает
+// остальных.
+//
+// Очереди ограничены: SendEvent не создает горутин и не ждет дольше, чем
+// позволяет политика переполнения из QueueConfig.
 type Pipeline struct {
-	pools   []*WorkerPool
-	service service.AuditService
-	logger  *zap.SugaredLogger
+	routes   []sinkRoute
+	queueCfg QueueConfig
+	batchCfg BatchConfig
+	logger   *zap.SugaredLogger
+
+	// cancelWrites прерывает запись в получателей, если Shutdown не успел
+	// дождаться очередей
+	cancelWrites context.CancelFunc
 }
 
-func NewPipeline(service service.AuditService, logger *zap.SugaredLogger, pools ...*WorkerPool) *Pipeline {
-	return &Pipeline{
-		pools:   pools,
-		service: service,
-		logger:  logger,
-	}
+func NewPipeline(queueCfg QueueConfig, batchCfg BatchConfig, logger *zap.SugaredLogger) *Pipeline {
+	return &Pipeline{queueCfg: queueCfg, batchCfg: batchCfg, logger: logger}
 }
-func (p *Pipeline) StartWorkers(ctx context.Context, filterFunc FilterFunc) error {
-	p.pools[0].StartWorkers(ctx, p.saveToDB(ctx))
 
-	p.pools[1].StartWorkers(ctx, p.printToStdout(filterFunc))
+// AddSink подключает получателя. Вызывается до StartWorkers.
+func (p *Pipeline) AddSink(name string, sink Sink, filter FilterFunc) error {
+	pool, err := NewWorkerPool(name, p.queueCfg, p.batchCfg, p.logger)
+	if err != nil {
+		return err
+	}
 
+	p.routes = append(p.routes, sinkRoute{
+		name:   name,
+		sink:   sink,
+		filter: filter,
+		pool:   pool,
+	})
 	return nil
 }
 
-func (p *Pipeline) saveToDB(ctx context.Context) func(domain.Event) error {
-	return func(e domain.Event) error {
-		err := p.service.SaveLog(ctx, e)
-		return err
+// StartWorkers запускает воркеры получателей. Отмена ctx не прерывает
+// запись уже принятых событий — для этого есть Shutdown.
+func (p *Pipeline) StartWorkers(ctx context.Context) {
+	writeCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.cancelWrites = cancel
+	for _, route := range p.routes {
+		route.pool.StartWorkers(ctx, func(events []domain.Event) error {
+			return writeBatch(writeCtx, route.sink, events)
+		})
 	}
 }
 
-func (p *Pipeline) printToStdout(filterFunc FilterFunc) func(domain.Event) error {
-	return func(e domain.Event) error {
-		return nil
+// writeBatch пишет пачку одним вызовом, если получатель это поддерживает,
+// иначе по одному событию, не останавливаясь на ошибке.
+func writeBatch(ctx context.Context, sink Sink, events []domain.Event) error {
+	if batchSink, ok := sink.(BatchSink); ok {
+		return batchSink.WriteBatch(ctx, events)
+	}
+
+	var errs []error
+	for _, event := range events {
+		if err := sink.Write(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
-func (p *Pipeline) SendEvent(eventType domain.EventType, data any) {
-	event := domain.NewEvent(eventType, data)
+// SendEvent проверяет данные события по схеме (см. пакет schema) и
+// отправляет событие получателям. Событие, не прошедшее проверку, не
+// отправляется никуда.
+func (p *Pipeline) SendEvent(ctx context.Context, eventType domain.EventType, data proto.Message) {
+	payload, err := schema.Encode(eventType, data)
+	if err != nil {
+		metrics.IncAuditEventsInvalid("producer", string(eventType))
+		p.logger.Errorw("audit event rejected", "type", eventType, "error", err)
+		return
+	}
+	event := domain.NewEvent(ctx, eventType, schema.Version, payload)
+
+	for _, route := range p.routes {
+		if route.filter != nil && !route.filter(event) {
+			continue
+		}
 
-	for _, pool := range p.pools {
-		switch eventType {
-		case domain.EventAPIRequest, domain.EventAPIResponse:
-			go func(pool *WorkerPool) { pool.ApiChan <- event }(pool)
-		case domain.EventStatusChange:
-			go func(pool *WorkerPool) { pool.StatusChan <- event }(pool)
+		if err := route.pool.Enqueue(event); err != nil && !errors.Is(err, errQueueClosed) {
+			p.logger.Errorw("failed to enqueue audit event", "sink", route.name, "error", err)
 		}
 	}
 }
 
-func NewFilterFunc(filterKeyword string) FilterFunc {
-	return func(e domain.Event) bool {
-		if filterKeyword == "" {
-			return true
+// Shutdown перестает принимать события, ждет, пока воркеры допишут очереди,
+// и закрывает получателей. Если ctx истек раньше, запись в получателей
+// прерывается и неотправленные события теряются (при политике spill
+// сброшенные на диск остаются там до следующего запуска). Получатели
+// закрываются только после остановки всех воркеров.
+func (p *Pipeline) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for _, route := range p.routes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				route.pool.Close()
+			}()
 		}
+		wg.Wait()
+	}()
+
+	var errs []error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("очереди аудита не успели опустеть: %w", ctx.Err()))
+		// Воркеры быстро доходят до конца очередей: каждая запись сразу
+		// завершается ошибкой отмененного контекста
+		if p.cancelWrites != nil {
+			p.cancelWrites()
+		}
+		<-done
+	}
+	if p.cancelWrites != nil {
+		p.cancelWrites()
+	}
 
-		if data, ok := e.Data.(map[string]any); ok {
-			for key, value := range data {
-				if str, ok := value.(string); ok {
-					if strings.Contains(str, filterKeyword) {
-						return true
-					}
-				}
-				if strings.Contains(key, filterKeyword) {
-					return true
-				}
-			}
+	for _, route := range p.routes {
+		if err := route.sink.Close(); err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		return false
+// NewFilterFunc компилирует выражение AUDIT_FILTER (см. пакет filter).
+// Пустое выражение пропускает все события.
+func NewFilterFunc(expression string) (FilterFunc, error) {
+	if strings.TrimSpace(expression) == "" {
+		return func(domain.Event) bool { return true }, nil
+	}
+
+	expr, err := filter.Compile(expression)
+	if err == nil {
+		err = checkFilterFields(expr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("неверный фильтр аудита %q: %w", expression, err)
 	}
+
+	return func(e domain.Event) bool {
+		return expr.Match(eventFields(e))
+	}, nil
+}
+
+const oldFilterHint = `; фильтр из одного ключевого слова больше не поддерживается, нужно выражение, например data.status == "issued"`
+
+// checkFilterFields отклоняет выражения с неизвестными полями и без полей.
+// Так выглядят фильтры старого формата из одного ключевого слова: например,
+// AUDIT_FILTER=issued теперь означает поле issued, которого в событии нет,
+// и фильтр молча не пропускал бы ни одного события.
+func checkFilterFields(expr *filter.Expr) error {
+	fields := expr.Fields()
+	if len(fields) == 0 {
+		return errors.New("выражение не обращается ни к одному полю" + oldFilterHint)
+	}
+
+	known := eventFields(domain.Event{})
+	for _, field := range fields {
+		root, _, _ := strings.Cut(field, ".")
+		if _, ok := known[root]; !ok {
+			return fmt.Errorf("неизвестное поле %q%s", field, oldFilterHint)
+		}
+	}
+	return nil
+}
+
+func eventFields(e domain.Event) map[string]any {
+	return map[string]any{
+		"type":           string(e.Type),
+		"event_id":       e.EventID,
+		"schema_version": e.SchemaVersion,
+		"data":           eventData(e.Data),
+		"time":           e.Time.Format(time.RFC3339Nano),
+		"user":           e.User,
+		"request_id":     e.RequestID,
+		"trace_id":       e.TraceID,
+		"client_ip":      e.ClientIP,
+		"user_agent":     e.UserAgent,
+	}
+}
+
+// eventData приводит данные события к map, чтобы к ним можно было
+// обращаться по полям. Структуры проходят через JSON.
+func eventData(data any) any {
+	if m, ok := data.(map[string]any); ok {
+		return m
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	var result any
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// StatusChanged — данные события status_change.
+func StatusChanged(orderID string, status domain.OrderStatus) *eventsv1.OrderStatusChanged {
+	return &eventsv1.OrderStatusChanged{OrderId: orderID, Status: string(status)}
 }
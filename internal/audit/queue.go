@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// OverflowPolicy определяет, что делать с событием, когда очередь получателя заполнена.
+type OverflowPolicy string
+
+const (
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	OverflowBlock      OverflowPolicy = "block"
+	OverflowSpill      OverflowPolicy = "spill"
+)
+
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(s); p {
+	case OverflowDropOldest, OverflowDropNewest, OverflowBlock, OverflowSpill:
+		return p, nil
+	}
+	return "", fmt.Errorf("неизвестная политика переполнения %q", s)
+}
+
+type QueueConfig struct {
+	Capacity     int
+	Policy       OverflowPolicy
+	BlockTimeout time.Duration
+	SpillDir     string
+}
+
+var errQueueClosed = errors.New("очередь аудита закрыта")
+
+// queue — ограниченная очередь событий получателя. Enqueue никогда
+// не блокирует дольше BlockTimeout. При политике spill события, не
+// поместившиеся в очередь, дописываются в файл и возвращаются в очередь,
+// когда в ней освобождается место (в том числе после перезапуска).
+type queue struct {
+	name   string
+	events chan domain.Event
+	cfg    QueueConfig
+	logger *zap.SugaredLogger
+
+	mu     sync.RWMutex
+	closed bool
+
+	spillMu   sync.Mutex
+	spillPath string
+}
+
+func newQueue(name string, cfg QueueConfig, logger *zap.SugaredLogger) (*queue, error) {
+	q := &queue{
+		name:   name,
+		events: make(chan domain.Event, cfg.Capacity),
+		cfg:    cfg,
+		logger: logger,
+	}
+
+	if cfg.Policy == OverflowSpill {
+		if err := os.MkdirAll(cfg.SpillDir, 0o750); err != nil {
+			return nil, fmt.Errorf("не удалось создать каталог для сброса очереди: %w", err)
+		}
+		q.spillPath = filepath.Join(cfg.SpillDir, name+".jsonl")
+	}
+
+	return q, nil
+}
+
+func (q *queue) Enqueue(event domain.Event) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		metrics.IncAuditEventsDropped(q.name, "closed")
+		return errQueueClosed
+	}
+	defer q.updateDepth()
+
+	select {
+	case q.events <- event:
+		return nil
+	default:
+	}
+
+	switch q.cfg.Policy {
+	case OverflowDropNewest:
+		metrics.IncAuditEventsDropped(q.name, string(OverflowDropNewest))
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.events <- event:
+				return nil
+			default:
+			}
+			select {
+			case <-q.events:
+				metrics.IncAuditEventsDropped(q.name, string(OverflowDropOldest))
+			default:
+			}
+		}
+
+	case OverflowBlock:
+		timer := time.NewTimer(q.cfg.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case q.events <- event:
+			return nil
+		case <-timer.C:
+			metrics.IncAuditEventsDropped(q.name, string(OverflowBlock))
+		}
+
+	case OverflowSpill:
+		if err := q.spill(event); err != nil {
+			metrics.IncAuditEventsDropped(q.name, string(OverflowSpill))
+			return err
+		}
+		metrics.IncAuditEventsSpilled(q.name)
+		return nil
+	}
+
+	return nil
+}
+
+func (q *queue) spill(event domain.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	q.spillMu.Lock()
+	defer q.spillMu.Unlock()
+
+	file, err := os.OpenFile(q.spillPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// runReplay периодически возвращает сброшенные на диск события в очередь,
+// когда она заполнена не больше чем наполовину.
+func (q *queue) runReplay(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if len(q.events) <= cap(q.events)/2 {
+				if err := q.replay(ctx); err != nil {
+					q.logger.Errorw("failed to replay spilled audit events", "queue", q.name, "error", err)
+				}
+			}
+		}
+	}
+}
+
+func (q *queue) replay(ctx context.Context) error {
+	q.spillMu.Lock()
+	replayPath := q.spillPath + ".replay"
+	// Незавершенный прошлый повтор дочитывается первым
+	if _, err := os.Stat(replayPath); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(q.spillPath, replayPath); err != nil {
+			q.spillMu.Unlock()
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+	}
+	q.spillMu.Unlock()
+
+	file, err := os.Open(replayPath)
+	if err != nil {
+		return err
+	}
+
+	var rest []domain.Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 10<<20)
+	for scanner.Scan() {
+		var event domain.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			q.logger.Errorw("skipping corrupted spilled audit event", "queue", q.name, "error", err)
+			continue
+		}
+		if ctx.Err() != nil || !q.offer(event) {
+			rest = append(rest, event)
+		}
+	}
+	file.Close()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// То, что не поместилось, снова уходит на диск
+	for _, event := range rest {
+		if err := q.spill(event); err != nil {
+			return err
+		}
+	}
+	return os.Remove(replayPath)
+}
+
+// offer кладет событие в очередь, только если в ней есть место.
+func (q *queue) offer(event domain.Event) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+	select {
+	case q.events <- event:
+		q.updateDepth()
+		return true
+	default:
+		return false
+	}
+}
+
+// Close перестает принимать события. Уже поставленные в очередь будут
+// обработаны воркерами до конца.
+func (q *queue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.events)
+	}
+}
+
+func (q *queue) updateDepth() {
+	metrics.SetAuditQueueDepth(q.name, len(q.events))
+}
@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy задает число попыток отправки задачи и задержку между ними.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay возвращает задержку перед следующей попыткой: экспоненциальный рост
+// от BaseDelay до MaxDelay, из которого случайна вторая половина, чтобы
+// упавшие одновременно задачи не повторялись одной пачкой.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,203 @@
+// Package schema проверяет данные событий аудита по схемам из proto/events.
+//
+// Данные события — сообщение proto, которое пишется в запись outbox в JSON
+// с именами полей как в proto. Продюсер проверяет событие перед отправкой,
+// консьюмер — после чтения из Kafka, поэтому запись, которая не
+// соответствует схеме, не проходит дальше ни с одной из сторон.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	eventsv1 "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/events/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Version — версия схемы, которую пишет сервис. Записи версии 0 созданы до
+// появления схем: у них нет EventID, а данные не проверяются.
+const Version = 1
+
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+var ErrInvalidEvent = errors.New("событие не соответствует схеме")
+
+var (
+	marshaler   = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshaler = protojson.UnmarshalOptions{}
+)
+
+// Encode проверяет данные события и кодирует их в JSON для записи.
+func Encode(eventType domain.EventType, data proto.Message) (json.RawMessage, error) {
+	if err := Validate(eventType, data); err != nil {
+		return nil, err
+	}
+
+	raw, err := marshaler.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return raw, nil
+}
+
+// Decode проверяет заголовок записи и разбирает ее данные по схеме.
+// Неизвестные поля считаются ошибкой: их появление означает, что продюсер
+// пишет новую схему, не подняв версию.
+func Decode(eventType domain.EventType, eventID string, version int, raw json.RawMessage) (proto.Message, error) {
+	switch {
+	case version == 0:
+		return nil, nil
+	case version > Version:
+		return nil, fmt.Errorf("%w: неподдерживаемая версия схемы %d", ErrInvalidEvent, version)
+	case version < 0:
+		return nil, fmt.Errorf("%w: неверная версия схемы %d", ErrInvalidEvent, version)
+	}
+
+	if _, err := uuid.Parse(eventID); err != nil {
+		return nil, fmt.Errorf("%w: неверный EventID %q", ErrInvalidEvent, eventID)
+	}
+
+	data, err := newMessage(eventType)
+	if err != nil {
+		return nil, err
+	}
+	if err := unmarshaler.Unmarshal(raw, data); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidEvent, eventType, err)
+	}
+	if err := Validate(eventType, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Validate проверяет, что data — сообщение схемы для типа события и его
+// обязательные поля заполнены.
+func Validate(eventType domain.EventType, data proto.Message) error {
+	var err error
+	switch eventType {
+	case domain.EventStatusChange:
+		msg, ok := data.(*eventsv1.OrderStatusChanged)
+		if !ok {
+			return wrongMessage(eventType, data)
+		}
+		err = validateStatusChanged(msg)
+	case domain.EventAPIRequest:
+		msg, ok := data.(*eventsv1.ApiRequest)
+		if !ok {
+			return wrongMessage(eventType, data)
+		}
+		err = validateAPIRequest(msg)
+	case domain.EventAPIResponse:
+		msg, ok := data.(*eventsv1.ApiResponse)
+		if !ok {
+			return wrongMessage(eventType, data)
+		}
+		err = validateAPIResponse(msg)
+	default:
+		return fmt.Errorf("%w: неизвестный тип %q", ErrInvalidEvent, eventType)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidEvent, eventType, err)
+	}
+	return nil
+}
+
+func newMessage(eventType domain.EventType) (proto.Message, error) {
+	switch eventType {
+	case domain.EventStatusChange:
+		return &eventsv1.OrderStatusChanged{}, nil
+	case domain.EventAPIRequest:
+		return &eventsv1.ApiRequest{}, nil
+	case domain.EventAPIResponse:
+		return &eventsv1.ApiResponse{}, nil
+	}
+	return nil, fmt.Errorf("%w: неизвестный тип %q", ErrInvalidEvent, eventType)
+}
+
+func wrongMessage(eventType domain.EventType, data proto.Message) error {
+	return fmt.Errorf("%w: для типа %q передано %T", ErrInvalidEvent, eventType, data)
+}
+
+var orderStatuses = map[domain.OrderStatus]struct{}{
+	domain.StatusStored:            {},
+	domain.StatusIssued:            {},
+	domain.StatusRefunded:          {},
+	domain.StatusReturnedToCourier: {},
+}
+
+func validateStatusChanged(msg *eventsv1.OrderStatusChanged) error {
+	if msg.GetOrderId() == "" {
+		return errors.New("пустой order_id")
+	}
+	if _, ok := orderStatuses[domain.OrderStatus(msg.GetStatus())]; !ok {
+		return fmt.Errorf("неизвестный статус заказа %q", msg.GetStatus())
+	}
+	return nil
+}
+
+func validateAPIRequest(msg *eventsv1.ApiRequest) error {
+	return validateCall(msg.GetTransport(), msg.GetMethod(), msg.GetPath())
+}
+
+func validateAPIResponse(msg *eventsv1.ApiResponse) error {
+	if err := validateCall(msg.GetTransport(), msg.GetMethod(), msg.GetPath()); err != nil {
+		return err
+	}
+
+	switch msg.GetTransport() {
+	case TransportHTTP:
+		if http.StatusText(int(msg.GetStatus())) == "" {
+			return fmt.Errorf("неверный HTTP-код %d", msg.GetStatus())
+		}
+		if msg.GetGrpcCode() != "" {
+			return errors.New("grpc_code задан для http")
+		}
+	case TransportGRPC:
+		if _, ok := grpcCodes[msg.GetGrpcCode()]; !ok {
+			return fmt.Errorf("неверный код gRPC %q", msg.GetGrpcCode())
+		}
+		if msg.GetStatus() != 0 {
+			return errors.New("status задан для grpc")
+		}
+	}
+	return nil
+}
+
+// grpcCodes — имена кодов в виде codes.Code.String().
+var grpcCodes = func() map[string]struct{} {
+	names := make(map[string]struct{})
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		names[code.String()] = struct{}{}
+	}
+	return names
+}()
+
+func validateCall(transport, method, path string) error {
+	if method == "" {
+		return errors.New("пустой method")
+	}
+
+	switch transport {
+	case TransportHTTP:
+		if path == "" {
+			return errors.New("пустой path")
+		}
+	case TransportGRPC:
+		if path != "" {
+			return errors.New("path задан для grpc")
+		}
+	default:
+		return fmt.Errorf("неизвестный transport %q", transport)
+	}
+	return nil
+}
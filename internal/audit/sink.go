@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+// Sink — получатель событий аудита. Write вызывается из нескольких
+// обработчиков одновременно.
+type Sink interface {
+	Write(ctx context.Context, event domain.Event) error
+	Close() error
+}
+
+// BatchSink — получатель, который умеет записать пачку событий за один
+// раз. Остальным получателям пачка передается по одному событию.
+type BatchSink interface {
+	Sink
+	WriteBatch(ctx context.Context, events []domain.Event) error
+}
+
+// SinkDeps — то, из чего фабрика может собрать получателя.
+type SinkDeps struct {
+	Config       *config.Config
+	AuditService service.AuditService
+	Logger       *zap.SugaredLogger
+}
+
+type SinkFactory func(deps SinkDeps) (Sink, error)
+
+var sinkFactories = map[string]SinkFactory{}
+
+// RegisterSink регистрирует фабрику получателя под именем, которое
+// указывается в AUDIT_SINKS.
+func RegisterSink(name string, factory SinkFactory) {
+	if _, exists := sinkFactories[name]; exists {
+		panic("audit: sink " + name + " already registered")
+	}
+	sinkFactories[name] = factory
+}
+
+func NewSink(name string, deps SinkDeps) (Sink, error) {
+	factory, ok := sinkFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный получатель аудита %q, доступны: %s", name, strings.Join(SinkNames(), ", "))
+	}
+	return factory(deps)
+}
+
+func SinkNames() []string {
+	names := make([]string, 0, len(sinkFactories))
+	for name := range sinkFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+func init() {
+	RegisterSink("file", func(deps SinkDeps) (Sink, error) {
+		return newFileSink(deps.Config.AuditFilePath, deps.Config.AuditFileMaxSizeMB<<20, deps.Config.AuditFileMaxBackups)
+	})
+}
+
+// fileSink пишет события в файл по одному JSON на строку. Когда файл
+// превышает maxSize, он переименовывается в path.1 (старые копии сдвигаются,
+// лишние удаляются) и запись продолжается в новый файл.
+type fileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newFileSink(path string, maxSize int64, maxBackups int) (*fileSink, error) {
+	s := &fileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть файл аудита: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *fileSink) Write(_ context.Context, event domain.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rotateErr error
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		rotateErr = s.rotate()
+	}
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return errors.Join(rotateErr, err)
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return errors.Join(rotateErr, err)
+}
+
+// rotate переносит текущий файл в копии и открывает новый. Если перенести
+// не удалось, снова открывается текущий файл: события продолжают писаться
+// в него, а ротация повторится на следующей записи.
+func (s *fileSink) rotate() error {
+	closeErr := s.file.Close()
+	s.file = nil
+	if closeErr != nil {
+		return errors.Join(fmt.Errorf("не удалось закрыть файл аудита: %w", closeErr), s.open())
+	}
+
+	if err := s.shiftBackups(); err != nil {
+		return errors.Join(fmt.Errorf("не удалось ротировать файл аудита: %w", err), s.open())
+	}
+	return s.open()
+}
+
+// shiftBackups сдвигает копии path.N -> path.N+1, удаляя самую старую,
+// и переименовывает текущий файл в path.1.
+func (s *fileSink) shiftBackups() error {
+	if s.maxBackups <= 0 {
+		return os.Remove(s.path)
+	}
+
+	if err := removeIfExists(s.backupPath(s.maxBackups)); err != nil {
+		return err
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(s.path, s.backupPath(1))
+}
+
+func (s *fileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+)
+
+func init() {
+	RegisterSink("kafka", func(deps SinkDeps) (Sink, error) {
+		writer, err := kafka.NewWriter(deps.Config.KafkaBrokers, deps.Config.AuditKafkaTopic, deps.Logger)
+		if err != nil {
+			return nil, err
+		}
+		return &kafkaSink{writer: writer}, nil
+	})
+}
+
+// kafkaSink отправляет события в Kafka напрямую, минуя outbox. Быстрее, но
+// событие теряется, если Kafka недоступна. Топик должен отличаться от топика
+// outbox: consumer ждет там ключи задач, а здесь ключ — request ID.
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func (s *kafkaSink) Write(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// События одного запроса попадают в одну партицию и читаются по порядку
+	var key []byte
+	if event.RequestID != "" {
+		key = []byte(event.RequestID)
+	}
+	return s.writer.Write(ctx, key, payload)
+}
+
+func (s *kafkaSink) Close() error {
+	s.writer.Close()
+	return nil
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+func init() {
+	RegisterSink("postgres", func(deps SinkDeps) (Sink, error) {
+		return &postgresSink{service: deps.AuditService, logger: deps.Logger}, nil
+	})
+}
+
+// postgresSink пишет события в outbox audit_tasks, откуда их забирает OutboxWorker.
+type postgresSink struct {
+	service service.AuditService
+	logger  *zap.SugaredLogger
+}
+
+func (s *postgresSink) Write(ctx context.Context, event domain.Event) error {
+	return s.service.SaveLog(ctx, event)
+}
+
+// WriteBatch сохраняет пачку одним COPY вместо INSERT на каждое событие.
+// Если пачка не сохранилась, события сохраняются по одному: так одно
+// проблемное событие или кратковременный сбой не стоят всей пачки.
+func (s *postgresSink) WriteBatch(ctx context.Context, events []domain.Event) error {
+	err := s.service.SaveLogs(ctx, events)
+	if err == nil {
+		return nil
+	}
+	s.logger.Warnw("failed to save audit batch, saving events one by one", "batch_size", len(events), "error", err)
+
+	var errs []error
+	for _, event := range events {
+		if err := s.service.SaveLog(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *postgresSink) Close() error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"context"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"go.uber.org/zap"
+)
+
+func init() {
+	RegisterSink("stdout", func(deps SinkDeps) (Sink, error) {
+		return &stdoutSink{logger: deps.Logger.Named("audit")}, nil
+	})
+}
+
+// stdoutSink пишет события структурированным логом zap.
+type stdoutSink struct {
+	logger *zap.SugaredLogger
+}
+
+func (s *stdoutSink) Write(_ context.Context, event domain.Event) error {
+	s.logger.Infow("audit event",
+		"type", event.Type,
+		"data", event.Data,
+		"time", event.Time,
+		"user", event.User,
+		"request_id", event.RequestID,
+		"trace_id", event.TraceID,
+		"client_ip", event.ClientIP,
+		"user_agent", event.UserAgent,
+	)
+	return nil
+}
+
+func (s *stdoutSink) Close() error {
+	return nil
+}
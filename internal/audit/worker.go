@@ -1,67 +1,79 @@
 package audit
 
 import (
-	"context"
 	"time"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"go.uber.org/zap"
 )
 
+// BatchConfig задает, когда воркер сбрасывает накопленную пачку: по
+// достижении Size событий или через FlushInterval после последнего сброса.
+type BatchConfig struct {
+	Size          int
+	FlushInterval time.Duration
+}
+
 type Worker struct {
 	inputChan   <-chan domain.Event
-	processFunc func(domain.Event) error
+	processFunc func([]domain.Event) error
 	workerType  string
+	batch       BatchConfig
 	logger      *zap.SugaredLogger
 }
 
-func NewWorker(input <-chan domain.Event, f func(domain.Event) error, workerType string, logger *zap.SugaredLogger) *Worker {
+// NewWorker создает воркер. processFunc не должен сохранять переданный срез:
+// после возврата он переиспользуется для следующей пачки.
+func NewWorker(input <-chan domain.Event, f func([]domain.Event) error, workerType string, batch BatchConfig, logger *zap.SugaredLogger) *Worker {
 	return &Worker{
 		inputChan:   input,
 		processFunc: f,
 		workerType:  workerType,
+		batch:       batch,
 		logger:      logger,
 	}
 }
 
-func (w *Worker) Run(ctx context.Context) {
-	batch := make([]domain.Event, 0, 5)
-	ticker := time.NewTicker(500 * time.Millisecond)
+// Run обрабатывает события, пока входной канал не закрыт, и перед выходом
+// дописывает неполную пачку.
+func (w *Worker) Run() {
+	batch := make([]domain.Event, 0, w.batch.Size)
+	ticker := time.NewTicker(w.batch.FlushInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case event := <-w.inputChan:
+		case event, ok := <-w.inputChan:
+			if !ok {
+				if len(batch) > 0 {
+					w.processBatch(batch)
+				}
+				return
+			}
+
 			batch = append(batch, event)
-			if len(batch) >= 5 {
+			if len(batch) >= w.batch.Size {
 				w.processBatch(batch)
 				batch = batch[:0]
-				ticker.Reset(500 * time.Millisecond)
+				ticker.Reset(w.batch.FlushInterval)
 			}
 
 		case <-ticker.C:
 			if len(batch) > 0 {
 				w.processBatch(batch)
 				batch = batch[:0]
-				ticker.Reset(500 * time.Millisecond)
+				ticker.Reset(w.batch.FlushInterval)
 			}
-
-		case <-ctx.Done():
-			if len(batch) > 0 {
-				w.processBatch(batch)
-			}
-			return
 		}
 	}
 }
 
 func (w *Worker) processBatch(events []domain.Event) {
-	for _, event := range events {
-		if err := w.processFunc(event); err != nil {
-			w.logger.Errorw("failed to process audit event",
-				"worker_type", w.workerType,
-				"error", err,
-			)
-		}
+	if err := w.processFunc(events); err != nil {
+		w.logger.Errorw("failed to process audit events",
+			"worker_type", w.workerType,
+			"batch_size", len(events),
+			"error", err,
+		)
 	}
 }
@@ -2,27 +2,77 @@ package audit
 
 import (
 	"context"
+	"sync"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"go.uber.org/zap"
 )
 
+// WorkerPool держит две ограниченные очереди получателя: для событий API и
+// для смены статусов, чтобы поток запросов не вытеснял события заказов.
 type WorkerPool struct {
-	StatusChan chan domain.Event
-	ApiChan    chan domain.Event
-	logger     *zap.SugaredLogger
+	statusQueue *queue
+	apiQueue    *queue
+	batch       BatchConfig
+	logger      *zap.SugaredLogger
+	wg          sync.WaitGroup
 }
 
-func NewWorkerPool(logger *zap.SugaredLogger) *WorkerPool {
+func NewWorkerPool(sinkName string, cfg QueueConfig, batch BatchConfig, logger *zap.SugaredLogger) (*WorkerPool, error) {
+	statusQueue, err := newQueue(sinkName+"_status", cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	apiQueue, err := newQueue(sinkName+"_api", cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &WorkerPool{
-		StatusChan: make(chan domain.Event, 1000),
-		ApiChan:    make(chan domain.Event, 1000),
-		logger:     logger,
+		statusQueue: statusQueue,
+		apiQueue:    apiQueue,
+		batch:       batch,
+		logger:      logger,
+	}, nil
+}
+
+// Enqueue кладет событие в очередь по его типу, не блокируясь дольше,
+// чем позволяет политика переполнения.
+func (p *WorkerPool) Enqueue(event domain.Event) error {
+	switch event.Type {
+	case domain.EventAPIRequest, domain.EventAPIResponse:
+		return p.apiQueue.Enqueue(event)
+	case domain.EventStatusChange:
+		return p.statusQueue.Enqueue(event)
 	}
+	return nil
 }
 
-func (p *WorkerPool) StartWorkers(ctx context.Context, handler func(domain.Event) error) {
-	go NewWorker(p.ApiChan, handler, "api_worker", p.logger).Run(ctx)
+// StartWorkers запускает воркеры, которые работают до закрытия очередей.
+// ctx ограничивает только повтор событий, сброшенных на диск.
+func (p *WorkerPool) StartWorkers(ctx context.Context, handler func([]domain.Event) error) {
+	for _, q := range []*queue{p.apiQueue, p.statusQueue} {
+		worker := NewWorker(q.events, func(events []domain.Event) error {
+			q.updateDepth()
+			return handler(events)
+		}, q.name+"_worker", p.batch, p.logger)
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			worker.Run()
+		}()
+
+		if q.cfg.Policy == OverflowSpill {
+			go q.runReplay(ctx)
+		}
+	}
+}
 
-	go NewWorker(p.StatusChan, handler, "status_worker", p.logger).Run(ctx)
+// Close перестает принимать события и ждет, пока воркеры обработают то,
+// что уже стоит в очередях.
+func (p *WorkerPool) Close() {
+	p.apiQueue.Close()
+	p.statusQueue.Close()
+	p.wg.Wait()
 }
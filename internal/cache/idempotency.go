@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+const idempotencyKeyPrefix = "idempotency:"
+
+// ErrReservationLost — ключ больше не занят этим запросом: резервирование
+// истекло, и ключ, возможно, занял повтор.
+var ErrReservationLost = errors.New("резервирование ключа идемпотентности потеряно")
+
+type IdempotencyStore interface {
+	// Reserve занимает ключ на ttl значением pending с токеном
+	// резервирования. Если ключ уже занят, возвращает сохраненный ответ
+	// (Pending, пока первый запрос выполняется).
+	Reserve(ctx context.Context, key string, pending domain.IdempotentResponse, ttl time.Duration) (*domain.IdempotentResponse, error)
+	// Save и Release меняют ключ, только если он все еще занят
+	// резервированием с токеном token, иначе возвращают ErrReservationLost.
+	Save(ctx context.Context, key, token string, response domain.IdempotentResponse, ttl time.Duration) error
+	Release(ctx context.Context, key, token string) error
+}
+
+// RedisIdempotencyStore хранит ответы на запросы с ключом идемпотентности.
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client}
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key string, pending domain.IdempotentResponse, ttl time.Duration) (*domain.IdempotentResponse, error) {
+	value, err := json.Marshal(pending)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := s.client.SetNX(ctx, idempotencyKeyPrefix+key, value, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	stored, err := s.client.Get(ctx, idempotencyKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Ключ истек между SETNX и GET: первый запрос только что сняли
+		return &pending, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var response domain.IdempotentResponse
+	if err := json.Unmarshal(stored, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// saveScript и releaseScript меняют ключ, только если в нем лежит
+// резервирование с токеном ARGV[1].
+var (
+	saveScript = redis.NewScript(`
+		local current = redis.call('GET', KEYS[1])
+		if not current then return 0 end
+		local saved = cjson.decode(current)
+		if not saved.pending or saved.token ~= ARGV[1] then return 0 end
+		redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+		return 1
+	`)
+	releaseScript = redis.NewScript(`
+		local current = redis.call('GET', KEYS[1])
+		if not current then return 0 end
+		local saved = cjson.decode(current)
+		if not saved.pending or saved.token ~= ARGV[1] then return 0 end
+		return redis.call('DEL', KEYS[1])
+	`)
+)
+
+func (s *RedisIdempotencyStore) Save(ctx context.Context, key, token string, response domain.IdempotentResponse, ttl time.Duration) error {
+	value, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	saved, err := saveScript.Run(ctx, s.client, []string{idempotencyKeyPrefix + key}, token, value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if saved == 0 {
+		return ErrReservationLost
+	}
+	return nil
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key, token string) error {
+	released, err := releaseScript.Run(ctx, s.client, []string{idempotencyKeyPrefix + key}, token).Int()
+	if err != nil {
+		return err
+	}
+	if released == 0 {
+		return ErrReservationLost
+	}
+	return nil
+}
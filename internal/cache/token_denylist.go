@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const revokedTokenKeyPrefix = "revoked_token:"
+
+type TokenDenyList interface {
+	Revoke(ctx context.Context, tokenID string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// RedisTokenDenyList хранит идентификаторы отозванных access токенов,
+// пока не истечет срок их действия.
+type RedisTokenDenyList struct {
+	client *redis.Client
+}
+
+func NewRedisTokenDenyList(client *redis.Client) *RedisTokenDenyList {
+	return &RedisTokenDenyList{client: client}
+}
+
+func (d *RedisTokenDenyList) Revoke(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, revokedTokenKeyPrefix+tokenID, 1, ttl).Err()
+}
+
+func (d *RedisTokenDenyList) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	n, err := d.client.Exists(ctx, revokedTokenKeyPrefix+tokenID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
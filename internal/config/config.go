@@ -1,45 +1,201 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DatabaseURL        string
-	HTTPPort           string
-	AuditFilter        string
-	CacheURL           string
-	CachePassword      string
-	KafkaBrokers       []string
-	KafkaConsumerGroup string
-	KafkaTopic         string
-	GRPCPort           string
-	JaegerServiceName  string
-	JaegerURL          string
-}
-
-func Load() *Config {
+	DatabaseURL           string
+	HTTPPort              string
+	TrustedProxies        []string
+	ShutdownTimeout       time.Duration
+	AuditFilter           string
+	AuditSinks            []string
+	AuditSinkFilters      map[string]string
+	AuditFilePath         string
+	AuditFileMaxSizeMB    int64
+	AuditFileMaxBackups   int
+	AuditKafkaTopic       string
+	AuditQueueSize        int
+	AuditOverflow         string
+	AuditBlockTimeout     time.Duration
+	AuditSpillDir         string
+	AuditDrainTimeout     time.Duration
+	AuditBatchSize        int
+	AuditFlushInterval    time.Duration
+	AuditHashKey          string
+	ConsumerMetricsPort   string
+	ConsumerAlertAttempts int
+	ConsumerMaxAttempts   int
+	ConsumerRetryBase     time.Duration
+	ConsumerRetryMax      time.Duration
+	KafkaDLQTopic         string
+	KafkaDLQGroup         string
+	KafkaReplayDLQTopic   string
+	CacheURL              string
+	CachePassword         string
+	IdempotencyTTL        time.Duration
+	IdempotencyLockTTL    time.Duration
+	KafkaBrokers          []string
+	KafkaConsumerGroup    string
+	KafkaTopic            string
+	GRPCPort              string
+	JaegerServiceName     string
+	JaegerURL             string
+	ReturnJobInterval     time.Duration
+	ReturnJobLimit        int
+	ReturnJobDryRun       bool
+	JWTSecret             string
+	JWTKeyFiles           map[string]string
+	JWTSigningKeyID       string
+	OutboxMaxAttempts     int
+	OutboxRetryBase       time.Duration
+	OutboxRetryMax        time.Duration
+	OutboxWorkers         int
+	OutboxBatchSize       int
+	OutboxPollInterval    time.Duration
+	KafkaTxIDPrefix       string
+}
+
+// Load читает конфигурацию из окружения и .env. Значения, с которыми сервис
+// не сможет работать, возвращаются ошибкой.
+func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		log.Fatalf("Ошибка загрузки .env файла: %v", err)
 	}
 
-	return &Config{
-		DatabaseURL:        getEnv("DATABASE_URL", ""),
-		HTTPPort:           getEnv("HTTP_PORT", ":9000"),
-		AuditFilter:        getEnv("AUDIT_FILTER", ""),
-		CacheURL:           getEnv("CACHE_URL", ""),
-		CachePassword:      getEnv("CACHE_PASSWORD", ""),
-		KafkaBrokers:       strings.Split(getEnv("KAFKA_BROKERS", ""), ","),
-		KafkaConsumerGroup: getEnv("KAFKA_CONSUMER_GROUP", ""),
-		KafkaTopic:         getEnv("KAFKA_TOPIC", ""),
-		GRPCPort:           getEnv("GRPC_PORT", ":8000"),
-		JaegerServiceName:  getEnv("JAEGER_SERVICE_NAME", ""),
-		JaegerURL:          getEnv("JAEGER_URL", ""),
+	auditFilter := getEnv("AUDIT_FILTER", "")
+	auditSinks := getEnvList("AUDIT_SINKS", []string{"postgres", "stdout"})
+	auditSinkFilters := make(map[string]string, len(auditSinks))
+	for _, sink := range auditSinks {
+		// AUDIT_FILTER относится только к stdout, остальные получатели по
+		// умолчанию получают все события
+		defaultFilter := ""
+		if sink == "stdout" {
+			defaultFilter = auditFilter
+		}
+		auditSinkFilters[sink] = getEnv("AUDIT_FILTER_"+strings.ToUpper(sink), defaultFilter)
+	}
+
+	cfg := &Config{
+		DatabaseURL:           getEnv("DATABASE_URL", ""),
+		HTTPPort:              getEnv("HTTP_PORT", ":9000"),
+		TrustedProxies:        getEnvList("TRUSTED_PROXIES", nil),
+		ShutdownTimeout:       getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+		AuditFilter:           auditFilter,
+		AuditSinks:            auditSinks,
+		AuditSinkFilters:      auditSinkFilters,
+		AuditFilePath:         getEnv("AUDIT_FILE_PATH", "audit.log"),
+		AuditFileMaxSizeMB:    int64(getEnvInt("AUDIT_FILE_MAX_SIZE_MB", 100)),
+		AuditFileMaxBackups:   getEnvInt("AUDIT_FILE_MAX_BACKUPS", 5),
+		AuditKafkaTopic:       getEnv("AUDIT_KAFKA_TOPIC", "audit_events.sink"),
+		AuditQueueSize:        getEnvInt("AUDIT_QUEUE_SIZE", 1000),
+		AuditOverflow:         getEnv("AUDIT_OVERFLOW_POLICY", "block"),
+		AuditBlockTimeout:     getEnvDuration("AUDIT_BLOCK_TIMEOUT", 50*time.Millisecond),
+		AuditSpillDir:         getEnv("AUDIT_SPILL_DIR", "audit-spill"),
+		AuditDrainTimeout:     getEnvDuration("AUDIT_DRAIN_TIMEOUT", 10*time.Second),
+		AuditBatchSize:        getEnvInt("AUDIT_BATCH_SIZE", 100),
+		AuditFlushInterval:    getEnvDuration("AUDIT_FLUSH_INTERVAL", 500*time.Millisecond),
+		AuditHashKey:          getEnv("AUDIT_HASH_KEY", ""),
+		ConsumerMetricsPort:   getEnv("CONSUMER_METRICS_PORT", ":2113"),
+		ConsumerAlertAttempts: getEnvInt("CONSUMER_ALERT_ATTEMPTS", 5),
+		ConsumerMaxAttempts:   getEnvInt("CONSUMER_MAX_ATTEMPTS", 10),
+		ConsumerRetryBase:     getEnvDuration("CONSUMER_RETRY_BASE_DELAY", 500*time.Millisecond),
+		ConsumerRetryMax:      getEnvDuration("CONSUMER_RETRY_MAX_DELAY", 30*time.Second),
+		KafkaDLQTopic:         getEnv("KAFKA_DLQ_TOPIC", "audit_logs.dlq"),
+		KafkaDLQGroup:         getEnv("KAFKA_DLQ_GROUP", "audit-dlq-reinject"),
+		KafkaReplayDLQTopic:   getEnv("KAFKA_REPLAY_DLQ_TOPIC", "audit_logs.replay.dlq"),
+		CacheURL:              getEnv("CACHE_URL", ""),
+		CachePassword:         getEnv("CACHE_PASSWORD", ""),
+		IdempotencyTTL:        getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyLockTTL:    getEnvDuration("IDEMPOTENCY_LOCK_TTL", 30*time.Second),
+		KafkaBrokers:          strings.Split(getEnv("KAFKA_BROKERS", ""), ","),
+		KafkaConsumerGroup:    getEnv("KAFKA_CONSUMER_GROUP", ""),
+		KafkaTopic:            getEnv("KAFKA_TOPIC", ""),
+		GRPCPort:              getEnv("GRPC_PORT", ":8000"),
+		JaegerServiceName:     getEnv("JAEGER_SERVICE_NAME", ""),
+		JaegerURL:             getEnv("JAEGER_URL", ""),
+		ReturnJobInterval:     getEnvDuration("RETURN_JOB_INTERVAL", time.Hour),
+		ReturnJobLimit:        getEnvInt("RETURN_JOB_LIMIT", 100),
+		ReturnJobDryRun:       getEnvBool("RETURN_JOB_DRY_RUN", false),
+		JWTSecret:             getEnv("JWT_SECRET", ""),
+		JWTKeyFiles:           getEnvMap("JWT_KEYS"),
+		JWTSigningKeyID:       getEnv("JWT_SIGNING_KEY_ID", ""),
+		OutboxMaxAttempts:     getEnvInt("OUTBOX_MAX_ATTEMPTS", 3),
+		OutboxRetryBase:       getEnvDuration("OUTBOX_RETRY_BASE_DELAY", 2*time.Second),
+		OutboxRetryMax:        getEnvDuration("OUTBOX_RETRY_MAX_DELAY", 5*time.Minute),
+		OutboxWorkers:         getEnvInt("OUTBOX_WORKERS", 4),
+		OutboxBatchSize:       getEnvInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxPollInterval:    getEnvDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+		KafkaTxIDPrefix:       getEnv("KAFKA_TRANSACTIONAL_ID", "audit-producer-v1"),
 	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate проверяет адреса доверенных прокси, топики получателя kafka и DLQ,
+// интервалы и размеры очередей: time.NewTicker с нулевым или отрицательным
+// интервалом и make(chan) с отрицательной емкостью паникуют уже в горутинах
+// фоновых задач.
+func (c *Config) validate() error {
+	var errs []error
+	positive := func(key string, value time.Duration) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s должен быть больше нуля, задано %s", key, value))
+		}
+	}
+	positiveInt := func(key string, value int) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s должен быть больше нуля, задано %d", key, value))
+		}
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: неверный адрес %q", proxy))
+			}
+		}
+	}
+
+	if slices.Contains(c.AuditSinks, "kafka") && c.AuditKafkaTopic == c.KafkaTopic {
+		errs = append(errs, fmt.Errorf("AUDIT_KAFKA_TOPIC не должен совпадать с KAFKA_TOPIC (%s): consumer не примет записи с ключом request ID", c.KafkaTopic))
+	}
+	if c.KafkaReplayDLQTopic == c.KafkaDLQTopic {
+		errs = append(errs, fmt.Errorf("KAFKA_REPLAY_DLQ_TOPIC не должен совпадать с KAFKA_DLQ_TOPIC (%s): повтор задвоит записи, уже попавшие в DLQ", c.KafkaDLQTopic))
+	}
+
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	positive("RETURN_JOB_INTERVAL", c.ReturnJobInterval)
+	positive("OUTBOX_POLL_INTERVAL", c.OutboxPollInterval)
+	positive("AUDIT_FLUSH_INTERVAL", c.AuditFlushInterval)
+	positive("AUDIT_DRAIN_TIMEOUT", c.AuditDrainTimeout)
+	positive("IDEMPOTENCY_TTL", c.IdempotencyTTL)
+	positive("IDEMPOTENCY_LOCK_TTL", c.IdempotencyLockTTL)
+	positiveInt("AUDIT_QUEUE_SIZE", c.AuditQueueSize)
+	positiveInt("AUDIT_BATCH_SIZE", c.AuditBatchSize)
+	positiveInt("RETURN_JOB_LIMIT", c.ReturnJobLimit)
+	positiveInt("OUTBOX_BATCH_SIZE", c.OutboxBatchSize)
+	positiveInt("OUTBOX_WORKERS", c.OutboxWorkers)
+	positiveInt("OUTBOX_MAX_ATTEMPTS", c.OutboxMaxAttempts)
+	if c.ConsumerMaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("CONSUMER_MAX_ATTEMPTS не может быть отрицательным, задано %d", c.ConsumerMaxAttempts))
+	}
+
+	return errors.Join(errs...)
 }
 
 func getEnv(key, defaultValue string) string {
@@ -49,3 +205,74 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("неверное значение %s=%q, используется %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("неверное значение %s=%q, используется %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("неверное значение %s=%q, используется %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+func getEnvList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue
+	}
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvMap разбирает значение вида "k1=v1,k2=v2".
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return result
+	}
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" || v == "" {
+			log.Printf("неверная пара %q в %s пропущена", pair, key)
+			continue
+		}
+		result[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return result
+}
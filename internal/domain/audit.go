@@ -1,6 +1,12 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type EventType string
 type TaskStatus string
@@ -19,12 +25,56 @@ const (
 	StatusFinished       TaskStatus = "FINISHED"
 )
 
+// Event — запись аудита. EventID уникален для каждого события,
+// SchemaVersion — версия схемы Data (см. proto/events).
 type Event struct {
-	Type EventType
-	Data any
-	Time time.Time
+	Type          EventType
+	EventID       string
+	SchemaVersion int
+	Data          any
+	Time          time.Time
+	User          string `json:",omitempty"`
+	RequestID     string `json:",omitempty"`
+	TraceID       string `json:",omitempty"`
+	ClientIP      string `json:",omitempty"`
+	UserAgent     string `json:",omitempty"`
 }
 
+var (
+	ErrInvalidAuditFilter = NewError(CodeInvalidAuditFilter, "неверные параметры фильтра аудита")
+	ErrAuditTaskNotFound  = NewError(CodeAuditTaskNotFound, "задачи аудита с таким ID не существует")
+)
+
+// AuditEventFilter задает условия выборки событий аудита. Пустые поля не фильтруют.
+type AuditEventFilter struct {
+	Type      EventType
+	OrderID   string
+	User      string
+	RequestID string
+	Path      string
+	Status    string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Cursor    *int
+}
+
+type AuditEvent struct {
+	ID            int             `json:"id"`
+	Type          EventType       `json:"type"`
+	EventID       string          `json:"event_id,omitempty"`
+	SchemaVersion int             `json:"schema_version,omitempty"`
+	Data          json.RawMessage `json:"data"`
+	Time          time.Time       `json:"time"`
+	User          string          `json:"user,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	TraceID       string          `json:"trace_id,omitempty"`
+	ClientIP      string          `json:"client_ip,omitempty"`
+	UserAgent     string          `json:"user_agent,omitempty"`
+}
+
+// AuditTask — задача outbox. Задачи аудита отправляются в топик аудита с
+// ключом — ID задачи; у остальных (события заказов) заданы Topic и MessageKey.
 type AuditTask struct {
 	ID            int
 	AuditLog      []byte
@@ -34,12 +84,59 @@ type AuditTask struct {
 	UpdatedAt     time.Time
 	FinishedAt    time.Time
 	NextRetry     time.Time
+	Topic         string
+	MessageKey    string
 }
 
-func NewEvent(t EventType, data any) Event {
+// AuditChainRecord — звено цепочки хешей: неизменяемые поля задачи аудита
+// в том виде, в каком их хранит база, и сохраненный хеш.
+type AuditChainRecord struct {
+	ID        int
+	CreatedAt time.Time
+	Data      []byte
+	Hash      []byte
+}
+
+// AuditChainHead — голова цепочки: id и хеш последнего звена.
+type AuditChainHead struct {
+	LastID int
+	Hash   []byte
+}
+
+// AuditChainReport — результат проверки цепочки. Broken указывает на первое
+// звено, хеш которого не сошелся: запись изменили, удалили предыдущую или
+// добавили в обход цепочки. Head — хеш головы цепочки; сохраненный вне базы,
+// он позволяет заметить удаление хвоста вместе с подменой головы.
+type AuditChainReport struct {
+	Valid   bool             `json:"valid"`
+	Checked int              `json:"checked"`
+	FirstID int              `json:"first_id,omitempty"`
+	LastID  int              `json:"last_id,omitempty"`
+	Head    string           `json:"head,omitempty"`
+	Broken  *AuditChainBreak `json:"broken,omitempty"`
+}
+
+type AuditChainBreak struct {
+	ID       int    `json:"id"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// NewEvent создает событие и привязывает его к пользователю и запросу из контекста.
+func NewEvent(ctx context.Context, t EventType, schemaVersion int, data any) Event {
+	info := RequestInfoFromContext(ctx)
+
 	return Event{
-		Type: t,
-		Data: data,
-		Time: time.Now().UTC(),
+		Type:          t,
+		EventID:       uuid.NewString(),
+		SchemaVersion: schemaVersion,
+		Data:          data,
+		Time:          time.Now().UTC(),
+		User:          ActorFromContext(ctx),
+		RequestID:     info.RequestID,
+		TraceID:       info.TraceID,
+		ClientIP:      info.ClientIP,
+		UserAgent:     info.UserAgent,
 	}
 }
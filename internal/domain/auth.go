@@ -1,24 +1,66 @@
 package domain
 
 import (
-	"errors"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
-	JwtSecret       = "OZONROUTE256GOJUNIOR"
-	TokenExpiration = 24 * time.Hour
+	TokenExpiration        = 15 * time.Minute
+	RefreshTokenExpiration = 30 * 24 * time.Hour
 )
 
 var (
-	ErrUserAlreadyExists  = errors.New("пользователь с таким email уже зарегистрирован")
-	ErrInvalidCredentials = errors.New("email или пароль неверны")
-	ErrEmptyPassword      = errors.New("пароль не может быть пустым")
-	ErrHashPassword       = errors.New("ошибка хеширования пароля")
-	ErrTokenGeneration    = errors.New("ошибка генерации токена")
+	ErrUserAlreadyExists   = NewError(CodeUserAlreadyExists, "пользователь с таким email уже зарегистрирован")
+	ErrInvalidCredentials  = NewError(CodeInvalidCredentials, "email или пароль неверны")
+	ErrEmptyPassword       = NewError(CodeEmptyPassword, "пароль не может быть пустым")
+	ErrHashPassword        = NewError(CodeHashPassword, "ошибка хеширования пароля")
+	ErrTokenGeneration     = NewError(CodeTokenGeneration, "ошибка генерации токена")
+	ErrUserNotFound        = NewError(CodeUserNotFound, "пользователь не найден")
+	ErrInvalidRole         = NewError(CodeInvalidRole, "неизвестная роль")
+	ErrForbidden           = NewError(CodeForbidden, "недостаточно прав")
+	ErrUnauthenticated     = NewError(CodeUnauthenticated, "необходимо выполнить вход")
+	ErrInvalidToken        = NewError(CodeInvalidToken, "токен недействителен")
+	ErrTokenRevoked        = NewError(CodeTokenRevoked, "токен отозван")
+	ErrInvalidRefreshToken = NewError(CodeInvalidRefreshToken, "refresh токен недействителен")
 )
 
 type User struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
+	Role     Role   `json:"-"`
+}
+
+type Claims struct {
+	Email string `json:"email"`
+	Role  Role   `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// RevokeReason — причина отзыва refresh токена.
+type RevokeReason string
+
+const (
+	// RevokeRotated — токен обменян на новую пару. Только его повторное
+	// использование считается признаком кражи.
+	RevokeRotated RevokeReason = "rotated"
+	RevokeLogout  RevokeReason = "logout"
+	// RevokeReuse — токен отозван вместе со всеми токенами пользователя
+	// после повторного использования обменянного токена.
+	RevokeReuse RevokeReason = "reuse"
+)
+
+// RefreshToken хранится в БД только в виде хеша.
+type RefreshToken struct {
+	TokenHash    string
+	Email        string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+	RevokeReason RevokeReason
 }
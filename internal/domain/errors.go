@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"maps"
+	"sort"
+	"strings"
+)
+
+// ErrorCode — стабильный машиночитаемый код ошибки. Клиенты разбирают код,
+// а не текст, поэтому коды не переименовываются и не удаляются.
+type ErrorCode string
+
+const (
+	CodeOrderNotFound            ErrorCode = "ORDER_NOT_FOUND"
+	CodeOrderAlreadyExists       ErrorCode = "ORDER_ALREADY_EXISTS"
+	CodeOrderExpired             ErrorCode = "ORDER_EXPIRED"
+	CodeOrderNotExpired          ErrorCode = "ORDER_NOT_EXPIRED"
+	CodeOrderNotStored           ErrorCode = "ORDER_NOT_STORED"
+	CodeOrderNotIssued           ErrorCode = "ORDER_NOT_ISSUED"
+	CodeOrderRefundPeriodExpired ErrorCode = "ORDER_REFUND_PERIOD_EXPIRED"
+	CodeOrderNotOwned            ErrorCode = "ORDER_NOT_OWNED"
+	CodeOrderInvalidTransition   ErrorCode = "ORDER_INVALID_TRANSITION"
+	CodeOrderIDsEmpty            ErrorCode = "ORDER_IDS_EMPTY"
+	CodeOrdersNotReady           ErrorCode = "ORDERS_NOT_READY"
+	CodeUserNoActiveOrders       ErrorCode = "USER_NO_ACTIVE_ORDERS"
+	CodeOrderBatchRolledBack     ErrorCode = "ORDER_BATCH_ROLLED_BACK"
+	CodeInvalidWeight            ErrorCode = "INVALID_WEIGHT"
+	CodeUnknownPackaging         ErrorCode = "UNKNOWN_PACKAGING"
+	CodePackagingCombination     ErrorCode = "PACKAGING_COMBINATION"
+	CodeInvalidMoney             ErrorCode = "INVALID_MONEY"
+	CodeUnknownCurrency          ErrorCode = "UNKNOWN_CURRENCY"
+
+	CodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+	CodeInvalidCommand ErrorCode = "INVALID_COMMAND"
+	CodeInvalidLimit   ErrorCode = "INVALID_LIMIT"
+	CodeInvalidCursor  ErrorCode = "INVALID_CURSOR"
+	CodeInvalidTime    ErrorCode = "INVALID_TIME"
+	CodeMissingField   ErrorCode = "MISSING_FIELD"
+	CodeInvalidTaskID  ErrorCode = "INVALID_TASK_ID"
+
+	CodeUserAlreadyExists   ErrorCode = "USER_ALREADY_EXISTS"
+	CodeInvalidCredentials  ErrorCode = "INVALID_CREDENTIALS"
+	CodeEmptyPassword       ErrorCode = "EMPTY_PASSWORD"
+	CodeUserNotFound        ErrorCode = "USER_NOT_FOUND"
+	CodeInvalidRole         ErrorCode = "INVALID_ROLE"
+	CodeForbidden           ErrorCode = "FORBIDDEN"
+	CodeUnauthenticated     ErrorCode = "UNAUTHENTICATED"
+	CodeInvalidToken        ErrorCode = "INVALID_TOKEN"
+	CodeTokenRevoked        ErrorCode = "TOKEN_REVOKED"
+	CodeInvalidRefreshToken ErrorCode = "INVALID_REFRESH_TOKEN"
+
+	CodeInvalidAuditFilter ErrorCode = "INVALID_AUDIT_FILTER"
+	CodeAuditTaskNotFound  ErrorCode = "AUDIT_TASK_NOT_FOUND"
+
+	CodeInvalidIdempotencyKey  ErrorCode = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused   ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress  ErrorCode = "IDEMPOTENCY_IN_PROGRESS"
+	CodeIdempotencyUnavailable ErrorCode = "IDEMPOTENCY_UNAVAILABLE"
+
+	CodeDatabase        ErrorCode = "DATABASE_ERROR"
+	CodeCache           ErrorCode = "CACHE_ERROR"
+	CodeHashPassword    ErrorCode = "PASSWORD_HASH_FAILED"
+	CodeTokenGeneration ErrorCode = "TOKEN_GENERATION_FAILED"
+	CodeInternal        ErrorCode = "INTERNAL"
+)
+
+// CodedError — ошибка из каталога. Params — значения для подстановки в
+// текст ошибки на других языках, они же отдаются клиенту.
+type CodedError interface {
+	error
+	Code() ErrorCode
+	Params() map[string]string
+}
+
+// Error — ошибка каталога без собственного типа. Текст — русский шаблон,
+// в котором {имя} заменяется параметром.
+type Error struct {
+	code     ErrorCode
+	template string
+	params   map[string]string
+	base     *Error
+}
+
+func NewError(code ErrorCode, template string) *Error {
+	return &Error{code: code, template: template}
+}
+
+func (e *Error) Error() string {
+	return ExpandParams(e.template, e.params)
+}
+
+func (e *Error) Code() ErrorCode {
+	return e.code
+}
+
+func (e *Error) Params() map[string]string {
+	return e.params
+}
+
+// With возвращает копию ошибки с параметром. errors.Is для копии и
+// исходной ошибки возвращает true.
+func (e *Error) With(key, value string) *Error {
+	params := maps.Clone(e.params)
+	if params == nil {
+		params = make(map[string]string, 1)
+	}
+	params[key] = value
+
+	base := e
+	if e.base != nil {
+		base = e.base
+	}
+	return &Error{code: e.code, template: e.template, params: params, base: base}
+}
+
+func (e *Error) Unwrap() error {
+	if e.base == nil {
+		return nil
+	}
+	return e.base
+}
+
+// ExpandParams подставляет параметры в шаблон. Параметры, которых нет в
+// шаблоне, дописываются в конец, чтобы не потеряться в логах.
+func ExpandParams(template string, params map[string]string) string {
+	if len(params) == 0 {
+		return template
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := template
+	var rest []string
+	for _, key := range keys {
+		placeholder := "{" + key + "}"
+		if strings.Contains(result, placeholder) {
+			result = strings.ReplaceAll(result, placeholder, params[key])
+			continue
+		}
+		rest = append(rest, key+"="+params[key])
+	}
+	if len(rest) > 0 {
+		result += " (" + strings.Join(rest, ", ") + ")"
+	}
+	return result
+}
+
+var (
+	ErrInvalidCommand = NewError(CodeInvalidCommand, "неверная команда")
+	ErrInvalidLimit   = NewError(CodeInvalidLimit, "неверный формат лимита")
+	ErrInvalidCursor  = NewError(CodeInvalidCursor, "неверный формат курсора")
+	// ErrInvalidTime и ErrMissingField используются с параметром field.
+	ErrInvalidTime   = NewError(CodeInvalidTime, "неверный формат времени {field}")
+	ErrMissingField  = NewError(CodeMissingField, "нужно указать {field}")
+	ErrInvalidTaskID = NewError(CodeInvalidTaskID, "неверный ID задачи")
+
+	ErrInternal = NewError(CodeInternal, "внутренняя ошибка")
+)
@@ -0,0 +1,38 @@
+package domain
+
+// IdempotencyHeader — заголовок HTTP с ключом идемпотентности. В gRPC ключ
+// передается в метаданных idempotency-key.
+const IdempotencyHeader = "Idempotency-Key"
+
+const MaxIdempotencyKeyLength = 255
+
+// IdempotentRequest — запрос с ключом идемпотентности. Scope отделяет
+// ответы разных транспортов (http, grpc), Fingerprint — хеш запроса, по
+// которому повтор отличается от другого запроса с тем же ключом.
+type IdempotentRequest struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+}
+
+// IdempotentResponse — первый ответ на запрос с ключом. Code — HTTP-статус
+// или код gRPC, Body — тело ответа или сообщение proto, ContentType — тип
+// тела или полное имя сообщения proto. Пока запрос выполняется, Pending
+// равен true, а Token отличает его резервирование от резервирования повтора,
+// занявшего ключ после истечения блокировки.
+type IdempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Pending     bool   `json:"pending,omitempty"`
+	Token       string `json:"token,omitempty"`
+	Code        int    `json:"code"`
+	ContentType string `json:"content_type,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+var (
+	ErrInvalidIdempotencyKey  = NewError(CodeInvalidIdempotencyKey, "неверный ключ идемпотентности")
+	ErrIdempotencyKeyReused   = NewError(CodeIdempotencyKeyReused, "ключ идемпотентности уже использован для другого запроса")
+	ErrIdempotencyInProgress  = NewError(CodeIdempotencyInProgress, "запрос с этим ключом идемпотентности еще выполняется")
+	ErrIdempotencyUnavailable = NewError(CodeIdempotencyUnavailable, "не удалось проверить ключ идемпотентности")
+)
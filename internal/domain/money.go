@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Currency — код валюты ISO 4217.
+type Currency string
+
+const (
+	CurrencyRUB Currency = "RUB"
+	CurrencyUSD Currency = "USD"
+	CurrencyEUR Currency = "EUR"
+
+	// DefaultCurrency — валюта сумм, для которых валюта не указана.
+	DefaultCurrency = CurrencyRUB
+)
+
+// Число знаков после запятой у поддерживаемых валют. В БД суммы хранятся
+// как NUMERIC(10, 2), поэтому больше двух знаков быть не может.
+var currencyDigits = map[Currency]int{
+	CurrencyRUB: 2,
+	CurrencyUSD: 2,
+	CurrencyEUR: 2,
+}
+
+// Digits — число знаков после запятой в сумме в этой валюте.
+func (c Currency) Digits() int {
+	return currencyDigits[c]
+}
+
+func (c Currency) Valid() bool {
+	_, ok := currencyDigits[c]
+	return ok
+}
+
+var (
+	ErrInvalidMoney    = NewError(CodeInvalidMoney, "неверная сумма")
+	ErrUnknownCurrency = NewError(CodeUnknownCurrency, "неизвестная валюта")
+)
+
+// Money — сумма в минимальных единицах валюты (копейках для рубля). Суммы
+// не переводятся во float, поэтому итоги и отчеты считаются без ошибок
+// округления.
+type Money struct {
+	MinorUnits int64
+	Currency   Currency
+}
+
+// NewMoney проверяет валюту и знак суммы. Пустая валюта — DefaultCurrency.
+func NewMoney(minorUnits int64, currency Currency) (Money, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !currency.Valid() {
+		return Money{}, ErrUnknownCurrency.With("currency", string(currency))
+	}
+	if minorUnits < 0 {
+		return Money{}, ErrInvalidMoney.With("amount", strconv.FormatInt(minorUnits, 10))
+	}
+	return Money{MinorUnits: minorUnits, Currency: currency}, nil
+}
+
+// Rubles — сумма в целых рублях.
+func Rubles(rubles int64) Money {
+	return Money{MinorUnits: rubles * 100, Currency: CurrencyRUB}
+}
+
+// ParseMoney разбирает десятичную запись суммы ("1000", "99.9", "99.90").
+// Знаков после точки не может быть больше, чем у валюты.
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if !currency.Valid() {
+		return Money{}, ErrUnknownCurrency.With("currency", string(currency))
+	}
+
+	invalid := ErrInvalidMoney.With("amount", amount)
+	whole, fraction, _ := strings.Cut(amount, ".")
+	digits := currency.Digits()
+	if whole == "" || len(fraction) > digits || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, invalid
+	}
+
+	minorUnits, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", digits-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, invalid
+	}
+	return Money{MinorUnits: minorUnits, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Money) IsZero() bool {
+	return m.MinorUnits == 0
+}
+
+// Amount — десятичная запись суммы без валюты, например "99.90".
+func (m Money) Amount() string {
+	digits := m.Currency.Digits()
+	units := strconv.FormatInt(m.MinorUnits, 10)
+	if digits == 0 {
+		return units
+	}
+	if len(units) <= digits {
+		units = strings.Repeat("0", digits-len(units)+1) + units
+	}
+	return units[:len(units)-digits] + "." + units[len(units)-digits:]
+}
+
+func (m Money) String() string {
+	return m.Amount() + " " + string(m.Currency)
+}
+
+// Float64 — приближенное значение суммы в основных единицах. Только для
+// метрик и устаревших полей API, считать им нельзя.
+func (m Money) Float64() float64 {
+	value, _ := strconv.ParseFloat(m.Amount(), 64)
+	return value
+}
+
+type moneyJSON struct {
+	MinorUnits int64    `json:"minor_units"`
+	Currency   Currency `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{MinorUnits: m.MinorUnits, Currency: m.Currency})
+}
+
+// UnmarshalJSON принимает объект {"minor_units": 9990, "currency": "RUB"}, а
+// также число или строку в основных единицах валюты по умолчанию (99.9) —
+// так цены передавались раньше.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		var raw moneyJSON
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		money, err := NewMoney(raw.MinorUnits, raw.Currency)
+		if err != nil {
+			return err
+		}
+		*m = money
+		return nil
+	}
+
+	amount := string(data)
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return err
+		}
+	}
+	money, err := ParseMoney(amount, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
@@ -1,7 +1,6 @@
 package domain
 
 import (
-	"errors"
 	"fmt"
 	"time"
 )
@@ -12,6 +11,8 @@ const (
 	StatusStored   OrderStatus = "stored"
 	StatusIssued   OrderStatus = "issued"
 	StatusRefunded OrderStatus = "refunded"
+
+	StatusReturnedToCourier OrderStatus = "returned_to_courier"
 )
 
 type Order struct {
@@ -21,8 +22,9 @@ type Order struct {
 	StoredAt     *time.Time    `json:"stored_at"`
 	IssuedAt     *time.Time    `json:"issued_at"`
 	RefundedAt   *time.Time    `json:"refunded_at"`
-	BasePrice    float64       `json:"base_price"`
-	PackagePrice float64       `json:"package_price"`
+	State        OrderStatus   `json:"status,omitempty"`
+	BasePrice    Money         `json:"base_price"`
+	PackagePrice Money         `json:"package_price"`
 	Weight       float64       `json:"weight"`
 	Packaging    PackagingType `json:"packaging"`
 }
@@ -32,26 +34,29 @@ type ProcessedOrders struct {
 	OrderIDs []string
 	Failed   []string
 	Error    error
+	Results  []OrderResult
 }
 
 var (
-	ErrExpiredOrder    = errors.New("срок хранения заказа уже прошел")
-	ErrDuplicateOrder  = errors.New("заказ с таким ID уже есть")
-	ErrNotExpiredOrder = errors.New("у этого заказа еще не истек срок хранения")
-
-	ErrNotFoundOrder       = errors.New("заказа с таким ID не существует")
-	ErrNullOrderIDs        = errors.New("список id заказов пуст")
-	ErrNotStoredOrder      = errors.New("заказа нет на складе")
-	ErrNotIssuedOrder      = errors.New("заказ ещё не был выдан")
-	ErrUserNoOrders        = errors.New("введенные заказы не готовы к выдаче или возврату")
-	ErrUserNoActiveOrders  = errors.New("у пользователя нет активных заказов")
-	ErrRefundPeriodExpired = errors.New("прошло 48 суток с момента выдачи заказа")
-
-	ErrInvalidWeight = errors.New("слишком большой вес для этой упаковки")
-
-	ErrWrongJSON = errors.New("тело запроса содержит ошибки")
-	ErrDatabase  = errors.New("ошибка базы данных")
-	ErrCache     = errors.New("ошибка кэша")
+	ErrExpiredOrder    = NewError(CodeOrderExpired, "срок хранения заказа уже прошел")
+	ErrDuplicateOrder  = NewError(CodeOrderAlreadyExists, "заказ с таким ID уже есть")
+	ErrNotExpiredOrder = NewError(CodeOrderNotExpired, "у этого заказа еще не истек срок хранения")
+
+	ErrNotFoundOrder       = NewError(CodeOrderNotFound, "заказа с таким ID не существует")
+	ErrNullOrderIDs        = NewError(CodeOrderIDsEmpty, "список id заказов пуст")
+	ErrNotStoredOrder      = NewError(CodeOrderNotStored, "заказа нет на складе")
+	ErrNotIssuedOrder      = NewError(CodeOrderNotIssued, "заказ ещё не был выдан")
+	ErrUserNoOrders        = NewError(CodeOrdersNotReady, "введенные заказы не готовы к выдаче или возврату")
+	ErrUserNoActiveOrders  = NewError(CodeUserNoActiveOrders, "у пользователя нет активных заказов")
+	ErrRefundPeriodExpired = NewError(CodeOrderRefundPeriodExpired, "прошло 48 суток с момента выдачи заказа")
+
+	ErrInvalidWeight        = NewError(CodeInvalidWeight, "слишком большой вес для этой упаковки")
+	ErrUnknownPackaging     = NewError(CodeUnknownPackaging, "неизвестный тип упаковки")
+	ErrPackagingCombination = NewError(CodePackagingCombination, "нельзя комбинировать основные типы упаковки")
+
+	ErrWrongJSON = NewError(CodeInvalidRequest, "тело запроса содержит ошибки")
+	ErrDatabase  = NewError(CodeDatabase, "ошибка базы данных")
+	ErrCache     = NewError(CodeCache, "ошибка кэша")
 )
 
 type ErrUserDoesntOwnOrder struct {
@@ -63,7 +68,19 @@ func (e *ErrUserDoesntOwnOrder) Error() string {
 	return fmt.Sprintf("Заказ %s не принадлежит пользователю %s", e.OrderID, e.UserID)
 }
 
+func (e *ErrUserDoesntOwnOrder) Code() ErrorCode {
+	return CodeOrderNotOwned
+}
+
+func (e *ErrUserDoesntOwnOrder) Params() map[string]string {
+	return map[string]string{"order_id": e.OrderID, "user_id": e.UserID}
+}
+
 func (o Order) Status() OrderStatus {
+	if o.State != "" {
+		return o.State
+	}
+
 	var latestTime time.Time
 	var status OrderStatus
 
@@ -0,0 +1,26 @@
+package domain
+
+import "github.com/google/uuid"
+
+// OrderEventsTopic — топик Kafka с доменными событиями заказов.
+const OrderEventsTopic = "order_events"
+
+// OrderEvent — доменное событие заказа для других сервисов: переход статуса
+// и заказ в том состоянии, в которое он перешел.
+type OrderEvent struct {
+	ID     string
+	Order  Order
+	Change OrderStatusChange
+}
+
+// OrderEventEncoder кодирует событие заказа для записи в outbox. Слой
+// хранения получает его снаружи и не зависит от схемы событий.
+type OrderEventEncoder func(OrderEvent) ([]byte, error)
+
+func NewOrderEvent(order Order, change OrderStatusChange) OrderEvent {
+	return OrderEvent{
+		ID:     uuid.NewString(),
+		Order:  order,
+		Change: change,
+	}
+}
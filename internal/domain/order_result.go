@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// OrderResultStatus — итог обработки одного заказа при выдаче или возврате.
+type OrderResultStatus string
+
+const (
+	OrderResultProcessed OrderResultStatus = "processed"
+	OrderResultFailed    OrderResultStatus = "failed"
+	// Заказ прошел проверки, но в режиме atomic изменения отменены из-за
+	// ошибки в другом заказе.
+	OrderResultRolledBack OrderResultStatus = "rolled_back"
+)
+
+// OrderFailureReason — машиночитаемая причина, по которой заказ не обработан.
+type OrderFailureReason string
+
+const (
+	ReasonNotFound            OrderFailureReason = "not_found"
+	ReasonNotOwner            OrderFailureReason = "not_owner"
+	ReasonNotStored           OrderFailureReason = "not_stored"
+	ReasonNotIssued           OrderFailureReason = "not_issued"
+	ReasonExpired             OrderFailureReason = "expired"
+	ReasonRefundPeriodExpired OrderFailureReason = "refund_period_expired"
+	ReasonInvalidTransition   OrderFailureReason = "invalid_transition"
+	ReasonBatchRolledBack     OrderFailureReason = "batch_rolled_back"
+	ReasonUnknown             OrderFailureReason = "unknown"
+)
+
+var ErrBatchRolledBack = NewError(CodeOrderBatchRolledBack, "изменения отменены из-за ошибки в другом заказе")
+
+// OrderResult — итог обработки заказа. Reason — код ошибки, Message — ее
+// текст для оператора, ChangedAt — время перехода в новый статус
+// (только для processed).
+type OrderResult struct {
+	OrderID   string             `json:"order_id"`
+	Status    OrderResultStatus  `json:"status"`
+	Reason    OrderFailureReason `json:"reason,omitempty"`
+	Message   string             `json:"message,omitempty"`
+	ChangedAt *time.Time         `json:"changed_at,omitempty"`
+	// Err — ошибка, из которой получены Reason и Message
+	Err error `json:"-"`
+}
+
+// FailureReasonOf возвращает код для ошибки обработки заказа.
+func FailureReasonOf(err error) OrderFailureReason {
+	var notOwner *ErrUserDoesntOwnOrder
+	var transition *ErrInvalidTransition
+
+	switch {
+	case errors.Is(err, ErrNotFoundOrder):
+		return ReasonNotFound
+	case errors.As(err, &notOwner):
+		return ReasonNotOwner
+	case errors.Is(err, ErrNotStoredOrder):
+		return ReasonNotStored
+	case errors.Is(err, ErrNotIssuedOrder):
+		return ReasonNotIssued
+	case errors.Is(err, ErrExpiredOrder):
+		return ReasonExpired
+	case errors.Is(err, ErrRefundPeriodExpired):
+		return ReasonRefundPeriodExpired
+	case errors.As(err, &transition):
+		return ReasonInvalidTransition
+	case errors.Is(err, ErrBatchRolledBack):
+		return ReasonBatchRolledBack
+	}
+	return ReasonUnknown
+}
+
+// UniqueOrderIDs убирает повторы ID заказов, сохраняя порядок первых
+// вхождений. Повтор заказа в пачке иначе не прошел бы проверку статуса:
+// заказ уже переведен своим первым вхождением.
+func UniqueOrderIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// OrderBatch собирает итоги обработки пачки заказов в порядке запроса. Без
+// atomic заказы обрабатываются независимо: ошибка одного не мешает
+// остальным. С atomic после первой ошибки заказы только проверяются, а
+// обработанные ранее отменяются.
+type OrderBatch struct {
+	atomic    bool
+	failed    bool
+	err       error
+	processed []string
+	results   []OrderResult
+}
+
+func NewOrderBatch(atomic bool, size int) *OrderBatch {
+	return &OrderBatch{
+		atomic:    atomic,
+		processed: make([]string, 0, size),
+		results:   make([]OrderResult, 0, size),
+	}
+}
+
+// Fail записывает ошибку заказа. В Error итога остается первая ошибка:
+// следующие есть в результатах по заказам.
+func (b *OrderBatch) Fail(id string, err error) {
+	if b.err == nil {
+		b.err = err
+	}
+	b.failed = true
+	b.results = append(b.results, newOrderResult(id, OrderResultFailed, err))
+}
+
+// CanApply сообщает, нужно ли переводить в новый статус заказ, прошедший
+// проверки. Если нет, заказ нужно отметить через Skip.
+func (b *OrderBatch) CanApply() bool {
+	return !b.atomic || !b.failed
+}
+
+// Skip отмечает заказ, прошедший проверки, изменения которого отменены
+// из-за ошибки в другом заказе.
+func (b *OrderBatch) Skip(id string) {
+	b.results = append(b.results, newOrderResult(id, OrderResultRolledBack, ErrBatchRolledBack))
+}
+
+// Processed отмечает заказ, переведенный в новый статус в changedAt.
+func (b *OrderBatch) Processed(id string, changedAt time.Time) {
+	b.processed = append(b.processed, id)
+	b.results = append(b.results, OrderResult{
+		OrderID:   id,
+		Status:    OrderResultProcessed,
+		ChangedAt: &changedAt,
+	})
+}
+
+// RolledBack сообщает, что изменения пачки нельзя сохранять: в режиме
+// atomic один из заказов не прошел.
+func (b *OrderBatch) RolledBack() bool {
+	return b.atomic && b.failed
+}
+
+// Result возвращает итог пачки заказов orderIDs пользователя userID.
+func (b *OrderBatch) Result(userID string, orderIDs []string) ProcessedOrders {
+	if b.RolledBack() {
+		for i := range b.results {
+			if b.results[i].Status == OrderResultProcessed {
+				b.results[i] = newOrderResult(b.results[i].OrderID, OrderResultRolledBack, ErrBatchRolledBack)
+			}
+		}
+		return ProcessedOrders{
+			UserID:   userID,
+			OrderIDs: []string{},
+			Failed:   orderIDs,
+			Error:    b.err,
+			Results:  b.results,
+		}
+	}
+
+	processed := make(map[string]struct{}, len(b.processed))
+	for _, id := range b.processed {
+		processed[id] = struct{}{}
+	}
+	var failed []string
+	for _, id := range orderIDs {
+		if _, ok := processed[id]; !ok {
+			failed = append(failed, id)
+		}
+	}
+
+	return ProcessedOrders{
+		UserID:   userID,
+		OrderIDs: b.processed,
+		Failed:   failed,
+		Error:    b.err,
+		Results:  b.results,
+	}
+}
+
+func newOrderResult(id string, status OrderResultStatus, err error) OrderResult {
+	return OrderResult{
+		OrderID: id,
+		Status:  status,
+		Reason:  FailureReasonOf(err),
+		Message: err.Error(),
+		Err:     err,
+	}
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Переходы, разрешенные конечным автоматом заказа.
+// Пустой статус означает, что заказа еще нет на складе.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	"":           {StatusStored},
+	StatusStored: {StatusIssued, StatusReturnedToCourier},
+	StatusIssued: {StatusRefunded},
+}
+
+type OrderStatusChange struct {
+	OrderID   string      `json:"order_id"`
+	From      OrderStatus `json:"from_status,omitempty"`
+	To        OrderStatus `json:"to_status"`
+	Actor     string      `json:"actor,omitempty"`
+	ChangedAt time.Time   `json:"changed_at"`
+}
+
+type ErrInvalidTransition struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+func (e *ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("нельзя перевести заказ из статуса %q в статус %q", e.From, e.To)
+}
+
+func (e *ErrInvalidTransition) Code() ErrorCode {
+	return CodeOrderInvalidTransition
+}
+
+func (e *ErrInvalidTransition) Params() map[string]string {
+	return map[string]string{"from": string(e.From), "to": string(e.To)}
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo переводит заказ в следующий статус, проставляя время перехода,
+// и возвращает запись для истории статусов.
+func (o *Order) TransitionTo(ctx context.Context, next OrderStatus, at time.Time) (OrderStatusChange, error) {
+	current := o.Status()
+	if !current.CanTransitionTo(next) {
+		return OrderStatusChange{}, &ErrInvalidTransition{From: current, To: next}
+	}
+
+	switch next {
+	case StatusStored:
+		o.StoredAt = &at
+	case StatusIssued:
+		o.IssuedAt = &at
+	case StatusRefunded:
+		o.RefundedAt = &at
+	}
+	o.State = next
+
+	return OrderStatusChange{
+		OrderID:   o.ID,
+		From:      current,
+		To:        next,
+		Actor:     ActorFromContext(ctx),
+		ChangedAt: at,
+	}, nil
+}
+
+type actorKey struct{}
+
+// ActorReturnJob — исполнитель автоматического возврата просроченных заказов курьеру.
+const ActorReturnJob = "system:return_job"
+
+// ContextWithActor сохраняет в контексте email пользователя, выполняющего действие.
+func ContextWithActor(ctx context.Context, email string) context.Context {
+	return context.WithValue(ctx, actorKey{}, email)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -9,7 +9,7 @@ const (
 )
 
 type PackagingStrategy interface {
-	CalculatePrice() float64
+	CalculatePrice() Money
 	CheckWeight(baseWeight float64) bool
 	IsMain() bool
 }
@@ -17,21 +17,21 @@ type PackagingStrategy interface {
 // Пакет (5 руб, вес <10 кг)
 type PackagingPackage struct{}
 
-func (p PackagingPackage) CalculatePrice() float64         { return 5 }
+func (p PackagingPackage) CalculatePrice() Money           { return Rubles(5) }
 func (p PackagingPackage) CheckWeight(weight float64) bool { return weight < 10 }
 func (p PackagingPackage) IsMain() bool                    { return true }
 
 // Коробка (20 руб, вес <30 кг)
 type PackagingBox struct{}
 
-func (p PackagingBox) CalculatePrice() float64         { return 20 }
+func (p PackagingBox) CalculatePrice() Money           { return Rubles(20) }
 func (p PackagingBox) CheckWeight(weight float64) bool { return weight < 30 }
 func (p PackagingBox) IsMain() bool                    { return true }
 
 // Пленка (1 руб, без проверок)
 type PackagingFilm struct{}
 
-func (p PackagingFilm) CalculatePrice() float64         { return 1 }
+func (p PackagingFilm) CalculatePrice() Money           { return Rubles(1) }
 func (p PackagingFilm) CheckWeight(weight float64) bool { return true }
 func (p PackagingFilm) IsMain() bool                    { return false }
 
@@ -39,10 +39,11 @@ type CompositePackaging struct {
 	Strategies []PackagingStrategy
 }
 
-func (c CompositePackaging) CalculatePrice() float64 {
-	res := 0.0
+// Цены всех упаковок в рублях, поэтому складываются без проверки валюты.
+func (c CompositePackaging) CalculatePrice() Money {
+	res := Rubles(0)
 	for _, s := range c.Strategies {
-		res += s.CalculatePrice()
+		res.MinorUnits += s.CalculatePrice().MinorUnits
 	}
 	return res
 }
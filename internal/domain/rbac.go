@@ -0,0 +1,58 @@
+package domain
+
+type Role string
+
+const (
+	RoleGuest    Role = "guest"
+	RoleOperator Role = "operator"
+	RoleManager  Role = "manager"
+	RoleAdmin    Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionAcceptOrder Permission = "orders:accept"
+	PermissionReturnOrder Permission = "orders:return"
+	PermissionIssueRefund Permission = "orders:issue_refund"
+	PermissionViewOrder   Permission = "orders:view"
+	PermissionViewReports Permission = "reports:view"
+	PermissionManageUsers Permission = "users:manage"
+	PermissionViewAudit   Permission = "audit:view"
+	PermissionManageAudit Permission = "audit:manage"
+)
+
+var operatorPermissions = []Permission{
+	PermissionAcceptOrder,
+	PermissionReturnOrder,
+	PermissionIssueRefund,
+	PermissionViewOrder,
+}
+
+// Гость (роль по умолчанию после регистрации) не имеет никаких прав,
+// пока администратор не назначит ему роль.
+var rolePermissions = map[Role][]Permission{
+	RoleGuest:    {},
+	RoleOperator: operatorPermissions,
+	RoleManager:  append([]Permission{PermissionViewReports, PermissionViewAudit}, operatorPermissions...),
+	RoleAdmin: append([]Permission{
+		PermissionViewReports,
+		PermissionViewAudit,
+		PermissionManageAudit,
+		PermissionManageUsers,
+	}, operatorPermissions...),
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) HasPermission(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
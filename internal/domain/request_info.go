@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// MaxRequestIDLength — максимальная длина request ID, переданного клиентом.
+const MaxRequestIDLength = 128
+
+// RequestInfo описывает входящий запрос, в рамках которого произошло событие.
+type RequestInfo struct {
+	RequestID string
+	TraceID   string
+	ClientIP  string
+	UserAgent string
+}
+
+type requestInfoKey struct{}
+
+func ContextWithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// RequestIDOrNew возвращает request ID клиента, если он не длиннее
+// MaxRequestIDLength и состоит только из [A-Za-z0-9-_]. Иначе генерируется
+// новый: request ID попадает в журнал аудита и заголовки ответа.
+func RequestIDOrNew(requestID string) string {
+	if requestID == "" || len(requestID) > MaxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, r := range requestID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return uuid.NewString()
+		}
+	}
+	return requestID
+}
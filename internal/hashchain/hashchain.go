@@ -0,0 +1,45 @@
+package hashchain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"time"
+)
+
+// Chain считает хеши звеньев цепочки. Каждое звено зависит от хеша
+// предыдущего, поэтому изменение или удаление записи ломает все звенья
+// после нее. С ключом используется HMAC-SHA256, без ключа — SHA-256.
+type Chain struct {
+	key []byte
+}
+
+func New(key string) *Chain {
+	if key == "" {
+		return &Chain{}
+	}
+	return &Chain{key: []byte(key)}
+}
+
+// Link возвращает хеш записи с идентификатором id, временем создания
+// createdAt и содержимым data, следующей за звеном с хешем prev. Для первой
+// записи prev пустой.
+func (c *Chain) Link(prev []byte, id int, createdAt time.Time, data []byte) []byte {
+	var h hash.Hash
+	if c.key != nil {
+		h = hmac.New(sha256.New, c.key)
+	} else {
+		h = sha256.New()
+	}
+
+	var buf [8]byte
+	h.Write(prev)
+	binary.BigEndian.PutUint64(buf[:], uint64(id))
+	h.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(createdAt.UnixMicro()))
+	h.Write(buf[:])
+	h.Write(data)
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,168 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultKeyID = "default"
+
+// MinSecretLength — минимальная длина секрета HS256 в байтах: секрет короче
+// выхода SHA-256 ослабляет подпись.
+const MinSecretLength = 32
+
+var (
+	ErrNoSigningKey      = errors.New("не задан ключ подписи JWT")
+	ErrUnknownKeyID      = errors.New("неизвестный kid токена")
+	ErrAlgorithmMismatch = errors.New("алгоритм токена не совпадает с алгоритмом ключа")
+	ErrShortSecret       = fmt.Errorf("секрет HS256 короче %d байт", MinSecretLength)
+	ErrMalformedPEM      = errors.New("файл похож на PEM, но не разбирается")
+)
+
+type key struct {
+	method  jwt.SigningMethod
+	private any
+	public  any
+}
+
+// KeySet хранит ключ, которым подписываются новые токены, и все ключи,
+// которыми можно проверить ранее выданные токены. Для ротации новый ключ
+// добавляется в набор и назначается ключом подписи, а старый остается
+// в наборе, пока не истекут выданные им токены.
+type KeySet struct {
+	signingKID string
+	keys       map[string]key
+}
+
+// Load читает ключи из файлов (kid -> путь). Файл может содержать приватный
+// или публичный ключ RSA/Ed25519 в PEM, либо секрет для HS256 в виде текста.
+// Если файлов нет, используется secret с kid "default".
+func Load(files map[string]string, signingKID, secret string) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]key)}
+
+	for kid, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось прочитать ключ %s: %w", kid, err)
+		}
+		k, err := parseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось разобрать ключ %s: %w", kid, err)
+		}
+		ks.keys[kid] = k
+	}
+
+	if len(ks.keys) == 0 && secret != "" {
+		k, err := hmacKey([]byte(secret))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_SECRET: %w", err)
+		}
+		ks.keys[defaultKeyID] = k
+		if signingKID == "" {
+			signingKID = defaultKeyID
+		}
+	}
+
+	signing, ok := ks.keys[signingKID]
+	if !ok || signing.private == nil {
+		return nil, ErrNoSigningKey
+	}
+	ks.signingKID = signingKID
+
+	return ks, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	k := ks.keys[ks.signingKID]
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = ks.signingKID
+	return token.SignedString(k.private)
+}
+
+// Keyfunc выбирает ключ проверки по заголовку kid и не дает подменить алгоритм.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, ErrAlgorithmMismatch
+	}
+	return k.public, nil
+}
+
+func parseKey(data []byte) (key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		// Обрезанный или поврежденный PEM нельзя молча принять за секрет HS256
+		if strings.Contains(string(data), "-----BEGIN") {
+			return key{}, ErrMalformedPEM
+		}
+		return hmacKey([]byte(strings.TrimSpace(string(data))))
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return key{}, err
+		}
+		return asymmetricKey(private)
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return key{}, err
+		}
+		return asymmetricKey(private)
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return key{}, err
+		}
+		return publicKey(public)
+	default:
+		return key{}, fmt.Errorf("неподдерживаемый тип PEM блока %q", block.Type)
+	}
+}
+
+func hmacKey(secret []byte) (key, error) {
+	if len(secret) < MinSecretLength {
+		return key{}, ErrShortSecret
+	}
+	return key{method: jwt.SigningMethodHS256, private: secret, public: secret}, nil
+}
+
+func asymmetricKey(private any) (key, error) {
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return key{}, errors.New("ключ не поддерживает подпись")
+	}
+	k, err := publicKey(signer.Public())
+	if err != nil {
+		return key{}, err
+	}
+	k.private = private
+	return k, nil
+}
+
+func publicKey(public any) (key, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return key{method: jwt.SigningMethodRS256, public: public}, nil
+	case ed25519.PublicKey:
+		return key{method: jwt.SigningMethodEdDSA, public: public}, nil
+	default:
+		return key{}, fmt.Errorf("неподдерживаемый тип ключа %T", public)
+	}
+}
@@ -0,0 +1,263 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// Client — часть kgo.Client, которой пользуется Consumer. В тестах ее
+// заменяет заглушка, работающая без брокера.
+type Client interface {
+	PollFetches(ctx context.Context) kgo.Fetches
+	MarkCommitRecords(rs ...*kgo.Record)
+	CommitUncommittedOffsets(ctx context.Context) error
+	Close()
+}
+
+// BatchHandler обрабатывает записи одной партиции из одного опроса. Ошибка
+// означает, что пачку нужно обработать повторно, поэтому обработчик должен
+// быть идемпотентным.
+type BatchHandler func(ctx context.Context, records []*kgo.Record) error
+
+// NewGroupClient создает клиента группы консьюмеров, который читает только
+// закоммиченные транзакции и коммитит смещения вручную. Новая группа
+// начинает с конца топика; extra переопределяет эти настройки.
+func NewGroupClient(brokers []string, groupID, topic string, logger *zap.SugaredLogger, extra ...kgo.Opt) (*kgo.Client, error) {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(brokers...),
+		kgo.ConsumerGroup(groupID),
+		kgo.ConsumeTopics(topic),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+		kgo.DisableAutoCommit(),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtEnd()),
+		kgo.SessionTimeout(30 * time.Second),
+		kgo.HeartbeatInterval(5 * time.Second),
+		kgo.OnPartitionsRevoked(func(ctx context.Context, client *kgo.Client, revoked map[string][]int32) {
+			if err := client.CommitUncommittedOffsets(ctx); err != nil {
+				logger.Errorw("failed to commit offsets during rebalance", "error", err)
+			}
+		}),
+		kgo.OnPartitionsAssigned(func(ctx context.Context, client *kgo.Client, assigned map[string][]int32) {
+			logger.Infow("partitions assigned", "partitions", assigned)
+		}),
+	}
+	opts = append(opts, extra...)
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init Kafka Client: %w", err)
+	}
+	return client, nil
+}
+
+// PermanentError — ошибка, которую повтор не исправит (например, запись не
+// разбирается). Такая запись сразу уходит в топик недоставленных; остальные
+// ошибки повторяются, пока не пройдут.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// Retry задает задержку перед попыткой обработки записи с номером attempt.
+// После AlertAfter неудачных попыток каждая ошибка пишется в лог как ошибка:
+// партиция стоит. После MaxAttempts попыток запись уходит в DeadLetter;
+// 0 — повторять, пока не пройдет.
+type Retry struct {
+	AlertAfter  int
+	MaxAttempts int
+	Delay       func(attempt int) time.Duration
+}
+
+// DeadLetter принимает записи, которые не удалось обработать.
+type DeadLetter interface {
+	Publish(ctx context.Context, record *kgo.Record, cause error, attempts int) error
+}
+
+// Consumer читает записи и передает их обработчику пачками по партициям.
+// Если пачка не прошла, записи обрабатываются по одной: временная ошибка
+// повторяется с задержкой до Retry.MaxAttempts раз, и новые записи до этого
+// не читаются; запись с постоянной ошибкой или исчерпавшая попытки уходит в
+// DeadLetter. Смещение
+// коммитится только после того, как каждая запись пачки обработана или
+// отправлена в DeadLetter.
+type Consumer struct {
+	client     Client
+	handler    BatchHandler
+	retry      Retry
+	deadLetter DeadLetter
+	logger     *zap.SugaredLogger
+}
+
+func NewConsumer(client Client, handler BatchHandler, retry Retry, deadLetter DeadLetter, logger *zap.SugaredLogger) *Consumer {
+	return &Consumer{
+		client:     client,
+		handler:    handler,
+		retry:      retry,
+		deadLetter: deadLetter,
+		logger:     logger,
+	}
+}
+
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		fetches := c.client.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if fetches.IsClientClosed() {
+			return fmt.Errorf("client is closed")
+		}
+
+		for _, err := range fetches.Errors() {
+			if errors.Is(err.Err, context.Canceled) {
+				continue
+			}
+			metrics.IncKafkaErrors()
+			c.logger.Errorw("fetch error",
+				"topic", err.Topic,
+				"partition", err.Partition,
+				"error", err.Err,
+			)
+		}
+
+		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+			if len(p.Records) == 0 || ctx.Err() != nil {
+				return
+			}
+			if !c.handleBatch(ctx, p.Records) {
+				return
+			}
+
+			c.client.MarkCommitRecords(p.Records...)
+			last := p.Records[len(p.Records)-1]
+			metrics.SetAuditConsumerLag(p.Topic, p.Partition, p.HighWatermark-last.Offset-1)
+		})
+
+		if err := c.client.CommitUncommittedOffsets(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Errorw("failed to commit offsets", "error", err)
+		}
+	}
+}
+
+// handleBatch возвращает false, если ctx закончился раньше, чем все записи
+// пачки были обработаны.
+func (c *Consumer) handleBatch(ctx context.Context, records []*kgo.Record) bool {
+	metrics.IncKafkaMessages(len(records))
+
+	start := time.Now()
+	err := c.handler(ctx, records)
+	metrics.ObserveAuditConsumerBatch(time.Since(start))
+	if err == nil {
+		return true
+	}
+
+	metrics.IncKafkaErrors()
+	c.logger.Warnw("batch failed, processing records one by one",
+		"topic", records[0].Topic,
+		"partition", records[0].Partition,
+		"offset", records[0].Offset,
+		"count", len(records),
+		"error", err,
+	)
+
+	for _, record := range records {
+		if !c.handleRecord(ctx, record) {
+			return false
+		}
+	}
+	return true
+}
+
+// handleRecord повторяет запись, пока она не обработается, не вернет
+// постоянную ошибку, не исчерпает Retry.MaxAttempts или не закончится ctx.
+// Ошибка, которая повторяется на каждой попытке (например, нарушение
+// ограничения в базе), иначе остановила бы партицию навсегда.
+func (c *Consumer) handleRecord(ctx context.Context, record *kgo.Record) bool {
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		err = c.handler(ctx, []*kgo.Record{record})
+		if err == nil {
+			return true
+		}
+		if IsPermanent(err) {
+			break
+		}
+
+		metrics.IncKafkaErrors()
+		if c.retry.MaxAttempts > 0 && attempt >= c.retry.MaxAttempts {
+			break
+		}
+		log := c.logger.Warnw
+		if attempt >= c.retry.AlertAfter {
+			log = c.logger.Errorw
+		}
+		log("failed to process record, retrying",
+			"topic", record.Topic,
+			"partition", record.Partition,
+			"offset", record.Offset,
+			"attempt", attempt,
+			"error", err,
+		)
+		if !sleep(ctx, c.retry.Delay(attempt)) {
+			return false
+		}
+	}
+
+	c.logger.Errorw("moving record to dead letter",
+		"topic", record.Topic,
+		"partition", record.Partition,
+		"offset", record.Offset,
+		"attempts", attempt,
+		"error", err,
+	)
+
+	// Запись нельзя потерять, поэтому отправка в DeadLetter повторяется,
+	// пока не пройдет или не закончится ctx
+	for retry := 1; ; retry++ {
+		publishErr := c.deadLetter.Publish(ctx, record, err, attempt)
+		if publishErr == nil {
+			metrics.AddAuditConsumerEvents("dead_letter", 1)
+			return true
+		}
+
+		c.logger.Errorw("failed to publish record to dead letter", "offset", record.Offset, "error", publishErr)
+		if !sleep(ctx, c.retry.Delay(retry)) {
+			return false
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
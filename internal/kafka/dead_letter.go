@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Заголовки, которые DeadLetterWriter добавляет к записи.
+const (
+	HeaderDLQError     = "dlq-error"
+	HeaderDLQTopic     = "dlq-original-topic"
+	HeaderDLQPartition = "dlq-original-partition"
+	HeaderDLQOffset    = "dlq-original-offset"
+	HeaderDLQAttempts  = "dlq-attempts"
+	HeaderDLQFailedAt  = "dlq-failed-at"
+
+	dlqHeaderPrefix = "dlq-"
+)
+
+// DeadLetterWriter пишет недоставленные записи в отдельный топик с тем же
+// ключом и значением, добавляя заголовки с причиной и исходным смещением.
+type DeadLetterWriter struct {
+	writer *Writer
+}
+
+func NewDeadLetterWriter(writer *Writer) *DeadLetterWriter {
+	return &DeadLetterWriter{writer: writer}
+}
+
+func (d *DeadLetterWriter) Publish(ctx context.Context, record *kgo.Record, cause error, attempts int) error {
+	headers := append(StripDeadLetterHeaders(record.Headers),
+		kgo.RecordHeader{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kgo.RecordHeader{Key: HeaderDLQTopic, Value: []byte(record.Topic)},
+		kgo.RecordHeader{Key: HeaderDLQPartition, Value: []byte(strconv.Itoa(int(record.Partition)))},
+		kgo.RecordHeader{Key: HeaderDLQOffset, Value: []byte(strconv.FormatInt(record.Offset, 10))},
+		kgo.RecordHeader{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kgo.RecordHeader{Key: HeaderDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	return d.writer.WriteRecord(ctx, &kgo.Record{
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	})
+}
+
+// StripDeadLetterHeaders возвращает заголовки записи без служебных
+// заголовков DLQ.
+func StripDeadLetterHeaders(headers []kgo.RecordHeader) []kgo.RecordHeader {
+	result := make([]kgo.RecordHeader, 0, len(headers))
+	for _, h := range headers {
+		if !strings.HasPrefix(h.Key, dlqHeaderPrefix) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// RecordHeader возвращает значение заголовка key или пустую строку.
+func RecordHeader(record *kgo.Record, key string) string {
+	for _, h := range record.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -2,7 +2,6 @@ package kafka
 
 import (
 	"context"
-	"strconv"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kgo"
@@ -14,11 +13,14 @@ type Producer struct {
 	logger *zap.SugaredLogger
 }
 
-func NewProducer(brokers []string, logger *zap.SugaredLogger) (*Producer, error) {
+// NewProducer создает транзакционного продюсера. Один продюсер не может вести
+// несколько транзакций одновременно, а продюсеры с одинаковым transactionalID
+// вытесняют друг друга, поэтому каждому обработчику нужен свой ID.
+func NewProducer(brokers []string, transactionalID string, logger *zap.SugaredLogger) (*Producer, error) {
 
 	opts := []kgo.Opt{
 		kgo.SeedBrokers(brokers...),
-		kgo.TransactionalID("audit-producer-v1"),
+		kgo.TransactionalID(transactionalID),
 		kgo.AllowAutoTopicCreation(),
 		kgo.ProduceRequestTimeout(3 * time.Second),
 		kgo.ProducerBatchMaxBytes(10 << 20),
@@ -36,34 +38,55 @@ func NewProducer(brokers []string, logger *zap.SugaredLogger) (*Producer, error)
 	}, nil
 }
 
-func (p *Producer) SendTransactional(
-	ctx context.Context,
-	taskID int,
-	payload []byte,
-) error {
+func (p *Producer) SendTransactional(ctx context.Context, message Message) error {
+	return p.SendTransactionalBatch(ctx, []Message{message})
+}
+
+// Message — запись для отправки. Пустой Topic означает топик аудита.
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
 
+const auditTopic = "audit_logs"
+
+// SendTransactionalBatch отправляет все сообщения в одной транзакции:
+// либо они все будут видны читателям с read_committed, либо ни одно.
+func (p *Producer) SendTransactionalBatch(ctx context.Context, messages []Message) error {
 	if err := p.client.BeginTransaction(); err != nil {
 		p.logger.Errorw("producer failed to start a transaction", "error", err)
 		return err
 	}
 
-	record := &kgo.Record{
-		Topic: "audit_logs",
-		Key:   []byte(strconv.Itoa(taskID)),
-		Value: payload,
+	records := make([]*kgo.Record, 0, len(messages))
+	for _, m := range messages {
+		topic := m.Topic
+		if topic == "" {
+			topic = auditTopic
+		}
+		records = append(records, &kgo.Record{
+			Topic: topic,
+			Key:   m.Key,
+			Value: m.Value,
+		})
 	}
 
-	result := p.client.ProduceSync(ctx, record)
-	if err := result.FirstErr(); err != nil {
+	results := p.client.ProduceSync(ctx, records...)
+	if err := results.FirstErr(); err != nil {
 		_ = p.client.EndTransaction(ctx, kgo.TryAbort)
-		p.logger.Errorw("producer failed to send a transaction", "error", err)
+		p.logger.Errorw("producer failed to send a transaction", "error", err, "size", len(messages))
 		return err
 	}
 
 	if err := p.client.EndTransaction(ctx, kgo.TryCommit); err != nil {
-		p.logger.Errorw("producer failed to commit a transaction", "error", err)
+		p.logger.Errorw("producer failed to commit a transaction", "error", err, "size", len(messages))
 		return err
 	}
 
 	return nil
 }
+
+func (p *Producer) Close() {
+	p.client.Close()
+}
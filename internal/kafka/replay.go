@@ -0,0 +1,350 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+)
+
+// ReplayRange задает историю для повторной обработки: начало по времени
+// записи (From) или по смещениям партиций (Offsets) и необязательный конец
+// по времени (Until, не включая). Без Until история заканчивается там, где
+// был конец топика на момент старта.
+type ReplayRange struct {
+	From    time.Time
+	Offsets map[int32]int64
+	Until   time.Time
+}
+
+// ParseReplayRange собирает диапазон из значений флагов -replay-from,
+// -replay-offsets и -replay-until. Возвращает nil, если режим повтора не
+// запрошен.
+func ParseReplayRange(from, offsets, until string) (*ReplayRange, error) {
+	if from == "" && offsets == "" {
+		if until != "" {
+			return nil, fmt.Errorf("-replay-until задается вместе с -replay-from или -replay-offsets")
+		}
+		return nil, nil
+	}
+	if from != "" && offsets != "" {
+		return nil, fmt.Errorf("нужно указать либо -replay-from, либо -replay-offsets")
+	}
+
+	var r ReplayRange
+	var err error
+	if from != "" {
+		if r.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, fmt.Errorf("неверное время -replay-from: %w", err)
+		}
+	} else {
+		if r.Offsets, err = ParsePartitionOffsets(offsets); err != nil {
+			return nil, err
+		}
+	}
+
+	if until != "" {
+		if r.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, fmt.Errorf("неверное время -replay-until: %w", err)
+		}
+		if !r.From.IsZero() && !r.From.Before(r.Until) {
+			return nil, fmt.Errorf("-replay-from должно быть раньше -replay-until")
+		}
+	}
+	return &r, nil
+}
+
+// ParsePartitionOffsets разбирает значение вида "0:120,1:80".
+func ParsePartitionOffsets(value string) (map[int32]int64, error) {
+	offsets := make(map[int32]int64)
+	for _, pair := range strings.Split(value, ",") {
+		partition, offset, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("неверное смещение %q, нужно партиция:смещение", pair)
+		}
+		p, err := strconv.ParseInt(partition, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("неверная партиция %q", partition)
+		}
+		o, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil || o < 0 {
+			return nil, fmt.Errorf("неверное смещение %q", offset)
+		}
+		offsets[int32(p)] = o
+	}
+	return offsets, nil
+}
+
+// ReplayPartition — смещения [Start, End) одной партиции.
+type ReplayPartition struct {
+	Start int64
+	End   int64
+}
+
+// PlanReplay переводит диапазон в смещения партиций. При заданных Offsets
+// остальные партиции не перечитываются.
+func PlanReplay(ctx context.Context, client *kgo.Client, topic string, r ReplayRange) (map[int32]ReplayPartition, error) {
+	partitions, err := listPartitions(ctx, client, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := listOffsets(ctx, client, topic, partitions, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	ends := latest
+	if !r.Until.IsZero() {
+		if ends, err = offsetsForTime(ctx, client, topic, partitions, r.Until, latest); err != nil {
+			return nil, err
+		}
+	}
+
+	var starts map[int32]int64
+	if r.Offsets != nil {
+		starts = make(map[int32]int64, len(r.Offsets))
+		for partition, offset := range r.Offsets {
+			if _, ok := latest[partition]; !ok {
+				return nil, fmt.Errorf("в топике %s нет партиции %d", topic, partition)
+			}
+			starts[partition] = offset
+		}
+	} else {
+		if starts, err = offsetsForTime(ctx, client, topic, partitions, r.From, latest); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := make(map[int32]ReplayPartition, len(starts))
+	for partition, start := range starts {
+		plan[partition] = ReplayPartition{Start: start, End: ends[partition]}
+	}
+	return plan, nil
+}
+
+func listPartitions(ctx context.Context, client *kgo.Client, topic string) ([]int32, error) {
+	req := kmsg.NewPtrMetadataRequest()
+	reqTopic := kmsg.NewMetadataRequestTopic()
+	reqTopic.Topic = kmsg.StringPtr(topic)
+	req.Topics = append(req.Topics, reqTopic)
+
+	resp, err := req.RequestWith(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Topics) != 1 {
+		return nil, fmt.Errorf("нет метаданных топика %s", topic)
+	}
+	if err := kerr.ErrorForCode(resp.Topics[0].ErrorCode); err != nil {
+		return nil, fmt.Errorf("метаданные топика %s: %w", topic, err)
+	}
+
+	partitions := make([]int32, 0, len(resp.Topics[0].Partitions))
+	for _, p := range resp.Topics[0].Partitions {
+		partitions = append(partitions, p.Partition)
+	}
+	return partitions, nil
+}
+
+// offsetsForTime возвращает для каждой партиции смещение первой записи не
+// раньше t, а если таких нет — конец партиции из latest.
+func offsetsForTime(ctx context.Context, client *kgo.Client, topic string, partitions []int32, t time.Time, latest map[int32]int64) (map[int32]int64, error) {
+	offsets, err := listOffsets(ctx, client, topic, partitions, t.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	for partition, offset := range offsets {
+		if offset < 0 {
+			offsets[partition] = latest[partition]
+		}
+	}
+	return offsets, nil
+}
+
+// listOffsets выполняет ListOffsets для всех партиций. timestamp -1 — конец
+// закоммиченных транзакций, иначе время в миллисекундах.
+func listOffsets(ctx context.Context, client *kgo.Client, topic string, partitions []int32, timestamp int64) (map[int32]int64, error) {
+	req := kmsg.NewPtrListOffsetsRequest()
+	req.ReplicaID = -1
+	req.IsolationLevel = 1
+	reqTopic := kmsg.NewListOffsetsRequestTopic()
+	reqTopic.Topic = topic
+	for _, partition := range partitions {
+		p := kmsg.NewListOffsetsRequestTopicPartition()
+		p.Partition = partition
+		p.Timestamp = timestamp
+		reqTopic.Partitions = append(reqTopic.Partitions, p)
+	}
+	req.Topics = append(req.Topics, reqTopic)
+
+	offsets := make(map[int32]int64, len(partitions))
+	for _, shard := range client.RequestSharded(ctx, req) {
+		if shard.Err != nil {
+			return nil, shard.Err
+		}
+		resp := shard.Resp.(*kmsg.ListOffsetsResponse)
+		for _, t := range resp.Topics {
+			for _, p := range t.Partitions {
+				if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+					return nil, fmt.Errorf("смещения %s/%d: %w", t.Topic, p.Partition, err)
+				}
+				offsets[p.Partition] = p.Offset
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// ReplayClient читает диапазон из плана отдельной группой и сообщает через
+// Done, когда все записи диапазона обработаны и закоммичены. Записи за
+// концом диапазона и из партиций вне плана отбрасываются.
+type ReplayClient struct {
+	client Client
+	plan   map[int32]ReplayPartition
+
+	mu       sync.Mutex
+	position map[int32]int64
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func NewReplayClient(brokers []string, groupID, topic string, plan map[int32]ReplayPartition, logger *zap.SugaredLogger) (*ReplayClient, error) {
+	var adjustOnce sync.Once
+	client, err := NewGroupClient(brokers, groupID, topic, logger,
+		// Управляющие записи транзакций занимают смещения, и без них конец
+		// диапазона может оказаться недостижимым
+		kgo.KeepControlRecords(),
+		kgo.AdjustFetchOffsetsFn(func(_ context.Context, offsets map[string]map[int32]kgo.Offset) (map[string]map[int32]kgo.Offset, error) {
+			// Начальные смещения ставятся один раз: после перебалансировки
+			// группа продолжает с закоммиченных
+			adjustOnce.Do(func() {
+				for partition := range offsets[topic] {
+					if p, ok := plan[partition]; ok {
+						offsets[topic][partition] = kgo.NewOffset().At(p.Start).WithEpoch(-1)
+					}
+				}
+			})
+			return offsets, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return WrapReplayClient(client, plan), nil
+}
+
+// WrapReplayClient ограничивает чтение клиента, который уже стоит на началах
+// диапазонов плана, концами этих диапазонов.
+func WrapReplayClient(client Client, plan map[int32]ReplayPartition) *ReplayClient {
+	r := &ReplayClient{
+		client:   client,
+		plan:     plan,
+		position: make(map[int32]int64, len(plan)),
+		done:     make(chan struct{}),
+	}
+	for partition, p := range plan {
+		r.position[partition] = p.Start
+	}
+
+	r.checkDone()
+	return r
+}
+
+func (r *ReplayClient) PollFetches(ctx context.Context) kgo.Fetches {
+	fetches := r.client.PollFetches(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range fetches {
+		for j := range fetches[i].Topics {
+			for k := range fetches[i].Topics[j].Partitions {
+				p := &fetches[i].Topics[j].Partitions[k]
+				bounds, ok := r.plan[p.Partition]
+				if !ok {
+					p.Records = nil
+					continue
+				}
+
+				records := make([]*kgo.Record, 0, len(p.Records))
+				for _, record := range p.Records {
+					if record.Offset >= bounds.End {
+						break
+					}
+					if record.Attrs.IsControl() {
+						r.advance(p.Partition, record.Offset+1)
+						continue
+					}
+					records = append(records, record)
+				}
+				p.Records = records
+			}
+		}
+	}
+	return fetches
+}
+
+func (r *ReplayClient) MarkCommitRecords(rs ...*kgo.Record) {
+	r.client.MarkCommitRecords(rs...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, record := range rs {
+		r.advance(record.Partition, record.Offset+1)
+	}
+}
+
+func (r *ReplayClient) CommitUncommittedOffsets(ctx context.Context) error {
+	if err := r.client.CommitUncommittedOffsets(ctx); err != nil {
+		return err
+	}
+	r.checkDone()
+	return nil
+}
+
+func (r *ReplayClient) Close() {
+	r.client.Close()
+}
+
+// Done закрывается, когда весь диапазон обработан.
+func (r *ReplayClient) Done() <-chan struct{} {
+	return r.done
+}
+
+// Progress возвращает, до какого смещения дошла каждая партиция.
+func (r *ReplayClient) Progress() map[int32]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	progress := make(map[int32]int64, len(r.position))
+	for partition, offset := range r.position {
+		progress[partition] = offset
+	}
+	return progress
+}
+
+func (r *ReplayClient) advance(partition int32, offset int64) {
+	if offset > r.position[partition] {
+		r.position[partition] = offset
+	}
+}
+
+func (r *ReplayClient) checkDone() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for partition, p := range r.plan {
+		if r.position[partition] < p.End {
+			return
+		}
+	}
+	r.doneOnce.Do(func() { close(r.done) })
+}
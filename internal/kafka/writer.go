@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+)
+
+// Writer — нетранзакционный продюсер для отправки в один топик.
+type Writer struct {
+	client *kgo.Client
+	topic  string
+	logger *zap.SugaredLogger
+}
+
+func NewWriter(brokers []string, topic string, logger *zap.SugaredLogger) (*Writer, error) {
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.DefaultProduceTopic(topic),
+		kgo.AllowAutoTopicCreation(),
+		kgo.ProduceRequestTimeout(3*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{client: client, topic: topic, logger: logger}, nil
+}
+
+func (w *Writer) Write(ctx context.Context, key, value []byte) error {
+	return w.WriteRecord(ctx, &kgo.Record{Key: key, Value: value})
+}
+
+// WriteRecord отправляет запись целиком, вместе с заголовками.
+func (w *Writer) WriteRecord(ctx context.Context, record *kgo.Record) error {
+	result := w.client.ProduceSync(ctx, record)
+	if err := result.FirstErr(); err != nil {
+		w.logger.Errorw("writer failed to send a record", "topic", w.topic, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) Close() {
+	w.client.Close()
+}
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -98,12 +99,15 @@ var (
 		[]string{"method"},
 	)
 
-	OrderValueDistribution = prometheus.NewHistogram(
+	// Суммы в разных валютах несравнимы, поэтому распределение ведется
+	// отдельно по каждой.
+	OrderValueDistribution = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "order_value",
-			Help:    "Order value distribution",
+			Help:    "Order value distribution by currency",
 			Buckets: []float64{100, 500, 1000, 3000, 5000, 10000},
 		},
+		[]string{"currency"},
 	)
 
 	OrderWeightDistribution = prometheus.NewHistogram(
@@ -135,6 +139,83 @@ var (
 			Help: "Total number of not accepted orders",
 		},
 	)
+
+	OutboxDeadTasks = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "audit_outbox_dead_tasks",
+			Help: "Current number of audit tasks with no attempts left",
+		},
+	)
+
+	OutboxLag = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "audit_outbox_lag_seconds",
+			Help: "Age of the oldest audit task not yet sent to Kafka",
+		},
+	)
+
+	OutboxDeadTasksTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "audit_outbox_dead_tasks_total",
+			Help: "Total number of audit tasks moved to dead letter",
+		},
+	)
+
+	AuditQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "audit_queue_depth",
+			Help: "Current number of audit events waiting in a sink queue",
+		},
+		[]string{"queue"},
+	)
+
+	AuditEventsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_events_dropped_total",
+			Help: "Total number of audit events dropped because a sink queue was full or closed",
+		},
+		[]string{"queue", "reason"},
+	)
+
+	AuditEventsSpilled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_events_spilled_total",
+			Help: "Total number of audit events spilled to disk because a sink queue was full",
+		},
+		[]string{"queue"},
+	)
+
+	AuditEventsInvalid = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_events_invalid_total",
+			Help: "Total number of audit events rejected because they do not match the event schema",
+		},
+		[]string{"side", "type"},
+	)
+
+	AuditConsumerEvents = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_consumer_events_total",
+			Help: "Total number of audit events handled by the consumer by result",
+		},
+		[]string{"result"},
+	)
+
+	AuditConsumerLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "audit_consumer_lag",
+			Help: "Number of records in a partition not yet processed by the consumer",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	AuditConsumerBatchDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "audit_consumer_batch_duration_seconds",
+			Help:    "Time to process a batch of records from one partition",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+		},
+	)
 )
 
 func RegisterMetrics() error {
@@ -156,6 +237,16 @@ func RegisterMetrics() error {
 		OrderReturns,
 		OrdersByStatus,
 		FailedOrderCount,
+		OutboxDeadTasks,
+		OutboxDeadTasksTotal,
+		OutboxLag,
+		AuditQueueDepth,
+		AuditEventsDropped,
+		AuditEventsSpilled,
+		AuditEventsInvalid,
+		AuditConsumerEvents,
+		AuditConsumerLag,
+		AuditConsumerBatchDuration,
 	}
 
 	for _, collector := range collectors {
@@ -191,8 +282,8 @@ func IncRequestCount(method, status string) {
 	RequestCount.WithLabelValues(method, status).Inc()
 }
 
-func ObserveOrderValue(value float64) {
-	OrderValueDistribution.Observe(value)
+func ObserveOrderValue(currency string, value float64) {
+	OrderValueDistribution.WithLabelValues(currency).Observe(value)
 }
 
 func ObserveOrderWeight(weight float64) {
@@ -210,3 +301,54 @@ func IncFailedOrderCount() {
 func IncOrdersByStatus(status string) {
 	OrdersByStatus.WithLabelValues(status).Inc()
 }
+
+func SetOutboxDeadTasks(count int) {
+	OutboxDeadTasks.Set(float64(count))
+}
+
+func IncOutboxDeadTasks() {
+	OutboxDeadTasksTotal.Inc()
+	OutboxDeadTasks.Inc()
+}
+
+func SetOutboxLag(lag time.Duration) {
+	OutboxLag.Set(lag.Seconds())
+}
+
+func SetAuditQueueDepth(queue string, depth int) {
+	AuditQueueDepth.WithLabelValues(queue).Set(float64(depth))
+}
+
+func IncAuditEventsDropped(queue, reason string) {
+	AuditEventsDropped.WithLabelValues(queue, reason).Inc()
+}
+
+func IncAuditEventsSpilled(queue string) {
+	AuditEventsSpilled.WithLabelValues(queue).Inc()
+}
+
+// IncAuditEventsInvalid считает события, отклоненные проверкой схемы.
+// side — producer или consumer.
+func IncAuditEventsInvalid(side, eventType string) {
+	AuditEventsInvalid.WithLabelValues(side, eventType).Inc()
+}
+
+func IncKafkaMessages(count int) {
+	KafkaMessages.Add(float64(count))
+}
+
+func IncKafkaErrors() {
+	KafkaErrors.Inc()
+}
+
+func AddAuditConsumerEvents(result string, count int) {
+	AuditConsumerEvents.WithLabelValues(result).Add(float64(count))
+}
+
+func SetAuditConsumerLag(topic string, partition int32, lag int64) {
+	AuditConsumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+func ObserveAuditConsumerBatch(duration time.Duration) {
+	AuditConsumerBatchDuration.Observe(duration.Seconds())
+}
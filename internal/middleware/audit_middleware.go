@@ -5,8 +5,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/schema"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	eventsv1 "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/events/v1"
 )
 
 func AuditMiddleware(p *audit.Pipeline) gin.HandlerFunc {
@@ -16,9 +18,10 @@ func AuditMiddleware(p *audit.Pipeline) gin.HandlerFunc {
 
 		metrics.HTTPRequestCount.WithLabelValues(method, path).Inc()
 
-		p.SendEvent(domain.EventAPIRequest, map[string]any{
-			"method": method,
-			"path":   path,
+		p.SendEvent(c.Request.Context(), domain.EventAPIRequest, &eventsv1.ApiRequest{
+			Transport: schema.TransportHTTP,
+			Method:    method,
+			Path:      path,
 		})
 
 		c.Next()
@@ -27,9 +30,12 @@ func AuditMiddleware(p *audit.Pipeline) gin.HandlerFunc {
 
 		metrics.HTTPResponseStatusCount.WithLabelValues(status, path).Inc()
 
-		p.SendEvent(domain.EventAPIResponse, map[string]any{
-			"status": c.Writer.Status(),
-			"path":   c.Request.URL.Path,
+		// Контекст берется после c.Next, чтобы в событие попал пользователь из AuthMiddleware
+		p.SendEvent(c.Request.Context(), domain.EventAPIResponse, &eventsv1.ApiResponse{
+			Transport: schema.TransportHTTP,
+			Method:    method,
+			Path:      path,
+			Status:    int32(c.Writer.Status()),
 		})
 	}
 }
@@ -1,30 +1,63 @@
 package middleware
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// Права, необходимые для вызова маршрута. Маршрут, которого нет в списке,
+// доступен только администратору.
+var routePermissions = map[string]domain.Permission{
+	"POST /orders":                             domain.PermissionAcceptOrder,
+	"DELETE /orders/:id/return":                domain.PermissionReturnOrder,
+	"GET /orders/:id/history":                  domain.PermissionViewOrder,
+	"PUT /actions/issues_refunds":              domain.PermissionIssueRefund,
+	"GET /reports/:user_id/orders":             domain.PermissionViewReports,
+	"GET /reports/refunded":                    domain.PermissionViewReports,
+	"GET /reports/history":                     domain.PermissionViewReports,
+	"GET /reports/:user_id/orders/active":      domain.PermissionViewReports,
+	"GET /reports/active":                      domain.PermissionViewReports,
+	"GET /reports/history/v2":                  domain.PermissionViewReports,
+	"PUT /admin/users/role":                    domain.PermissionManageUsers,
+	"GET /audit/events":                        domain.PermissionViewAudit,
+	"GET /admin/audit/dead-tasks":              domain.PermissionManageAudit,
+	"GET /admin/audit/dead-tasks/:id":          domain.PermissionManageAudit,
+	"POST /admin/audit/dead-tasks/:id/requeue": domain.PermissionManageAudit,
+	"POST /admin/audit/dead-tasks/requeue":     domain.PermissionManageAudit,
+	"GET /admin/audit/chain/verify":            domain.PermissionManageAudit,
+}
+
+func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString, err := c.Cookie("jwt")
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "необходимо выполнить вход"})
+			apperr.JSON(c, domain.ErrUnauthenticated)
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(domain.JwtSecret), nil
-		})
+		claims, err := authService.ParseToken(c.Request.Context(), tokenString)
+		if err != nil {
+			apperr.JSON(c, domain.ErrInvalidToken)
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "необходимо выполнить вход заново"})
+		if !isAllowed(claims.Role, c.Request.Method+" "+c.FullPath()) {
+			apperr.JSON(c, domain.ErrForbidden)
 			return
 		}
 
+		c.Request = c.Request.WithContext(domain.ContextWithActor(c.Request.Context(), claims.Email))
+
 		c.Next()
 	}
 }
+
+func isAllowed(role domain.Role, route string) bool {
+	permission, ok := routePermissions[route]
+	if !ok {
+		return role == domain.RoleAdmin
+	}
+	return role.HasPermission(permission)
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+// IdempotencyMiddleware отдает на повтор изменяющего запроса с заголовком
+// Idempotency-Key ответ первого запроса. Ответы 5xx не сохраняются: такой
+// запрос можно повторить с тем же ключом. Ответ запроса, выполнявшегося
+// дольше блокировки ключа, тоже не сохраняется: ключ мог занять повтор.
+// Ставится после AuthMiddleware, потому что ключи хранятся отдельно для
+// каждого пользователя.
+func IdempotencyMiddleware(idempotency service.IdempotencyService, logger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(domain.IdempotencyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apperr.JSON(c, domain.ErrWrongJSON)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		req := domain.IdempotentRequest{
+			Scope:       "http",
+			Key:         key,
+			Fingerprint: service.IdempotencyFingerprint([]byte(c.Request.Method), []byte(c.Request.URL.RequestURI()), body),
+		}
+
+		ctx := c.Request.Context()
+		saved, token, err := idempotency.Begin(ctx, req)
+		switch {
+		case errors.Is(err, domain.ErrInvalidIdempotencyKey),
+			errors.Is(err, domain.ErrIdempotencyKeyReused),
+			errors.Is(err, domain.ErrIdempotencyInProgress):
+			apperr.JSON(c, err)
+			return
+		case err != nil:
+			logger.Errorw("failed to check idempotency key", "error", err)
+			apperr.JSON(c, domain.ErrIdempotencyUnavailable)
+			return
+		case saved != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(saved.Code, saved.ContentType, saved.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		finishCtx, cancel := service.IdempotencyFinishContext(ctx)
+		defer cancel()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			if err := idempotency.Abort(finishCtx, req, token); err != nil {
+				logger.Errorw("failed to release idempotency key", "error", err)
+			}
+			return
+		}
+
+		response := domain.IdempotentResponse{
+			Code:        writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := idempotency.Finish(finishCtx, req, token, response); err != nil {
+			logger.Errorw("failed to save idempotent response", "error", err)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// recordingWriter копирует тело ответа, чтобы его можно было сохранить.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
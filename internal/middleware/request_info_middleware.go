@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestInfoMiddleware открывает span запроса (продолжая трейс вызывающей стороны,
+// если он передан) и сохраняет в контексте данные запроса для аудита. IP клиента
+// берется из X-Forwarded-For только от доверенных прокси (TRUSTED_PROXIES).
+func RequestInfoMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer("http")
+
+	return func(c *gin.Context) {
+		requestID := domain.RequestIDOrNew(c.GetHeader(RequestIDHeader))
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+c.Request.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		var traceID string
+		if spanContext := span.SpanContext(); spanContext.HasTraceID() {
+			traceID = spanContext.TraceID().String()
+		}
+
+		ctx = domain.ContextWithRequestInfo(ctx, domain.RequestInfo{
+			RequestID: requestID,
+			TraceID:   traceID,
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+// Package orderevents кодирует доменные события заказа по схеме
+// proto/events/v1/order.proto.
+package orderevents
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	eventsv1 "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/events/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Version — версия схемы событий заказа.
+const Version = 1
+
+var marshaler = protojson.MarshalOptions{UseProtoNames: true}
+
+// Marshal кодирует событие в JSON для записи в outbox.
+func Marshal(event domain.OrderEvent) ([]byte, error) {
+	msg, err := toProto(event)
+	if err != nil {
+		return nil, err
+	}
+	return marshaler.Marshal(msg)
+}
+
+func toProto(event domain.OrderEvent) (*eventsv1.OrderEvent, error) {
+	order := event.Order
+	msg := &eventsv1.OrderEvent{
+		EventId:       event.ID,
+		SchemaVersion: Version,
+		OrderId:       event.Change.OrderID,
+		OccurredAt:    event.Change.ChangedAt.UTC().Format(time.RFC3339Nano),
+		Actor:         event.Change.Actor,
+	}
+
+	switch event.Change.To {
+	case domain.StatusStored:
+		msg.Event = &eventsv1.OrderEvent_Stored{Stored: &eventsv1.OrderStored{
+			RecipientId:    order.RecipientID,
+			Expiry:         order.Expiry.UTC().Format(time.RFC3339),
+			BasePrice:      order.BasePrice.Float64(),
+			PackagePrice:   order.PackagePrice.Float64(),
+			Weight:         order.Weight,
+			Packaging:      string(order.Packaging),
+			Price:          moneyToProto(order.BasePrice),
+			PackagingPrice: moneyToProto(order.PackagePrice),
+		}}
+	case domain.StatusIssued:
+		msg.Event = &eventsv1.OrderEvent_Issued{Issued: &eventsv1.OrderIssued{
+			RecipientId: order.RecipientID,
+		}}
+	case domain.StatusRefunded:
+		msg.Event = &eventsv1.OrderEvent_Refunded{Refunded: &eventsv1.OrderRefunded{
+			RecipientId: order.RecipientID,
+		}}
+	case domain.StatusReturnedToCourier:
+		msg.Event = &eventsv1.OrderEvent_ReturnedToCourier{ReturnedToCourier: &eventsv1.OrderReturnedToCourier{
+			RecipientId: order.RecipientID,
+		}}
+	default:
+		return nil, fmt.Errorf("нет события заказа для статуса %q", event.Change.To)
+	}
+
+	return msg, nil
+}
+
+func moneyToProto(m domain.Money) *eventsv1.Money {
+	return &eventsv1.Money{MinorUnits: m.MinorUnits, Currency: string(m.Currency)}
+}
@@ -0,0 +1,34 @@
+package auditeventrepo
+
+import (
+	"context"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage"
+	"go.uber.org/zap"
+)
+
+type AuditEventRepository interface {
+	SaveEvents(ctx context.Context, events []domain.AuditEvent) (int, error)
+}
+
+type auditEventRepository struct {
+	storage storage.AuditEventStorage
+	logger  *zap.SugaredLogger
+}
+
+func NewAuditEventRepository(storage storage.AuditEventStorage, logger *zap.SugaredLogger) AuditEventRepository {
+	return &auditEventRepository{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (r *auditEventRepository) SaveEvents(ctx context.Context, events []domain.AuditEvent) (int, error) {
+	inserted, err := r.storage.SaveEvents(ctx, events)
+	if err != nil {
+		r.logger.Error("failed to save audit events to DB", zap.Error(err))
+		return 0, domain.ErrDatabase
+	}
+	return inserted, nil
+}
@@ -2,6 +2,8 @@ package auditrepo
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
@@ -11,9 +13,19 @@ import (
 
 type AuditRepository interface {
 	SaveLog(ctx context.Context, auditTask domain.AuditTask) error
-	BeginTx(ctx context.Context) (pgx.Tx, error)
-	FetchPendingTasksTx(ctx context.Context, tx pgx.Tx, limit int) ([]domain.AuditTask, error)
+	SaveLogs(ctx context.Context, auditTasks []domain.AuditTask) error
+	ClaimPendingTasks(ctx context.Context, limit int, lease time.Duration) ([]domain.AuditTask, error)
+	FinishTasks(ctx context.Context, ids []int, finishedAt time.Time) error
+	ListenNewTasks(ctx context.Context, notify func()) error
+	OldestPendingTaskAge(ctx context.Context) (time.Duration, error)
 	UpdateTask(ctx context.Context, task domain.AuditTask) error
+	GetEvents(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, string, error)
+	ListDeadTasks(ctx context.Context, limit int, cursor *int) ([]domain.AuditTask, string, error)
+	GetTask(ctx context.Context, id int) (domain.AuditTask, error)
+	RequeueDeadTasks(ctx context.Context, ids []int) (int64, error)
+	CountDeadTasks(ctx context.Context) (int, error)
+	GetChainRecords(ctx context.Context, afterID, limit int) ([]domain.AuditChainRecord, error)
+	GetChainHead(ctx context.Context) (domain.AuditChainHead, error)
 }
 
 type auditRepository struct {
@@ -32,14 +44,92 @@ func (r *auditRepository) SaveLog(ctx context.Context, auditTask domain.AuditTas
 	return r.storage.SaveLog(ctx, auditTask)
 }
 
-func (r *auditRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	return r.storage.BeginTx(ctx)
+func (r *auditRepository) SaveLogs(ctx context.Context, auditTasks []domain.AuditTask) error {
+	return r.storage.SaveLogs(ctx, auditTasks)
 }
 
-func (r *auditRepository) FetchPendingTasksTx(ctx context.Context, tx pgx.Tx, limit int) ([]domain.AuditTask, error) {
-	return r.storage.FetchPendingTasksTx(ctx, tx, limit)
+func (r *auditRepository) ClaimPendingTasks(ctx context.Context, limit int, lease time.Duration) ([]domain.AuditTask, error) {
+	return r.storage.ClaimPendingTasks(ctx, limit, lease)
+}
+
+func (r *auditRepository) FinishTasks(ctx context.Context, ids []int, finishedAt time.Time) error {
+	return r.storage.FinishTasks(ctx, ids, finishedAt)
+}
+
+func (r *auditRepository) ListenNewTasks(ctx context.Context, notify func()) error {
+	return r.storage.ListenNewTasks(ctx, notify)
+}
+
+func (r *auditRepository) OldestPendingTaskAge(ctx context.Context) (time.Duration, error) {
+	return r.storage.OldestPendingTaskAge(ctx)
 }
 
 func (r *auditRepository) UpdateTask(ctx context.Context, task domain.AuditTask) error {
 	return r.storage.UpdateTask(ctx, task)
 }
+
+func (r *auditRepository) GetEvents(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, string, error) {
+	events, nextCursor, err := r.storage.GetEvents(ctx, filter)
+	if err != nil {
+		r.logger.Error("failed to get audit events from DB", zap.Error(err))
+		return nil, "", domain.ErrDatabase
+	}
+	return events, nextCursor, nil
+}
+
+func (r *auditRepository) ListDeadTasks(ctx context.Context, limit int, cursor *int) ([]domain.AuditTask, string, error) {
+	tasks, nextCursor, err := r.storage.ListDeadTasks(ctx, limit, cursor)
+	if err != nil {
+		r.logger.Error("failed to list dead audit tasks from DB", zap.Error(err))
+		return nil, "", domain.ErrDatabase
+	}
+	return tasks, nextCursor, nil
+}
+
+func (r *auditRepository) GetTask(ctx context.Context, id int) (domain.AuditTask, error) {
+	task, err := r.storage.GetTask(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.AuditTask{}, domain.ErrAuditTaskNotFound
+	}
+	if err != nil {
+		r.logger.Error("failed to get audit task from DB", zap.Error(err))
+		return domain.AuditTask{}, domain.ErrDatabase
+	}
+	return task, nil
+}
+
+func (r *auditRepository) RequeueDeadTasks(ctx context.Context, ids []int) (int64, error) {
+	requeued, err := r.storage.RequeueDeadTasks(ctx, ids)
+	if err != nil {
+		r.logger.Error("failed to requeue dead audit tasks", zap.Error(err))
+		return 0, domain.ErrDatabase
+	}
+	return requeued, nil
+}
+
+func (r *auditRepository) CountDeadTasks(ctx context.Context) (int, error) {
+	count, err := r.storage.CountDeadTasks(ctx)
+	if err != nil {
+		r.logger.Error("failed to count dead audit tasks", zap.Error(err))
+		return 0, domain.ErrDatabase
+	}
+	return count, nil
+}
+
+func (r *auditRepository) GetChainRecords(ctx context.Context, afterID, limit int) ([]domain.AuditChainRecord, error) {
+	records, err := r.storage.GetChainRecords(ctx, afterID, limit)
+	if err != nil {
+		r.logger.Error("failed to get audit chain records from DB", zap.Error(err))
+		return nil, domain.ErrDatabase
+	}
+	return records, nil
+}
+
+func (r *auditRepository) GetChainHead(ctx context.Context) (domain.AuditChainHead, error) {
+	head, err := r.storage.GetChainHead(ctx)
+	if err != nil {
+		r.logger.Error("failed to get audit chain head from DB", zap.Error(err))
+		return domain.AuditChainHead{}, domain.ErrDatabase
+	}
+	return head, nil
+}
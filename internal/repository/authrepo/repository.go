@@ -34,6 +34,7 @@ func (r *AuthRepository) Register(ctx context.Context, user *domain.User) error
 		return domain.ErrUserAlreadyExists
 	}
 
+	user.Role = domain.RoleGuest
 	user.Password, err = hashPassword(user.Password)
 	if err != nil {
 		r.logger.Error("cryptography error", zap.Error(err))
@@ -48,17 +49,87 @@ func (r *AuthRepository) Register(ctx context.Context, user *domain.User) error
 	return nil
 }
 
-func (r *AuthRepository) Login(ctx context.Context, email, password string) error {
+func (r *AuthRepository) Login(ctx context.Context, email, password string) (*domain.User, error) {
 	user, err := r.storage.GetUserByEmail(ctx, email)
-	if err != nil && !errors.Is(err, domain.ErrInvalidCredentials) {
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			return nil, err
+		}
 		r.logger.Error("failed to find the user in DB", zap.Error(err))
-		return domain.ErrDatabase
+		return nil, domain.ErrDatabase
 	}
 
 	if !compareHashAndPassword(password, user.Password) {
-		return domain.ErrInvalidCredentials
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func (r *AuthRepository) AssignRole(ctx context.Context, email string, role domain.Role) error {
+	if !role.IsValid() {
+		return domain.ErrInvalidRole
 	}
 
+	err := r.storage.UpdateUserRole(ctx, email, role)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		r.logger.Error("failed to update the user role in DB", zap.String("email", email), zap.Error(err))
+		return domain.ErrDatabase
+	}
+	return err
+}
+
+func (r *AuthRepository) GetUser(ctx context.Context, email string) (*domain.User, error) {
+	user, err := r.storage.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			return nil, domain.ErrUserNotFound
+		}
+		r.logger.Error("failed to find the user in DB", zap.Error(err))
+		return nil, domain.ErrDatabase
+	}
+	return user, nil
+}
+
+func (r *AuthRepository) SaveRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	if err := r.storage.SaveRefreshToken(ctx, token); err != nil {
+		r.logger.Error("failed to save the refresh token in DB", zap.Error(err))
+		return domain.ErrDatabase
+	}
+	return nil
+}
+
+// ConsumeRefreshToken погашает refresh токен. При повторном использовании
+// уже обменянного токена отзываются все токены пользователя. Токен,
+// отозванный при выходе, просто недействителен: его может прислать вторая
+// вкладка или повтор запроса.
+func (r *AuthRepository) ConsumeRefreshToken(ctx context.Context, tokenHash string) (string, error) {
+	token, err := r.storage.ConsumeRefreshToken(ctx, tokenHash)
+	switch {
+	case err == nil:
+		return token.Email, nil
+	case errors.Is(err, domain.ErrTokenRevoked):
+		if token.RevokeReason != domain.RevokeRotated {
+			return "", domain.ErrInvalidRefreshToken
+		}
+		r.logger.Warn("revoked refresh token reused, revoking all user tokens", zap.String("email", token.Email))
+		if err := r.storage.RevokeUserRefreshTokens(ctx, token.Email); err != nil {
+			r.logger.Error("failed to revoke the user refresh tokens", zap.Error(err))
+		}
+		return "", domain.ErrInvalidRefreshToken
+	case errors.Is(err, domain.ErrInvalidRefreshToken):
+		return "", err
+	default:
+		r.logger.Error("failed to consume the refresh token", zap.Error(err))
+		return "", domain.ErrDatabase
+	}
+}
+
+func (r *AuthRepository) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	if err := r.storage.RevokeRefreshToken(ctx, tokenHash); err != nil {
+		r.logger.Error("failed to revoke the refresh token", zap.Error(err))
+		return domain.ErrDatabase
+	}
 	return nil
 }
 
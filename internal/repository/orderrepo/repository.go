@@ -16,6 +16,8 @@ type OrderRepository interface {
 	ReturnOrder(ctx context.Context, id string) error
 	FindOrderByID(ctx context.Context, id string) (*domain.Order, error)
 	FindOrdersByIDs(ctx context.Context, ids []string) ([]*domain.Order, error)
+	GetStatusHistory(ctx context.Context, id string) ([]domain.OrderStatusChange, error)
+	ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error)
 }
 
 type orderRepository struct {
@@ -56,6 +58,7 @@ func (r *orderRepository) AcceptOrder(ctx context.Context, order domain.Order) e
 	order.PackagePrice = packaging.CalculatePrice()
 	now := time.Now().UTC()
 	order.StoredAt = &now
+	order.State = domain.StatusStored
 
 	err = r.orderStorage.SaveOrder(ctx, order)
 	if err != nil {
@@ -67,7 +70,10 @@ func (r *orderRepository) AcceptOrder(ctx context.Context, order domain.Order) e
 
 func (r *orderRepository) ReturnOrder(ctx context.Context, id string) error {
 	order, err := r.orderStorage.FindOrderByID(ctx, id)
-	if err != nil && !errors.Is(err, domain.ErrNotFoundOrder) {
+	if errors.Is(err, domain.ErrNotFoundOrder) {
+		return err
+	}
+	if err != nil {
 		r.logger.Error("failed to find the order in DB", zap.Error(err))
 		return domain.ErrDatabase
 	}
@@ -80,12 +86,18 @@ func (r *orderRepository) ReturnOrder(ctx context.Context, id string) error {
 		return domain.ErrNotExpiredOrder
 	}
 
-	if err := r.orderStorage.DeleteOrder(ctx, id); err != nil && !errors.Is(err, domain.ErrNotFoundOrder) {
-		r.logger.Error("failed to delete the order from DB", zap.String("orderID", id), zap.Error(err))
+	err = r.orderStorage.ReturnOrder(ctx, id)
+	var transitionErr *domain.ErrInvalidTransition
+	switch {
+	case err == nil, errors.Is(err, domain.ErrNotFoundOrder):
+		return err
+	case errors.As(err, &transitionErr):
+		// Статус сменился после проверки выше
+		return domain.ErrNotStoredOrder
+	default:
+		r.logger.Error("failed to return the order to courier in DB", zap.String("orderID", id), zap.Error(err))
 		return domain.ErrDatabase
 	}
-
-	return err
 }
 
 func (r *orderRepository) FindOrderByID(ctx context.Context, id string) (*domain.Order, error) {
@@ -107,3 +119,35 @@ func (r *orderRepository) FindOrdersByIDs(ctx context.Context, ids []string) ([]
 
 	return orders, nil
 }
+
+func (r *orderRepository) GetStatusHistory(ctx context.Context, id string) ([]domain.OrderStatusChange, error) {
+	history, err := r.orderStorage.GetStatusHistory(ctx, id)
+	if err != nil {
+		r.logger.Error("failed to get the order status history", zap.String("orderID", id), zap.Error(err))
+		return nil, domain.ErrDatabase
+	}
+
+	if len(history) > 0 {
+		return history, nil
+	}
+
+	// У заказа может не быть истории, если он принят до ее появления
+	if _, err := r.orderStorage.FindOrderByID(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFoundOrder) {
+			return nil, domain.ErrNotFoundOrder
+		}
+		r.logger.Error("failed to find the order in DB", zap.String("orderID", id), zap.Error(err))
+		return nil, domain.ErrDatabase
+	}
+
+	return []domain.OrderStatusChange{}, nil
+}
+
+func (r *orderRepository) ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error) {
+	orders, err := r.orderStorage.ReturnExpiredOrders(ctx, limit, dryRun)
+	if err != nil {
+		r.logger.Error("failed to return expired orders", zap.Int("limit", limit), zap.Error(err))
+		return nil, domain.ErrDatabase
+	}
+	return orders, nil
+}
@@ -1,7 +1,6 @@
 package repoutils
 
 import (
-	"fmt"
 	"strings"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
@@ -24,7 +23,7 @@ func ParsePackaging(input string) (domain.PackagingStrategy, error) {
 		case domain.PackagingTypeFilm:
 			strategy = domain.PackagingFilm{}
 		default:
-			return nil, fmt.Errorf("неизвестный тип упаковки")
+			return nil, domain.ErrUnknownPackaging
 		}
 
 		if strategy.IsMain() {
@@ -32,7 +31,7 @@ func ParsePackaging(input string) (domain.PackagingStrategy, error) {
 		}
 
 		if mainPackagingCount > 1 {
-			return nil, fmt.Errorf("нельзя комбинировать основные типы упаковки")
+			return nil, domain.ErrPackagingCombination
 		}
 
 		strategies = append(strategies, strategy)
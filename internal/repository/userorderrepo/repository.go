@@ -10,8 +10,8 @@ import (
 )
 
 type UserOrderRepository interface {
-	IssueOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error)
-	RefundOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error)
+	IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error)
+	RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error)
 }
 
 type userOrderRepository struct {
@@ -23,14 +23,15 @@ func NewUserOrderRepository(storage storage.UserOrderStorage, logger *zap.Sugare
 	return &userOrderRepository{userOrderStorage: storage, logger: logger}
 }
 
-func (r *userOrderRepository) IssueOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error) {
-	result, err := r.userOrderStorage.IssueOrders(ctx, userID, orderIDs)
+func (r *userOrderRepository) IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error) {
+	result, err := r.userOrderStorage.IssueOrders(ctx, userID, orderIDs, atomic)
 	if err != nil {
 		if errors.Is(err, domain.ErrDatabase) {
 			r.logger.Error("failed to issue the order",
 				zap.Error(err),
 				zap.String("userID", userID),
 				zap.Strings("orderIDs", orderIDs),
+				zap.Bool("atomic", atomic),
 			)
 			return domain.ProcessedOrders{}, domain.ErrDatabase
 		}
@@ -39,14 +40,15 @@ func (r *userOrderRepository) IssueOrders(ctx context.Context, userID string, or
 	return result, nil
 }
 
-func (r *userOrderRepository) RefundOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error) {
-	result, err := r.userOrderStorage.RefundOrders(ctx, userID, orderIDs)
+func (r *userOrderRepository) RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error) {
+	result, err := r.userOrderStorage.RefundOrders(ctx, userID, orderIDs, atomic)
 	if err != nil {
 		if errors.Is(err, domain.ErrDatabase) {
 			r.logger.Error("failed to refund the order",
 				zap.Error(err),
 				zap.String("userID", userID),
 				zap.Strings("orderIDs", orderIDs),
+				zap.Bool("atomic", atomic),
 			)
 			return domain.ProcessedOrders{}, domain.ErrDatabase
 		}
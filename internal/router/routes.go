@@ -6,32 +6,49 @@ import (
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/api"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/middleware"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"go.uber.org/zap"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(apiHandler *api.APIHandler, authHandler *api.AuthHandler, logger *zap.SugaredLogger, auditPipeline *audit.Pipeline) *gin.Engine {
+func SetupRouter(
+	apiHandler *api.APIHandler,
+	authHandler *api.AuthHandler,
+	auditHandler *api.AuditHandler,
+	authService service.AuthService,
+	idempotencyService service.IdempotencyService,
+	logger *zap.SugaredLogger,
+	auditPipeline *audit.Pipeline,
+) *gin.Engine {
 	router := gin.Default()
 
+	router.Use(middleware.RequestInfoMiddleware())
 	router.Use(middleware.AuditMiddleware(auditPipeline))
 	router.Use(middleware.MetricsMiddleware())
 
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
+
+	// Изменяющие запросы с Idempotency-Key можно безопасно повторять.
+	// Кроме /users: их ответы содержат токены.
+	idempotency := middleware.IdempotencyMiddleware(idempotencyService, logger)
+
 	orders := router.Group("/orders")
-	orders.Use(middleware.AuthMiddleware())
+	orders.Use(middleware.AuthMiddleware(authService), idempotency)
 	{
 		orders.POST("", apiHandler.AcceptOrder)
 		orders.DELETE("/:id/return", apiHandler.ReturnOrder)
+		orders.GET("/:id/history", apiHandler.GetOrderStatusHistory)
 	}
 
 	actions := router.Group("/actions")
-	actions.Use(middleware.AuthMiddleware())
+	actions.Use(middleware.AuthMiddleware(authService), idempotency)
 	{
 		actions.PUT("/issues_refunds", apiHandler.IssueRefundOrders)
 	}
 
 	reports := router.Group("/reports")
-	reports.Use(middleware.AuthMiddleware())
+	reports.Use(middleware.AuthMiddleware(authService))
 	{
 		reports.GET("/:user_id/orders", apiHandler.GetUserOrders)
 		reports.GET("/refunded", apiHandler.GetRefundedOrders)
@@ -41,10 +58,29 @@ func SetupRouter(apiHandler *api.APIHandler, authHandler *api.AuthHandler, logge
 		reports.GET("/history/v2", apiHandler.GetOrderHistoryV2)
 	}
 
+	admin := router.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(authService), idempotency)
+	{
+		admin.PUT("/users/role", authHandler.AssignRole)
+		admin.GET("/audit/dead-tasks", auditHandler.ListDeadTasks)
+		admin.GET("/audit/dead-tasks/:id", auditHandler.GetDeadTask)
+		admin.POST("/audit/dead-tasks/:id/requeue", auditHandler.RequeueDeadTask)
+		admin.POST("/audit/dead-tasks/requeue", auditHandler.RequeueDeadTasks)
+		admin.GET("/audit/chain/verify", auditHandler.VerifyChain)
+	}
+
+	auditLogs := router.Group("/audit")
+	auditLogs.Use(middleware.AuthMiddleware(authService))
+	{
+		auditLogs.GET("/events", auditHandler.GetEvents)
+	}
+
 	users := router.Group("/users")
 	{
 		users.POST("/signup", authHandler.Signup)
 		users.POST("/login", authHandler.Login)
+		users.POST("/refresh", authHandler.Refresh)
+		users.POST("/logout", authHandler.Logout)
 	}
 
 	health := router.Group("/health")
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+// ReturnJob периодически возвращает курьеру заказы, у которых истек срок хранения.
+type ReturnJob struct {
+	service  service.OrderService
+	pipeline *audit.Pipeline
+	logger   *zap.SugaredLogger
+	interval time.Duration
+	limit    int
+	dryRun   bool
+}
+
+func NewReturnJob(
+	service service.OrderService,
+	pipeline *audit.Pipeline,
+	logger *zap.SugaredLogger,
+	interval time.Duration,
+	limit int,
+	dryRun bool,
+) *ReturnJob {
+	return &ReturnJob{
+		service:  service,
+		pipeline: pipeline,
+		logger:   logger,
+		interval: interval,
+		limit:    limit,
+		dryRun:   dryRun,
+	}
+}
+
+func (j *ReturnJob) Run(ctx context.Context) {
+	j.logger.Infow("return job started", "interval", j.interval, "limit", j.limit, "dry_run", j.dryRun)
+	defer j.logger.Info("return job stopped")
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce(ctx)
+		}
+	}
+}
+
+func (j *ReturnJob) runOnce(ctx context.Context) {
+	ctx = domain.ContextWithActor(ctx, domain.ActorReturnJob)
+
+	orders, err := j.service.ReturnExpiredOrders(ctx, j.limit, j.dryRun)
+	if err != nil {
+		j.logger.Errorw("failed to return expired orders", "error", err)
+		return
+	}
+
+	if len(orders) == 0 {
+		return
+	}
+
+	if j.dryRun {
+		ids := make([]string, 0, len(orders))
+		for _, order := range orders {
+			ids = append(ids, order.ID)
+		}
+		j.logger.Infow("dry run: expired orders would be returned to courier", "order_ids", ids)
+		return
+	}
+
+	for _, order := range orders {
+		j.pipeline.SendEvent(ctx, domain.EventStatusChange, audit.StatusChanged(order.ID, domain.StatusReturnedToCourier))
+		metrics.IncOrderReturns()
+	}
+
+	j.logger.Infow("expired orders returned to courier", "count", len(orders))
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	repository "gitlab.ozon.dev/sadsnake2311/homework/internal/repository/auditeventrepo"
+)
+
+// AuditEventService сохраняет события аудита, прочитанные consumer'ом из Kafka.
+type AuditEventService interface {
+	SaveEvents(ctx context.Context, events []domain.AuditEvent) (int, error)
+}
+
+type auditEventService struct {
+	repo repository.AuditEventRepository
+}
+
+func NewAuditEventService(repo repository.AuditEventRepository) AuditEventService {
+	return &auditEventService{repo: repo}
+}
+
+// SaveEvents возвращает число новых событий; остальные уже были сохранены.
+func (s *auditEventService) SaveEvents(ctx context.Context, events []domain.AuditEvent) (int, error) {
+	return s.repo.SaveEvents(ctx, events)
+}
@@ -2,52 +2,92 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"encoding/hex"
 	"encoding/json"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/hashchain"
 	repository "gitlab.ozon.dev/sadsnake2311/homework/internal/repository/auditlogrepo"
 )
 
 type AuditService interface {
 	SaveLog(ctx context.Context, event domain.Event) error
-	BeginTx(ctx context.Context) (pgx.Tx, error)
-	FetchPendingTasksTx(ctx context.Context, tx pgx.Tx, limit int) ([]domain.AuditTask, error)
+	SaveLogs(ctx context.Context, events []domain.Event) error
+	ClaimPendingTasks(ctx context.Context, limit int, lease time.Duration) ([]domain.AuditTask, error)
+	FinishTasks(ctx context.Context, ids []int) error
+	ListenNewTasks(ctx context.Context, notify func()) error
+	OldestPendingTaskAge(ctx context.Context) (time.Duration, error)
 	UpdateTask(ctx context.Context, task domain.AuditTask) error
+	GetEvents(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, string, error)
+	ListDeadTasks(ctx context.Context, limit int, cursor *int) ([]domain.AuditTask, string, error)
+	GetTask(ctx context.Context, id int) (domain.AuditTask, error)
+	RequeueDeadTasks(ctx context.Context, ids []int) (int64, error)
+	CountDeadTasks(ctx context.Context) (int, error)
+	VerifyChain(ctx context.Context) (domain.AuditChainReport, error)
 }
 
 type auditService struct {
-	repo repository.AuditRepository
+	repo  repository.AuditRepository
+	chain *hashchain.Chain
 }
 
-func NewAuditService(repo repository.AuditRepository) AuditService {
-	return &auditService{repo: repo}
+func NewAuditService(repo repository.AuditRepository, chain *hashchain.Chain) AuditService {
+	return &auditService{repo: repo, chain: chain}
 }
 
 func (s *auditService) SaveLog(ctx context.Context, event domain.Event) error {
-	auditLogJSON, err := json.Marshal(event)
+	auditTask, err := newAuditTask(event)
 	if err != nil {
 		return err
 	}
 
-	auditTask := domain.AuditTask{
+	return s.repo.SaveLog(ctx, auditTask)
+}
+
+func (s *auditService) SaveLogs(ctx context.Context, events []domain.Event) error {
+	auditTasks := make([]domain.AuditTask, 0, len(events))
+	for _, event := range events {
+		auditTask, err := newAuditTask(event)
+		if err != nil {
+			return err
+		}
+		auditTasks = append(auditTasks, auditTask)
+	}
+
+	return s.repo.SaveLogs(ctx, auditTasks)
+}
+
+func newAuditTask(event domain.Event) (domain.AuditTask, error) {
+	auditLogJSON, err := json.Marshal(event)
+	if err != nil {
+		return domain.AuditTask{}, err
+	}
+
+	return domain.AuditTask{
 		AuditLog:      auditLogJSON,
 		Status:        "CREATED",
 		AttemptNumber: 0,
 		CreatedAt:     event.Time,
 		UpdatedAt:     event.Time,
-	}
+	}, nil
+}
 
-	return s.repo.SaveLog(ctx, auditTask)
+func (s *auditService) ClaimPendingTasks(ctx context.Context, limit int, lease time.Duration) ([]domain.AuditTask, error) {
+	return s.repo.ClaimPendingTasks(ctx, limit, lease)
+}
+
+func (s *auditService) FinishTasks(ctx context.Context, ids []int) error {
+	return s.repo.FinishTasks(ctx, ids, time.Now().UTC())
 }
 
-func (s *auditService) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	return s.repo.BeginTx(ctx)
+func (s *auditService) ListenNewTasks(ctx context.Context, notify func()) error {
+	return s.repo.ListenNewTasks(ctx, notify)
 }
 
-func (s *auditService) FetchPendingTasksTx(ctx context.Context, tx pgx.Tx, limit int) ([]domain.AuditTask, error) {
-	return s.repo.FetchPendingTasksTx(ctx, tx, limit)
+func (s *auditService) OldestPendingTaskAge(ctx context.Context) (time.Duration, error) {
+	return s.repo.OldestPendingTaskAge(ctx)
 }
 
 func (s *auditService) UpdateTask(ctx context.Context, task domain.AuditTask) error {
@@ -55,3 +95,122 @@ func (s *auditService) UpdateTask(ctx context.Context, task domain.AuditTask) er
 
 	return s.repo.UpdateTask(ctx, task)
 }
+
+const (
+	defaultAuditEventsLimit = 50
+	maxAuditEventsLimit     = 500
+)
+
+func (s *auditService) GetEvents(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, string, error) {
+	if filter.Limit < 0 || (!filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To)) {
+		return nil, "", domain.ErrInvalidAuditFilter
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditEventsLimit
+	}
+	if filter.Limit > maxAuditEventsLimit {
+		filter.Limit = maxAuditEventsLimit
+	}
+
+	return s.repo.GetEvents(ctx, filter)
+}
+
+func (s *auditService) ListDeadTasks(ctx context.Context, limit int, cursor *int) ([]domain.AuditTask, string, error) {
+	if limit <= 0 {
+		limit = defaultAuditEventsLimit
+	}
+	if limit > maxAuditEventsLimit {
+		limit = maxAuditEventsLimit
+	}
+
+	return s.repo.ListDeadTasks(ctx, limit, cursor)
+}
+
+func (s *auditService) GetTask(ctx context.Context, id int) (domain.AuditTask, error) {
+	return s.repo.GetTask(ctx, id)
+}
+
+func (s *auditService) RequeueDeadTasks(ctx context.Context, ids []int) (int64, error) {
+	return s.repo.RequeueDeadTasks(ctx, ids)
+}
+
+func (s *auditService) CountDeadTasks(ctx context.Context) (int, error) {
+	return s.repo.CountDeadTasks(ctx)
+}
+
+const chainPageSize = 1000
+
+// VerifyChain проходит цепочку хешей audit_tasks от первого звена и
+// останавливается на первом несовпадении. Задачи, сохраненные до появления
+// цепочки, пропускаются. Последнее звено сверяется с головой цепочки, иначе
+// удаление записей с конца было бы незаметно. Звенья, добавленные после
+// чтения головы, не проверяются.
+func (s *auditService) VerifyChain(ctx context.Context) (domain.AuditChainReport, error) {
+	head, err := s.repo.GetChainHead(ctx)
+	if err != nil {
+		return domain.AuditChainReport{}, err
+	}
+
+	var (
+		report  = domain.AuditChainReport{Head: hex.EncodeToString(head.Hash)}
+		prev    []byte
+		afterID int
+	)
+
+pages:
+	for {
+		records, err := s.repo.GetChainRecords(ctx, afterID, chainPageSize)
+		if err != nil {
+			return domain.AuditChainReport{}, err
+		}
+
+		for _, record := range records {
+			if record.ID > head.LastID {
+				break pages
+			}
+			afterID = record.ID
+			if report.FirstID == 0 {
+				if record.Hash == nil {
+					continue
+				}
+				report.FirstID = record.ID
+			}
+
+			report.Checked++
+			expected := s.chain.Link(prev, record.ID, record.CreatedAt, record.Data)
+			if !hmac.Equal(expected, record.Hash) {
+				reason := "хеш не совпадает: запись изменена или удалена предыдущая"
+				if record.Hash == nil {
+					reason = "у записи нет хеша: она добавлена в обход цепочки"
+				}
+				report.Broken = &domain.AuditChainBreak{
+					ID:       record.ID,
+					Reason:   reason,
+					Expected: hex.EncodeToString(expected),
+					Actual:   hex.EncodeToString(record.Hash),
+				}
+				return report, nil
+			}
+
+			report.LastID = record.ID
+			prev = record.Hash
+		}
+
+		if len(records) < chainPageSize {
+			break
+		}
+	}
+
+	if report.LastID != head.LastID || !hmac.Equal(prev, head.Hash) {
+		report.Broken = &domain.AuditChainBreak{
+			ID:       head.LastID,
+			Reason:   "последнее звено не совпадает с головой цепочки: удалены записи с конца",
+			Expected: hex.EncodeToString(head.Hash),
+			Actual:   hex.EncodeToString(prev),
+		}
+		return report, nil
+	}
+
+	report.Valid = true
+	return report, nil
+}
@@ -2,25 +2,39 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/cache"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/jwtkeys"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/authrepo"
 )
 
 type AuthService interface {
 	Register(ctx context.Context, user *domain.User) error
 	Login(ctx context.Context, user *domain.User) error
-	GenerateToken(email string) (string, error)
+	GenerateToken(user *domain.User) (string, error)
+	IssueTokens(ctx context.Context, user *domain.User) (domain.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (domain.TokenPair, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	ParseToken(ctx context.Context, tokenString string) (*domain.Claims, error)
+	AssignRole(ctx context.Context, email string, role domain.Role) error
+	JWKS() jwtkeys.JWKS
 }
 
 type authService struct {
-	repo *authrepo.AuthRepository
+	repo     *authrepo.AuthRepository
+	denyList cache.TokenDenyList
+	keys     *jwtkeys.KeySet
 }
 
-func NewAuthService(repo *authrepo.AuthRepository) AuthService {
-	return &authService{repo: repo}
+func NewAuthService(repo *authrepo.AuthRepository, denyList cache.TokenDenyList, keys *jwtkeys.KeySet) AuthService {
+	return &authService{repo: repo, denyList: denyList, keys: keys}
 }
 
 func (s *authService) Register(ctx context.Context, user *domain.User) error {
@@ -28,15 +42,144 @@ func (s *authService) Register(ctx context.Context, user *domain.User) error {
 }
 
 func (s *authService) Login(ctx context.Context, user *domain.User) error {
-	return s.repo.Login(ctx, user.Email, user.Password)
+	stored, err := s.repo.Login(ctx, user.Email, user.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Role = stored.Role
+	return nil
+}
+
+func (s *authService) AssignRole(ctx context.Context, email string, role domain.Role) error {
+	return s.repo.AssignRole(ctx, email, role)
+}
+
+func (s *authService) GenerateToken(user *domain.User) (string, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := domain.Claims{
+		Email: user.Email,
+		Role:  user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(domain.TokenExpiration)),
+		},
+	}
+
+	return s.keys.Sign(claims)
+}
+
+func (s *authService) JWKS() jwtkeys.JWKS {
+	return s.keys.JWKS()
 }
 
-func (s *authService) GenerateToken(email string) (string, error) {
-	claims := jwt.MapClaims{
-		"email": email,
-		"exp":   time.Now().Add(domain.TokenExpiration).Unix(),
+func (s *authService) IssueTokens(ctx context.Context, user *domain.User) (domain.TokenPair, error) {
+	accessToken, err := s.GenerateToken(user)
+	if err != nil {
+		return domain.TokenPair{}, fmt.Errorf("%w: %v", domain.ErrTokenGeneration, err)
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return domain.TokenPair{}, fmt.Errorf("%w: %v", domain.ErrTokenGeneration, err)
+	}
+
+	now := time.Now().UTC()
+	err = s.repo.SaveRefreshToken(ctx, domain.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		Email:     user.Email,
+		ExpiresAt: now.Add(domain.RefreshTokenExpiration),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	return domain.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (domain.TokenPair, error) {
+	if refreshToken == "" {
+		return domain.TokenPair{}, domain.ErrInvalidRefreshToken
+	}
+
+	email, err := s.repo.ConsumeRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	// Роль берется из БД, чтобы новый access токен учитывал ее изменения
+	user, err := s.repo.GetUser(ctx, email)
+	if err != nil {
+		return domain.TokenPair{}, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(domain.JwtSecret))
+	return s.IssueTokens(ctx, user)
+}
+
+func (s *authService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	if refreshToken != "" {
+		if err := s.repo.RevokeRefreshToken(ctx, hashToken(refreshToken)); err != nil {
+			return err
+		}
+	}
+
+	if accessToken == "" {
+		return nil
+	}
+
+	claims := &domain.Claims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, s.keys.Keyfunc)
+	if err != nil || claims.ID == "" || claims.ExpiresAt == nil {
+		// Просроченный или поддельный токен отзывать не нужно
+		return nil
+	}
+
+	if err := s.denyList.Revoke(ctx, claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrCache, err)
+	}
+	return nil
+}
+
+// ParseToken проверяет подпись и срок действия access токена, а также
+// то, что он не был отозван. Если deny-list недоступен, токен отклоняется.
+func (s *authService) ParseToken(ctx context.Context, tokenString string) (*domain.Claims, error) {
+	claims := &domain.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.keys.Keyfunc)
+	if err != nil || !token.Valid {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if claims.ID == "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	revoked, err := s.denyList.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrCache, err)
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/cache"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+// IdempotencyService сохраняет первый ответ на запрос с ключом
+// идемпотентности и отдает его на повторы. Ключи разных пользователей не
+// пересекаются.
+type IdempotencyService interface {
+	// Begin возвращает сохраненный ответ, если запрос уже выполнялся, или
+	// nil и токен резервирования ключа, если его нужно выполнить. Ключ,
+	// использованный для другого запроса или занятый выполняющимся
+	// запросом, — ошибка.
+	Begin(ctx context.Context, req domain.IdempotentRequest) (*domain.IdempotentResponse, string, error)
+	// Finish сохраняет ответ выполненного запроса. Если резервирование с
+	// токеном token истекло, ответ не сохраняется: ключ мог занять повтор.
+	Finish(ctx context.Context, req domain.IdempotentRequest, token string, response domain.IdempotentResponse) error
+	// Abort освобождает ключ, чтобы запрос можно было повторить, например
+	// после внутренней ошибки. Чужое резервирование не освобождается.
+	Abort(ctx context.Context, req domain.IdempotentRequest, token string) error
+}
+
+type idempotencyService struct {
+	store   cache.IdempotencyStore
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// NewIdempotencyService: ответы хранятся ttl, ключ выполняющегося запроса
+// занят не дольше lockTTL, чтобы упавший запрос не блокировал повторы.
+func NewIdempotencyService(store cache.IdempotencyStore, ttl, lockTTL time.Duration) IdempotencyService {
+	return &idempotencyService{store: store, ttl: ttl, lockTTL: lockTTL}
+}
+
+// idempotencyFinishTimeout ограничивает сохранение ответа и снятие
+// блокировки ключа после запроса.
+const idempotencyFinishTimeout = 2 * time.Second
+
+// IdempotencyFinishContext — контекст для Finish и Abort, который не
+// отменяется вместе с запросом. Клиент, не дождавшийся ответа, уже ушел, но
+// изменение сохранено: если не записать ответ, ключ останется занят до
+// истечения блокировки, а повтор выполнит запрос заново.
+func IdempotencyFinishContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), idempotencyFinishTimeout)
+}
+
+// IdempotencyFingerprint — хеш частей запроса для IdempotentRequest.
+func IdempotencyFingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, req domain.IdempotentRequest) (*domain.IdempotentResponse, string, error) {
+	if req.Key == "" || len(req.Key) > domain.MaxIdempotencyKeyLength {
+		return nil, "", domain.ErrInvalidIdempotencyKey
+	}
+
+	pending := domain.IdempotentResponse{Fingerprint: req.Fingerprint, Pending: true, Token: uuid.NewString()}
+	saved, err := s.store.Reserve(ctx, storeKey(ctx, req), pending, s.lockTTL)
+	if err != nil {
+		return nil, "", err
+	}
+	if saved == nil {
+		return nil, pending.Token, nil
+	}
+
+	if saved.Fingerprint != req.Fingerprint {
+		return nil, "", domain.ErrIdempotencyKeyReused
+	}
+	if saved.Pending {
+		return nil, "", domain.ErrIdempotencyInProgress
+	}
+	return saved, "", nil
+}
+
+func (s *idempotencyService) Finish(ctx context.Context, req domain.IdempotentRequest, token string, response domain.IdempotentResponse) error {
+	response.Fingerprint = req.Fingerprint
+	response.Pending = false
+	response.Token = ""
+	return s.store.Save(ctx, storeKey(ctx, req), token, response, s.ttl)
+}
+
+func (s *idempotencyService) Abort(ctx context.Context, req domain.IdempotentRequest, token string) error {
+	return s.store.Release(ctx, storeKey(ctx, req), token)
+}
+
+func storeKey(ctx context.Context, req domain.IdempotentRequest) string {
+	return req.Scope + ":" + domain.ActorFromContext(ctx) + ":" + req.Key
+}
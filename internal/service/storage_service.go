@@ -16,14 +16,16 @@ import (
 type OrderService interface {
 	AcceptOrder(ctx context.Context, order domain.Order) error
 	ReturnOrder(ctx context.Context, orderID string) error
-	IssueOrders(ctx context.Context, userID string, orderIDs []string) (*IssueRefundResponse, error)
-	RefundOrders(ctx context.Context, userID string, orderIDs []string) (*IssueRefundResponse, error)
+	IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (*IssueRefundResponse, error)
+	RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (*IssueRefundResponse, error)
 	GetUserOrders(ctx context.Context, userID string, limit int, cursor *int, status string) ([]OrderResponse, string, error)
 	GetRefundedOrders(ctx context.Context, limit int, cursor *int) ([]OrderResponse, string, error)
 	GetOrderHistory(ctx context.Context, limit int, lastUpdatedCursor time.Time, idCursor int) ([]OrderResponse, string, error)
 	GetUserActiveOrders(ctx context.Context, userID string) ([]domain.Order, error)
 	GetAllActiveOrders(ctx context.Context) ([]domain.Order, error)
 	GetOrderHistoryV2(ctx context.Context) ([]domain.Order, error)
+	GetOrderStatusHistory(ctx context.Context, orderID string) ([]domain.OrderStatusChange, error)
+	ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error)
 
 	CacheRefresh(ctx context.Context)
 	InitCache(ctx context.Context)
@@ -41,7 +43,7 @@ type OrderResponse struct {
 	ID          string               `json:"id"`
 	RecipientID string               `json:"recipient_id"`
 	Expiry      string               `json:"expiry"`
-	BasePrice   float64              `json:"base_price"`
+	BasePrice   domain.Money         `json:"base_price"`
 	Weight      float64              `json:"weight"`
 	Packaging   domain.PackagingType `json:"packaging"`
 	Status      string               `json:"status"`
@@ -51,9 +53,10 @@ type OrderResponse struct {
 }
 
 type IssueRefundResponse struct {
-	ProcessedOrderIDs []string `json:"processed_order_ids"`
-	FailedOrderIds    []string `json:"failed_order_ids"`
-	Error             string   `json:"error,omitempty"`
+	ProcessedOrderIDs []string             `json:"processed_order_ids"`
+	FailedOrderIds    []string             `json:"failed_order_ids"`
+	Error             error                `json:"-"`
+	Results           []domain.OrderResult `json:"results"`
 }
 
 func NewOrderService(
@@ -116,24 +119,27 @@ func (s *orderService) ReturnOrder(ctx context.Context, orderID string) error {
 	return nil
 }
 
-func (s *orderService) IssueOrders(ctx context.Context, userID string, orderIDs []string) (*IssueRefundResponse, error) {
-	result, err := s.userOrderRepo.IssueOrders(ctx, userID, orderIDs)
+func (s *orderService) IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (*IssueRefundResponse, error) {
+	result, err := s.userOrderRepo.IssueOrders(ctx, userID, orderIDs, atomic)
 	if err != nil {
 		return &IssueRefundResponse{}, err
 	}
 
-	for _, orderID := range result.OrderIDs {
-		order, err := s.cache.GetOrder(ctx, orderID)
+	for _, r := range result.Results {
+		if r.Status != domain.OrderResultProcessed {
+			continue
+		}
+		order, err := s.cache.GetOrder(ctx, r.OrderID)
 		if err != nil {
-			s.logger.Errorf("failed to get order %s from cache: %v", orderID, err)
+			s.logger.Errorf("failed to get order %s from cache: %v", r.OrderID, err)
 			continue
 		}
 		if order == nil {
 			continue
 		}
 
-		order.IssuedAt = new(time.Time)
-		*order.IssuedAt = time.Now()
+		order.IssuedAt = r.ChangedAt
+		order.State = domain.StatusIssued
 
 		if err := s.cache.SetOrder(ctx, *order); err != nil {
 			s.logger.Errorf("failed to update order %s in cache: %v", order.ID, err)
@@ -143,12 +149,13 @@ func (s *orderService) IssueOrders(ctx context.Context, userID string, orderIDs
 	return &IssueRefundResponse{
 		ProcessedOrderIDs: result.OrderIDs,
 		FailedOrderIds:    result.Failed,
-		Error:             errorToString(result.Error),
+		Error:             result.Error,
+		Results:           result.Results,
 	}, nil
 }
 
-func (s *orderService) RefundOrders(ctx context.Context, userID string, orderIDs []string) (*IssueRefundResponse, error) {
-	result, err := s.userOrderRepo.RefundOrders(ctx, userID, orderIDs)
+func (s *orderService) RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (*IssueRefundResponse, error) {
+	result, err := s.userOrderRepo.RefundOrders(ctx, userID, orderIDs, atomic)
 	if err != nil {
 		return &IssueRefundResponse{}, err
 	}
@@ -165,7 +172,8 @@ func (s *orderService) RefundOrders(ctx context.Context, userID string, orderIDs
 	return &IssueRefundResponse{
 		ProcessedOrderIDs: result.OrderIDs,
 		FailedOrderIds:    result.Failed,
-		Error:             errorToString(result.Error),
+		Error:             result.Error,
+		Results:           result.Results,
 	}, nil
 }
 
@@ -229,6 +237,51 @@ func (s *orderService) GetOrderHistoryV2(ctx context.Context) ([]domain.Order, e
 	return result, nil
 }
 
+func (s *orderService) GetOrderStatusHistory(ctx context.Context, orderID string) ([]domain.OrderStatusChange, error) {
+	return s.orderRepo.GetStatusHistory(ctx, orderID)
+}
+
+func (s *orderService) ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error) {
+	orders, err := s.orderRepo.ReturnExpiredOrders(ctx, limit, dryRun)
+	if err != nil || dryRun || len(orders) == 0 {
+		return orders, err
+	}
+
+	returnedByUser := make(map[string]map[string]struct{})
+	for _, order := range orders {
+		if err := s.cache.DeleteOrder(ctx, order.ID); err != nil {
+			s.logger.Errorf("failed to delete order %s from cache: %v", order.ID, err)
+		}
+		if returnedByUser[order.RecipientID] == nil {
+			returnedByUser[order.RecipientID] = make(map[string]struct{})
+		}
+		returnedByUser[order.RecipientID][order.ID] = struct{}{}
+	}
+
+	for userID, returned := range returnedByUser {
+		activeIDs, err := s.cache.GetUserActiveOrders(ctx, userID)
+		if err != nil {
+			s.logger.Errorf("failed to get the orders of user %s from cache: %v", userID, err)
+			continue
+		}
+		newActiveIDs := make([]string, 0, len(activeIDs))
+		for _, id := range activeIDs {
+			if _, ok := returned[id]; !ok {
+				newActiveIDs = append(newActiveIDs, id)
+			}
+		}
+		if err := s.cache.UpdateUserActiveOrders(ctx, userID, newActiveIDs); err != nil {
+			s.logger.Errorf("failed to update in cache: %v", err)
+		}
+	}
+
+	if err := s.cache.RefreshActiveOrders(ctx); err != nil {
+		s.logger.Errorf("failed to refresh active orders in cache: %v", err)
+	}
+
+	return orders, nil
+}
+
 func (s *orderService) GetUserActiveOrders(ctx context.Context, userID string) ([]domain.Order, error) {
 	startTime := time.Now()
 	defer func() {
@@ -413,10 +466,3 @@ func (s *orderService) mapOrdersToResponses(orders []domain.Order) []OrderRespon
 	}
 	return responses
 }
-
-func errorToString(err error) string {
-	if err != nil {
-		return err.Error()
-	}
-	return ""
-}
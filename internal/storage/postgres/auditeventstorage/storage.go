@@ -0,0 +1,126 @@
+package auditeventstorage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+// AuditEventStorage хранит события аудита, прочитанные из Kafka, в таблице
+// audit_events с партициями по месяцам.
+type AuditEventStorage struct {
+	db *pgxpool.Pool
+
+	mu         sync.Mutex
+	partitions map[string]struct{}
+}
+
+func NewAuditEventStorage(db *pgxpool.Pool) *AuditEventStorage {
+	return &AuditEventStorage{
+		db:         db,
+		partitions: make(map[string]struct{}),
+	}
+}
+
+// SaveEvents сохраняет события и возвращает, сколько из них записано.
+// Событие с уже сохраненным ID задачи пропускается, поэтому повторная
+// доставка записи из Kafka не создает дублей.
+func (s *AuditEventStorage) SaveEvents(ctx context.Context, events []domain.AuditEvent) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	var (
+		ids        = make([]int, 0, len(events))
+		types      = make([]string, 0, len(events))
+		eventIDs   = make([]string, 0, len(events))
+		versions   = make([]int, 0, len(events))
+		data       = make([]string, 0, len(events))
+		times      = make([]time.Time, 0, len(events))
+		actors     = make([]string, 0, len(events))
+		requestIDs = make([]string, 0, len(events))
+		traceIDs   = make([]string, 0, len(events))
+		clientIPs  = make([]string, 0, len(events))
+		userAgents = make([]string, 0, len(events))
+	)
+	for _, event := range events {
+		if err := s.ensurePartition(ctx, event.Time); err != nil {
+			return 0, err
+		}
+
+		payload := string(event.Data)
+		if payload == "" {
+			payload = "null"
+		}
+
+		ids = append(ids, event.ID)
+		types = append(types, string(event.Type))
+		eventIDs = append(eventIDs, event.EventID)
+		versions = append(versions, event.SchemaVersion)
+		data = append(data, payload)
+		times = append(times, event.Time.UTC())
+		actors = append(actors, event.User)
+		requestIDs = append(requestIDs, event.RequestID)
+		traceIDs = append(traceIDs, event.TraceID)
+		clientIPs = append(clientIPs, event.ClientIP)
+		userAgents = append(userAgents, event.UserAgent)
+	}
+
+	query := `
+		INSERT INTO audit_events
+		(task_id, type, event_id, schema_version, data, event_time,
+		 actor, request_id, trace_id, client_ip, user_agent)
+		SELECT id, type, NULLIF(event_id, '')::uuid, schema_version, data::jsonb, event_time,
+		       NULLIF(actor, ''), NULLIF(request_id, ''), NULLIF(trace_id, ''),
+		       NULLIF(client_ip, ''), NULLIF(user_agent, '')
+		FROM unnest(
+			$1::int[], $2::text[], $3::text[], $4::int[], $5::text[], $6::timestamp[],
+			$7::text[], $8::text[], $9::text[], $10::text[], $11::text[]
+		) AS e(id, type, event_id, schema_version, data, event_time,
+		       actor, request_id, trace_id, client_ip, user_agent)
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := s.db.Exec(ctx, query,
+		ids, types, eventIDs, versions, data, times, actors, requestIDs, traceIDs, clientIPs, userAgents,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ensurePartition создает партицию месяца, в который попадает t, если ее
+// еще нет. Созданные партиции запоминаются, чтобы не ходить в базу на
+// каждое событие.
+func (s *AuditEventStorage) ensurePartition(ctx context.Context, t time.Time) error {
+	t = t.UTC()
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	name := "audit_events_" + from.Format("2006_01")
+
+	s.mu.Lock()
+	_, exists := s.partitions[name]
+	s.mu.Unlock()
+	if exists {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF audit_events FOR VALUES FROM ('%s') TO ('%s')`,
+		name,
+		from.Format(time.DateOnly),
+		from.AddDate(0, 1, 0).Format(time.DateOnly),
+	)
+	if _, err := s.db.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to create partition %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	s.partitions[name] = struct{}{}
+	s.mu.Unlock()
+	return nil
+}
@@ -2,61 +2,293 @@ package auditlogstorage
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/hashchain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage/postgres/storageutils"
 )
 
 type AuditLogStorage struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	chain *hashchain.Chain
 }
 
-func NewAuditStorage(db *pgxpool.Pool) *AuditLogStorage {
-	return &AuditLogStorage{db: db}
+func NewAuditStorage(db *pgxpool.Pool, chain *hashchain.Chain) *AuditLogStorage {
+	return &AuditLogStorage{db: db, chain: chain}
 }
 
+// NewTaskChannel — канал NOTIFY, в который пишется число новых задач.
+const NewTaskChannel = storageutils.OutboxChannel
+
 func (s *AuditLogStorage) SaveLog(ctx context.Context, auditTask domain.AuditTask) error {
+	return s.SaveLogs(ctx, []domain.AuditTask{auditTask})
+}
+
+// SaveLogs сохраняет пачку задач одним COPY, связывает их в цепочку хешей и
+// отправляет одно уведомление в той же транзакции, поэтому обработчики
+// outbox проснутся только после коммита.
+//
+// Запись в цепочку упорядочивает блокировка строки audit_chain_head: id
+// задачам выдаются под ней, поэтому звенья идут в порядке id.
+func (s *AuditLogStorage) SaveLogs(ctx context.Context, auditTasks []domain.AuditTask) error {
+	if len(auditTasks) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var prevHash []byte
+	if err := tx.QueryRow(ctx, `
+		UPDATE audit_chain_head
+		SET updated_at = NOW()
+		RETURNING hash
+	`).Scan(&prevHash); err != nil {
+		return err
+	}
+
+	logs := make([]string, 0, len(auditTasks))
+	for _, task := range auditTasks {
+		logs = append(logs, string(task.AuditLog))
+	}
+
+	// Хеш считается от того, что сохранит база: jsonb нормализует документ,
+	// поэтому он приводится к jsonb вместе с выдачей id
+	ids, normalized, err := reserveTaskIDs(ctx, tx, logs)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, 0, len(auditTasks))
+	for i, task := range auditTasks {
+		createdAt := storedTime(task.CreatedAt)
+		prevHash = s.chain.Link(prevHash, ids[i], createdAt, []byte(normalized[i]))
+		rows = append(rows, []any{
+			ids[i],
+			normalized[i],
+			task.Status,
+			task.AttemptNumber,
+			createdAt,
+			task.UpdatedAt,
+			prevHash,
+		})
+	}
+
+	if _, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"audit_tasks"},
+		[]string{"id", "audit_log", "status", "attempt_number", "created_at", "updated_at", "hash"},
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE audit_chain_head SET last_id = $1, hash = $2",
+		ids[len(ids)-1], prevHash,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT pg_notify('"+NewTaskChannel+"', $1)", strconv.Itoa(len(auditTasks))); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// reserveTaskIDs выдает id из последовательности audit_tasks по возрастанию
+// и возвращает документы в том виде, в каком их сохранит jsonb.
+func reserveTaskIDs(ctx context.Context, tx pgx.Tx, logs []string) ([]int, []string, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT nextval(pg_get_serial_sequence('audit_tasks', 'id')), log::jsonb::text
+		FROM unnest($1::text[]) WITH ORDINALITY AS v(log, n)
+		ORDER BY n
+	`, logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, len(logs))
+	normalized := make([]string, 0, len(logs))
+	for rows.Next() {
+		var (
+			id  int
+			log string
+		)
+		if err := rows.Scan(&id, &log); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		normalized = append(normalized, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Порядок вычисления nextval не гарантирован, звенья же идут по id
+	slices.Sort(ids)
+	return ids, normalized, nil
+}
+
+// storedTime возвращает время в том виде, в каком его вернет колонка
+// TIMESTAMP: без часового пояса и с точностью до микросекунд.
+func storedTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).
+		Truncate(time.Microsecond)
+}
+
+// GetChainHead возвращает голову цепочки хешей: id и хеш последнего звена.
+func (s *AuditLogStorage) GetChainHead(ctx context.Context) (domain.AuditChainHead, error) {
+	var head domain.AuditChainHead
+	err := s.db.QueryRow(ctx, "SELECT last_id, hash FROM audit_chain_head").Scan(&head.LastID, &head.Hash)
+	return head, err
+}
+
+// GetChainRecords возвращает задачи с id больше afterID в порядке цепочки.
+// События заказов (задачи с topic) в цепочку не входят.
+func (s *AuditLogStorage) GetChainRecords(ctx context.Context, afterID, limit int) ([]domain.AuditChainRecord, error) {
+	return scanChainRecords(s.db.Query(ctx, `
+		SELECT id, COALESCE(created_at, '0001-01-01'::timestamp), audit_log::text, hash
+		FROM audit_tasks
+		WHERE id > $1 AND topic IS NULL
+		ORDER BY id
+		LIMIT $2
+	`, afterID, limit))
+}
+
+func scanChainRecords(rows pgx.Rows, err error) ([]domain.AuditChainRecord, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.AuditChainRecord
+	for rows.Next() {
+		var (
+			record domain.AuditChainRecord
+			data   string
+		)
+		if err := rows.Scan(&record.ID, &record.CreatedAt, &data, &record.Hash); err != nil {
+			return nil, err
+		}
+		record.Data = []byte(data)
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// ClaimPendingTasks забирает задачи в обработку: переводит их в PROCESSING
+// и ставит next_retry на время аренды. Задача, которую обработчик не
+// завершил до конца аренды (например, упал процесс), снова станет доступна.
+//
+// Задача с ключом (событие заказа) не забирается, пока не отправлены более
+// ранние задачи с тем же ключом, иначе параллельные обработчики и повторы
+// могли бы переставить события одного заказа. Задачи без оставшихся попыток
+// очередь не держат.
+func (s *AuditLogStorage) ClaimPendingTasks(ctx context.Context, limit int, lease time.Duration) ([]domain.AuditTask, error) {
 	query := `
-        INSERT INTO audit_tasks 
-        (audit_log, status, attempt_number, created_at, updated_at) 
-        VALUES ($1, $2, $3, $4, $5)
-    `
-	_, err := s.db.Exec(ctx, query,
-		auditTask.AuditLog,
-		auditTask.Status,
-		auditTask.AttemptNumber,
-		auditTask.CreatedAt,
-		auditTask.UpdatedAt,
-	)
+		UPDATE audit_tasks
+		SET status = 'PROCESSING',
+		    updated_at = NOW(),
+		    next_retry = NOW() + $2::interval
+		WHERE id IN (
+			SELECT id
+			FROM audit_tasks AS t
+			WHERE status IN ('CREATED', 'FAILED', 'PROCESSING')
+			  AND (next_retry IS NULL OR next_retry < NOW())
+			  AND (message_key IS NULL OR NOT EXISTS (
+				SELECT 1
+				FROM audit_tasks AS prev
+				WHERE prev.message_key = t.message_key
+				  AND prev.id < t.id
+				  AND prev.status IN ('CREATED', 'FAILED', 'PROCESSING')
+			  ))
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + taskColumns
+
+	rows, err := s.db.Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+func (s *AuditLogStorage) FinishTasks(ctx context.Context, ids []int, finishedAt time.Time) error {
+	query := `
+		UPDATE audit_tasks
+		SET status = 'FINISHED',
+		    updated_at = $2,
+		    finished_at = $2
+		WHERE id = ANY($1)
+	`
+	_, err := s.db.Exec(ctx, query, ids, finishedAt)
 	return err
 }
 
-func (s *AuditLogStorage) BeginTx(ctx context.Context) (pgx.Tx, error) {
-	return s.db.Begin(ctx)
+// ListenNewTasks держит отдельное соединение с LISTEN и вызывает notify на
+// каждое уведомление о новой задаче. Возвращается при ошибке соединения
+// или отмене контекста.
+func (s *AuditLogStorage) ListenNewTasks(ctx context.Context, notify func()) error {
+	poolConn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Соединение с LISTEN не возвращается в пул
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+NewTaskChannel); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		notify()
+	}
 }
 
-func (s *AuditLogStorage) FetchPendingTasksTx(ctx context.Context, tx pgx.Tx, limit int) ([]domain.AuditTask, error) {
+// OldestPendingTaskAge возвращает возраст самой старой неотправленной задачи.
+func (s *AuditLogStorage) OldestPendingTaskAge(ctx context.Context) (time.Duration, error) {
 	query := `
-		SELECT id, audit_log, status, attempt_number,
-		       created_at, updated_at,
-		       COALESCE(finished_at, '0001-01-01'::timestamp),
-		       COALESCE(next_retry, '0001-01-01'::timestamp)
+		SELECT COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at)), 0)
 		FROM audit_tasks
-		WHERE status IN ('CREATED', 'FAILED')
-		  AND (next_retry IS NULL OR next_retry < NOW())
-		  AND attempt_number < 3
-		ORDER BY created_at
-		LIMIT $1
-		FOR UPDATE SKIP LOCKED
+		WHERE status IN ('CREATED', 'FAILED', 'PROCESSING')
 	`
 
-	rows, err := tx.Query(ctx, query, limit)
-	if err != nil {
-		return nil, err
+	var seconds float64
+	if err := s.db.QueryRow(ctx, query).Scan(&seconds); err != nil {
+		return 0, err
 	}
-	defer rows.Close()
+	return time.Duration(seconds * float64(time.Second)), nil
+}
 
+const taskColumns = `
+	id, audit_log, status, attempt_number,
+	created_at, updated_at,
+	COALESCE(finished_at, '0001-01-01'::timestamp),
+	COALESCE(next_retry, '0001-01-01'::timestamp),
+	COALESCE(topic, ''), COALESCE(message_key, '')
+`
+
+func scanTasks(rows pgx.Rows) ([]domain.AuditTask, error) {
 	var tasks []domain.AuditTask
 	for rows.Next() {
 		var task domain.AuditTask
@@ -69,13 +301,15 @@ func (s *AuditLogStorage) FetchPendingTasksTx(ctx context.Context, tx pgx.Tx, li
 			&task.UpdatedAt,
 			&task.FinishedAt,
 			&task.NextRetry,
+			&task.Topic,
+			&task.MessageKey,
 		); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, task)
 	}
 
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 func (s *AuditLogStorage) UpdateTask(ctx context.Context, task domain.AuditTask) error {
@@ -107,3 +341,179 @@ func (s *AuditLogStorage) UpdateTask(ctx context.Context, task domain.AuditTask)
 
 	return tx.Commit(ctx)
 }
+
+// GetEvents возвращает события аудита от новых к старым. Условия собираются
+// динамически, чтобы планировщик мог использовать индексы по полям JSONB.
+func (s *AuditLogStorage) GetEvents(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, string, error) {
+	var (
+		// События заказов лежат в той же таблице outbox, но в журнал не входят
+		conditions = []string{"topic IS NULL"}
+		args       []any
+	)
+	addCondition := func(expr string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(expr, len(args)))
+	}
+
+	if filter.Type != "" {
+		addCondition("audit_log->>'Type' = $%d", string(filter.Type))
+	}
+	if filter.OrderID != "" {
+		addCondition("audit_log->'Data'->>'order_id' = $%d", filter.OrderID)
+	}
+	if filter.User != "" {
+		addCondition("audit_log->>'User' = $%d", filter.User)
+	}
+	if filter.RequestID != "" {
+		addCondition("audit_log->>'RequestID' = $%d", filter.RequestID)
+	}
+	if filter.Path != "" {
+		addCondition("audit_log->'Data'->>'path' = $%d", filter.Path)
+	}
+	if filter.Status != "" {
+		addCondition("audit_log->'Data'->>'status' = $%d", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+	if filter.Cursor != nil {
+		addCondition("id < $%d", *filter.Cursor)
+	}
+
+	query := `
+		SELECT id, audit_log->>'Type',
+		       COALESCE(audit_log->>'EventID', ''),
+		       COALESCE((audit_log->>'SchemaVersion')::int, 0),
+		       audit_log->'Data', created_at,
+		       COALESCE(audit_log->>'User', ''),
+		       COALESCE(audit_log->>'RequestID', ''),
+		       COALESCE(audit_log->>'TraceID', ''),
+		       COALESCE(audit_log->>'ClientIP', ''),
+		       COALESCE(audit_log->>'UserAgent', '')
+		FROM audit_tasks
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.AuditEvent
+	for rows.Next() {
+		var event domain.AuditEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.EventID,
+			&event.SchemaVersion,
+			&event.Data,
+			&event.Time,
+			&event.User,
+			&event.RequestID,
+			&event.TraceID,
+			&event.ClientIP,
+			&event.UserAgent,
+		); err != nil {
+			return nil, "", fmt.Errorf("ошибка скана: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("ошибка скана: %w", err)
+	}
+
+	var nextCursor string
+	if len(events) == filter.Limit {
+		nextCursor = strconv.Itoa(events[len(events)-1].ID)
+	}
+
+	return events, nextCursor, nil
+}
+
+func (s *AuditLogStorage) ListDeadTasks(ctx context.Context, limit int, cursor *int) ([]domain.AuditTask, string, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM audit_tasks
+		WHERE status = 'NO_ATTEMPTS_LEFT'
+		  AND ($1::INT IS NULL OR id < $1)
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := s.db.Query(ctx, query, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer rows.Close()
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка скана: %w", err)
+	}
+
+	var nextCursor string
+	if len(tasks) == limit {
+		nextCursor = strconv.Itoa(tasks[len(tasks)-1].ID)
+	}
+
+	return tasks, nextCursor, nil
+}
+
+func (s *AuditLogStorage) GetTask(ctx context.Context, id int) (domain.AuditTask, error) {
+	query := `SELECT ` + taskColumns + ` FROM audit_tasks WHERE id = $1`
+
+	rows, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return domain.AuditTask{}, err
+	}
+	defer rows.Close()
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return domain.AuditTask{}, err
+	}
+	if len(tasks) == 0 {
+		return domain.AuditTask{}, pgx.ErrNoRows
+	}
+
+	return tasks[0], nil
+}
+
+// RequeueDeadTasks возвращает задачи без оставшихся попыток в очередь.
+// Пустой список ids означает все такие задачи.
+func (s *AuditLogStorage) RequeueDeadTasks(ctx context.Context, ids []int) (int64, error) {
+	query := `
+		UPDATE audit_tasks
+		SET status = 'CREATED',
+		    attempt_number = 0,
+		    next_retry = NULL,
+		    updated_at = NOW()
+		WHERE status = 'NO_ATTEMPTS_LEFT'
+		  AND (cardinality($1::BIGINT[]) = 0 OR id = ANY($1))
+	`
+
+	if ids == nil {
+		ids = []int{}
+	}
+
+	tag, err := s.db.Exec(ctx, query, ids)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *AuditLogStorage) CountDeadTasks(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM audit_tasks WHERE status = 'NO_ATTEMPTS_LEFT'`).Scan(&count)
+	return count, err
+}
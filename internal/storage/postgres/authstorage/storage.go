@@ -18,16 +18,16 @@ func NewAuthStorage(db *pgxpool.Pool) *AuthStorage {
 
 func (s *AuthStorage) CreateUser(ctx context.Context, user *domain.User) error {
 	query := `
-        INSERT INTO users (email, password) 
-        VALUES ($1, $2)`
+        INSERT INTO users (email, password, role) 
+        VALUES ($1, $2, $3)`
 
-	_, err := s.db.Exec(ctx, query, user.Email, user.Password)
+	_, err := s.db.Exec(ctx, query, user.Email, user.Password, user.Role)
 	return err
 }
 
 func (s *AuthStorage) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-        SELECT email, password 
+        SELECT email, password, role 
         FROM users 
         WHERE email = $1`
 
@@ -35,6 +35,7 @@ func (s *AuthStorage) GetUserByEmail(ctx context.Context, email string) (*domain
 	err := s.db.QueryRow(ctx, query, email).Scan(
 		&user.Email,
 		&user.Password,
+		&user.Role,
 	)
 
 	if err != nil {
@@ -45,3 +46,83 @@ func (s *AuthStorage) GetUserByEmail(ctx context.Context, email string) (*domain
 	}
 	return &user, nil
 }
+
+func (s *AuthStorage) UpdateUserRole(ctx context.Context, email string, role domain.Role) error {
+	query := `
+        UPDATE users 
+        SET role = $1 
+        WHERE email = $2`
+
+	result, err := s.db.Exec(ctx, query, role, email)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *AuthStorage) SaveRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	query := `
+        INSERT INTO refresh_tokens (token_hash, user_email, expires_at, created_at) 
+        VALUES ($1, $2, $3, $4)`
+
+	_, err := s.db.Exec(ctx, query, token.TokenHash, token.Email, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+// ConsumeRefreshToken отзывает действующий refresh токен и возвращает его
+// владельца. Если токен уже был отозван, возвращается ErrTokenRevoked вместе с
+// причиной отзыва: повторное использование обменянного токена — признак кражи.
+func (s *AuthStorage) ConsumeRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	query := `
+        UPDATE refresh_tokens 
+        SET revoked_at = NOW(), revoke_reason = $2
+        WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        RETURNING user_email`
+
+	token := domain.RefreshToken{TokenHash: tokenHash}
+	err := s.db.QueryRow(ctx, query, tokenHash, domain.RevokeRotated).Scan(&token.Email)
+	if err == nil {
+		return token, nil
+	}
+	if err != pgx.ErrNoRows {
+		return domain.RefreshToken{}, err
+	}
+
+	query = `
+        SELECT user_email, revoke_reason
+        FROM refresh_tokens 
+        WHERE token_hash = $1 AND revoked_at IS NOT NULL`
+
+	err = s.db.QueryRow(ctx, query, tokenHash).Scan(&token.Email, &token.RevokeReason)
+	if err == nil {
+		return token, domain.ErrTokenRevoked
+	}
+	if err == pgx.ErrNoRows {
+		return domain.RefreshToken{}, domain.ErrInvalidRefreshToken
+	}
+	return domain.RefreshToken{}, err
+}
+
+func (s *AuthStorage) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	query := `
+        UPDATE refresh_tokens 
+        SET revoked_at = NOW(), revoke_reason = $2
+        WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	_, err := s.db.Exec(ctx, query, tokenHash, domain.RevokeLogout)
+	return err
+}
+
+func (s *AuthStorage) RevokeUserRefreshTokens(ctx context.Context, email string) error {
+	query := `
+        UPDATE refresh_tokens 
+        SET revoked_at = NOW(), revoke_reason = $2
+        WHERE user_email = $1 AND revoked_at IS NULL`
+
+	_, err := s.db.Exec(ctx, query, email, domain.RevokeReuse)
+	return err
+}
@@ -2,6 +2,7 @@ package orderstorage
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -10,11 +11,13 @@ import (
 )
 
 type OrderStorage struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	encode domain.OrderEventEncoder
 }
 
-func NewOrderStorage(db *pgxpool.Pool) *OrderStorage {
-	return &OrderStorage{db: db}
+// NewOrderStorage создает хранилище; encode кодирует события заказа для outbox.
+func NewOrderStorage(db *pgxpool.Pool, encode domain.OrderEventEncoder) *OrderStorage {
+	return &OrderStorage{db: db, encode: encode}
 }
 
 func (s *OrderStorage) SaveOrder(ctx context.Context, order domain.Order) error {
@@ -24,16 +27,25 @@ func (s *OrderStorage) SaveOrder(ctx context.Context, order domain.Order) error
 	}
 	defer tx.Rollback(ctx)
 
+	packagePrice, err := storageutils.Numeric(order.PackagePrice)
+	if err != nil {
+		return err
+	}
+	basePrice, err := storageutils.Numeric(order.BasePrice)
+	if err != nil {
+		return err
+	}
+
 	savePackagingQuery := `INSERT INTO packaging_types (id, packaging_price) VALUES ($1, $2)
                           ON CONFLICT (id) DO NOTHING`
-	if _, err := tx.Exec(ctx, savePackagingQuery, string(order.Packaging), order.PackagePrice); err != nil {
+	if _, err := tx.Exec(ctx, savePackagingQuery, string(order.Packaging), packagePrice); err != nil {
 		return err
 	}
 
 	saveOrderQuery := `INSERT INTO orders (
         order_id, recipient_id, expiry, stored_at, issued_at, refunded_at,
-        base_price, weight, packaging
-    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+        base_price, currency, weight, packaging, status
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	if _, err := tx.Exec(ctx, saveOrderQuery,
 		order.ID,
@@ -42,31 +54,71 @@ func (s *OrderStorage) SaveOrder(ctx context.Context, order domain.Order) error
 		order.StoredAt,
 		order.IssuedAt,
 		order.RefundedAt,
-		order.BasePrice,
+		basePrice,
+		string(order.BasePrice.Currency),
 		order.Weight,
 		order.Packaging,
+		domain.StatusStored,
 	); err != nil {
 		return err
 	}
 
+	change := domain.OrderStatusChange{
+		OrderID:   order.ID,
+		To:        domain.StatusStored,
+		Actor:     domain.ActorFromContext(ctx),
+		ChangedAt: *order.StoredAt,
+	}
+	if err := storageutils.SaveStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	order.State = domain.StatusStored
+	event := domain.NewOrderEvent(order, change)
+	if err := storageutils.SaveOrderEvents(ctx, tx, s.encode, []domain.OrderEvent{event}); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
-func (s *OrderStorage) DeleteOrder(ctx context.Context, id string) error {
+// ReturnOrder переводит заказ в статус returned_to_courier, записывает
+// переход в историю статусов и публикует событие возврата. Строка заказа
+// остается, как и при возврате по сроку хранения. Статус проверяется под
+// блокировкой строки: заказ могли выдать или вернуть после проверки в
+// репозитории.
+func (s *OrderStorage) ReturnOrder(ctx context.Context, id string) error {
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	query := `DELETE FROM orders WHERE order_id = $1`
-	result, err := tx.Exec(ctx, query, id)
+	query := `SELECT order_id, recipient_id, expiry,
+			stored_at, issued_at, refunded_at,
+			base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
+		FROM orders WHERE order_id = $1 FOR UPDATE`
+	order, err := storageutils.ScanOrder(tx.QueryRow(ctx, query, id))
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected := result.RowsAffected(); rowsAffected == 0 {
-		return domain.ErrNotFoundOrder
+	change, err := order.TransitionTo(ctx, domain.StatusReturnedToCourier, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status = $1 WHERE order_id = $2`, domain.StatusReturnedToCourier, id); err != nil {
+		return err
+	}
+
+	if err := storageutils.SaveStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	event := domain.NewOrderEvent(*order, change)
+	if err := storageutils.SaveOrderEvents(ctx, tx, s.encode, []domain.OrderEvent{event}); err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
@@ -76,7 +128,7 @@ func (s *OrderStorage) FindOrderByID(ctx context.Context, id string) (*domain.Or
 	query := `SELECT 
 			order_id, recipient_id, expiry,
 			stored_at, issued_at, refunded_at,
-			base_price, weight, packaging
+			base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 		FROM orders WHERE order_id = $1`
 	row := s.db.QueryRow(ctx, query, id)
 	return storageutils.ScanOrder(row)
@@ -86,7 +138,7 @@ func (s *OrderStorage) FindOrdersByIDs(ctx context.Context, ids []string) ([]*do
 	query := `SELECT 
 			order_id, recipient_id, expiry,
 			stored_at, issued_at, refunded_at,
-			base_price, weight, packaging
+			base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 		FROM orders WHERE order_id = ANY($1)`
 
 	rows, err := s.db.Query(ctx, query, ids)
@@ -110,3 +162,128 @@ func (s *OrderStorage) FindOrdersByIDs(ctx context.Context, ids []string) ([]*do
 
 	return orders, nil
 }
+
+func (s *OrderStorage) GetStatusHistory(ctx context.Context, id string) ([]domain.OrderStatusChange, error) {
+	query := `SELECT
+			order_id, COALESCE(from_status, ''), to_status,
+			COALESCE(actor, ''), changed_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY changed_at, id`
+
+	rows, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []domain.OrderStatusChange
+	for rows.Next() {
+		var change domain.OrderStatusChange
+		if err := rows.Scan(
+			&change.OrderID,
+			&change.From,
+			&change.To,
+			&change.Actor,
+			&change.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// ReturnExpiredOrders возвращает курьеру до limit просроченных заказов. В
+// режиме dryRun заказы только читаются, без блокировок и изменений.
+func (s *OrderStorage) ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error) {
+	query := `SELECT 
+			order_id, recipient_id, expiry,
+			stored_at, issued_at, refunded_at,
+			base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
+		FROM orders
+		WHERE status = $1 AND expiry < NOW()
+		ORDER BY expiry
+		LIMIT $2`
+
+	if dryRun {
+		rows, err := s.db.Query(ctx, query, domain.StatusStored, limit)
+		if err != nil {
+			return nil, err
+		}
+		return scanOrders(rows)
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, query+" FOR UPDATE SKIP LOCKED", domain.StatusStored, limit)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	now := time.Now().UTC()
+	ids := make([]string, 0, len(orders))
+	events := make([]domain.OrderEvent, 0, len(orders))
+	for i := range orders {
+		change, err := orders[i].TransitionTo(ctx, domain.StatusReturnedToCourier, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := storageutils.SaveStatusChange(ctx, tx, change); err != nil {
+			return nil, err
+		}
+		ids = append(ids, orders[i].ID)
+		events = append(events, domain.NewOrderEvent(orders[i], change))
+	}
+
+	updateQuery := `UPDATE orders SET status = $1 WHERE order_id = ANY($2)`
+	if _, err := tx.Exec(ctx, updateQuery, domain.StatusReturnedToCourier, ids); err != nil {
+		return nil, err
+	}
+
+	if err := storageutils.SaveOrderEvents(ctx, tx, s.encode, events); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// scanOrders читает и закрывает rows.
+func scanOrders(rows pgx.Rows) ([]domain.Order, error) {
+	defer rows.Close()
+
+	var orders []domain.Order
+	for rows.Next() {
+		order, err := storageutils.ScanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
@@ -30,18 +30,12 @@ func (s *ReportOrderStorage) GetUserOrders(
 	query := `SELECT 
 	order_id, recipient_id, expiry, 
 	stored_at, issued_at, refunded_at, 
-	base_price, weight, packaging
+	base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 	FROM orders
 	WHERE 
     recipient_id = $1 AND
     ($2::INT IS NULL OR id < $2) AND
-    ($4 = '' OR (
-            $4 = 'stored' AND 
-            stored_at IS NOT NULL AND
-            issued_at IS NULL AND
-            refunded_at IS NULL
-        )
-    )
+    ($4 = '' OR status = $4)
 	ORDER BY id DESC
 	LIMIT $3
     `
@@ -81,7 +75,7 @@ func (s *ReportOrderStorage) GetRefundedOrders(
 		SELECT 
 		order_id, recipient_id, expiry, 
 		stored_at, issued_at, refunded_at, 
-		base_price, weight, packaging
+		base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 		FROM orders
 		WHERE 
 			refunded_at IS NOT NULL AND
@@ -126,7 +120,7 @@ func (s *ReportOrderStorage) GetOrderHistory(
         SELECT 
     	order_id, recipient_id, expiry, 
     	stored_at, issued_at, refunded_at, 
-    	base_price, weight, packaging
+    	base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 		FROM orders
 		WHERE 
     	($1::timestamp = '0001-01-01' AND $2 = 0) OR  
@@ -270,7 +264,7 @@ func (s *ReportOrderStorage) GetAllOrders(ctx context.Context) ([]domain.Order,
 	SELECT 
 	order_id, recipient_id, expiry, 
     stored_at, issued_at, refunded_at, 
-    base_price, weight, packaging
+    base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 	FROM orders
 	`
 
@@ -1,15 +1,32 @@
 package storageutils
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/big"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 )
 
+// OutboxChannel — канал NOTIFY, в который пишется число новых задач outbox.
+const OutboxChannel = "audit_tasks_new"
+
+// PackagePriceColumn — цена упаковки заказа из packaging_types. Идет в
+// запросах сразу после колонки packaging, как ее ждет ScanOrder.
+const PackagePriceColumn = `(SELECT packaging_price FROM packaging_types WHERE packaging_types.id = orders.packaging)`
+
+// ScanOrder читает заказ из колонок order_id, recipient_id, expiry, stored_at,
+// issued_at, refunded_at, base_price, currency, weight, packaging,
+// PackagePriceColumn, status.
 func ScanOrder(row pgx.Row) (*domain.Order, error) {
 	var o domain.Order
+	var basePrice, packagePrice pgtype.Numeric
+	var currency string
 	err := row.Scan(
 		&o.ID,
 		&o.RecipientID,
@@ -17,9 +34,12 @@ func ScanOrder(row pgx.Row) (*domain.Order, error) {
 		&o.StoredAt,
 		&o.IssuedAt,
 		&o.RefundedAt,
-		&o.BasePrice,
+		&basePrice,
+		&currency,
 		&o.Weight,
 		&o.Packaging,
+		&packagePrice,
+		&o.State,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -29,5 +49,110 @@ func ScanOrder(row pgx.Row) (*domain.Order, error) {
 		return nil, fmt.Errorf("не смог отсканить заказ: %w", err)
 	}
 
+	o.BasePrice, err = MoneyFromNumeric(basePrice, domain.Currency(currency))
+	if err != nil {
+		return nil, fmt.Errorf("неверная цена заказа %s: %w", o.ID, err)
+	}
+
+	// Цены упаковок хранятся в рублях
+	o.PackagePrice, err = MoneyFromNumeric(packagePrice, domain.CurrencyRUB)
+	if err != nil {
+		return nil, fmt.Errorf("неверная цена упаковки заказа %s: %w", o.ID, err)
+	}
+
 	return &o, nil
 }
+
+// Numeric — сумма для колонки NUMERIC без перевода во float. Без известной
+// валюты число знаков после запятой неизвестно, такая сумма не пишется.
+func Numeric(m domain.Money) (pgtype.Numeric, error) {
+	if !m.Currency.Valid() {
+		return pgtype.Numeric{}, domain.ErrUnknownCurrency.With("currency", string(m.Currency))
+	}
+	return pgtype.Numeric{
+		Int:   big.NewInt(m.MinorUnits),
+		Exp:   -int32(m.Currency.Digits()),
+		Valid: true,
+	}, nil
+}
+
+// MoneyFromNumeric переводит значение колонки NUMERIC в минимальные единицы
+// валюты. Знаков после запятой не может быть больше, чем у валюты.
+func MoneyFromNumeric(n pgtype.Numeric, currency domain.Currency) (domain.Money, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite {
+		return domain.Money{}, domain.ErrInvalidMoney
+	}
+
+	units := new(big.Int).Set(n.Int)
+	exp := int64(n.Exp) + int64(currency.Digits())
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(exp)), nil)
+	if exp >= 0 {
+		units.Mul(units, scale)
+	} else {
+		var remainder big.Int
+		units.QuoRem(units, scale, &remainder)
+		if remainder.Sign() != 0 {
+			return domain.Money{}, domain.ErrInvalidMoney
+		}
+	}
+	if !units.IsInt64() {
+		return domain.Money{}, domain.ErrInvalidMoney
+	}
+
+	return domain.NewMoney(units.Int64(), currency)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func SaveStatusChange(ctx context.Context, tx pgx.Tx, change domain.OrderStatusChange) error {
+	query := `INSERT INTO order_status_history (
+		order_id, from_status, to_status, actor, changed_at
+	) VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5)`
+
+	_, err := tx.Exec(ctx, query,
+		change.OrderID,
+		string(change.From),
+		string(change.To),
+		change.Actor,
+		change.ChangedAt,
+	)
+	return err
+}
+
+// SaveOrderEvents кодирует события заказа encode и кладет их в outbox в
+// транзакции tx, чтобы они ушли в Kafka, только если изменение заказа
+// сохранилось. Ключ записи — ID заказа.
+func SaveOrderEvents(ctx context.Context, tx pgx.Tx, encode domain.OrderEventEncoder, events []domain.OrderEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO audit_tasks (
+		audit_log, status, attempt_number, created_at, updated_at, topic, message_key
+	) VALUES ($1, $2, 0, $3, $3, $4, $5)`
+
+	now := time.Now().UTC()
+	for _, event := range events {
+		payload, err := encode(event)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, query,
+			payload,
+			domain.StatusCreated,
+			now,
+			domain.OrderEventsTopic,
+			event.Change.OrderID,
+		); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(ctx, "SELECT pg_notify('"+OutboxChannel+"', $1)", strconv.Itoa(len(events)))
+	return err
+}
@@ -13,106 +13,107 @@ import (
 )
 
 type UserOrderStorage struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	encode domain.OrderEventEncoder
 }
 
-func NewUserOrderStorage(db *pgxpool.Pool) *UserOrderStorage {
-	return &UserOrderStorage{db: db}
+// NewUserOrderStorage создает хранилище; encode кодирует события заказа для outbox.
+func NewUserOrderStorage(db *pgxpool.Pool, encode domain.OrderEventEncoder) *UserOrderStorage {
+	return &UserOrderStorage{db: db, encode: encode}
 }
 
-func (s *UserOrderStorage) IssueOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error) {
+func (s *UserOrderStorage) IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error) {
+	return s.processOrders(ctx, userID, orderIDs, atomic, domain.StatusIssued, validateOrderForIssue, s.updateIssueTime)
+}
+
+func (s *UserOrderStorage) RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error) {
+	return s.processOrders(ctx, userID, orderIDs, atomic, domain.StatusRefunded, validateOrderForRefund, s.updateRefundTime)
+}
+
+// processOrders переводит заказы в статус next в одной транзакции.
+// Без atomic каждый заказ обрабатывается независимо: заказ, не прошедший
+// проверку, попадает в результаты с ошибкой, а остальные сохраняются. С
+// atomic проверяются все заказы и при любой ошибке транзакция откатывается
+// целиком. Повторы ID в запросе обрабатываются один раз.
+func (s *UserOrderStorage) processOrders(
+	ctx context.Context,
+	userID string,
+	orderIDs []string,
+	atomic bool,
+	next domain.OrderStatus,
+	validate func(o *domain.Order, userID string, now time.Time) error,
+	update func(ctx context.Context, tx pgx.Tx, id string, t time.Time) error,
+) (domain.ProcessedOrders, error) {
+	orderIDs = domain.UniqueOrderIDs(orderIDs)
+
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 	}
 	defer tx.Rollback(ctx)
 
-	processed := make([]string, 0)
+	batch := domain.NewOrderBatch(atomic, len(orderIDs))
+	events := make([]domain.OrderEvent, 0, len(orderIDs))
 	now := time.Now().UTC()
-	var returnErr error
 
 	for _, id := range orderIDs {
 		o, err := s.lockAndGetOrder(ctx, tx, id)
 		if err != nil {
-			if errors.Is(err, domain.ErrNotFoundOrder) {
-				returnErr = fmt.Errorf("%w: %s", domain.ErrNotFoundOrder, id)
-				continue
+			if !errors.Is(err, domain.ErrNotFoundOrder) {
+				return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 			}
-			return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
+			batch.Fail(id, fmt.Errorf("%w: %s", domain.ErrNotFoundOrder, id))
+			continue
 		}
 
-		if err := validateOrderForIssue(o, userID, now); err != nil {
-			returnErr = err
-			break
+		if err := validate(o, userID, now); err != nil {
+			batch.Fail(id, err)
+			continue
 		}
 
-		if err := s.updateIssueTime(ctx, tx, id, now); err != nil {
-			return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
+		// В режиме atomic после ошибки остальные заказы только
+		// проверяются, чтобы вернуть причины для всех
+		if !batch.CanApply() {
+			batch.Skip(id)
+			continue
 		}
 
-		processed = append(processed, id)
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
-	}
-
-	return domain.ProcessedOrders{
-		UserID:   userID,
-		OrderIDs: processed,
-		Failed:   getFailedOrders(orderIDs, processed),
-		Error:    returnErr,
-	}, nil
-}
-
-func (s *UserOrderStorage) RefundOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error) {
-	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
-	}
-	defer tx.Rollback(ctx)
-
-	processed := make([]string, 0)
-	now := time.Now().UTC()
-	var returnErr error
-
-	for _, id := range orderIDs {
-		o, err := s.lockAndGetOrder(ctx, tx, id)
+		change, err := o.TransitionTo(ctx, next, now)
 		if err != nil {
-			if errors.Is(err, domain.ErrNotFoundOrder) {
-				returnErr = fmt.Errorf("%w: %s", domain.ErrNotFoundOrder, id)
-				continue
-			}
-			return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
+			batch.Fail(id, err)
+			continue
 		}
 
-		if err := validateOrderForRefund(o, userID, now); err != nil {
-			returnErr = err
-			break
+		if err := update(ctx, tx, id, now); err != nil {
+			return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 		}
 
-		if err := s.updateRefundTime(ctx, tx, id, now); err != nil {
+		if err := storageutils.SaveStatusChange(ctx, tx, change); err != nil {
 			return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 		}
 
-		processed = append(processed, id)
+		batch.Processed(id, change.ChangedAt)
+		events = append(events, domain.NewOrderEvent(*o, change))
+	}
+
+	if batch.RolledBack() {
+		return batch.Result(userID, orderIDs), nil
+	}
+
+	if err := storageutils.SaveOrderEvents(ctx, tx, s.encode, events); err != nil {
+		return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return domain.ProcessedOrders{}, fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 	}
 
-	return domain.ProcessedOrders{
-		UserID:   userID,
-		OrderIDs: processed,
-		Failed:   getFailedOrders(orderIDs, processed),
-		Error:    returnErr,
-	}, nil
+	return batch.Result(userID, orderIDs), nil
 }
 
 func (s *UserOrderStorage) lockAndGetOrder(ctx context.Context, tx pgx.Tx, id string) (*domain.Order, error) {
 	query := `SELECT order_id, recipient_id, expiry, stored_at, issued_at, 
-					 refunded_at, base_price, weight, packaging
+					 refunded_at, base_price, currency, weight, packaging, ` + storageutils.PackagePriceColumn + `, status
 	 		FROM orders WHERE order_id = $1 FOR UPDATE`
 	row := tx.QueryRow(ctx, query, id)
 	order, err := storageutils.ScanOrder(row)
@@ -130,7 +131,7 @@ func validateOrderForIssue(o *domain.Order, userID string, now time.Time) error
 		return &domain.ErrUserDoesntOwnOrder{OrderID: o.ID, UserID: userID}
 	}
 
-	if o.Status() != domain.StatusStored {
+	if !o.Status().CanTransitionTo(domain.StatusIssued) {
 		return domain.ErrNotStoredOrder
 	}
 
@@ -146,7 +147,7 @@ func validateOrderForRefund(o *domain.Order, userID string, now time.Time) error
 		return &domain.ErrUserDoesntOwnOrder{OrderID: o.ID, UserID: userID}
 	}
 
-	if o.Status() != domain.StatusIssued {
+	if !o.Status().CanTransitionTo(domain.StatusRefunded) {
 		return domain.ErrNotIssuedOrder
 	}
 
@@ -159,8 +160,8 @@ func validateOrderForRefund(o *domain.Order, userID string, now time.Time) error
 
 func (s *UserOrderStorage) updateIssueTime(ctx context.Context, tx pgx.Tx, id string, t time.Time) error {
 	_, err := tx.Exec(ctx,
-		"UPDATE orders SET issued_at = $1 WHERE order_id = $2",
-		t, id,
+		"UPDATE orders SET issued_at = $1, status = $2 WHERE order_id = $3",
+		t, domain.StatusIssued, id,
 	)
 	if err != nil {
 		return fmt.Errorf("%w: %v", domain.ErrDatabase, err)
@@ -170,26 +171,11 @@ func (s *UserOrderStorage) updateIssueTime(ctx context.Context, tx pgx.Tx, id st
 
 func (s *UserOrderStorage) updateRefundTime(ctx context.Context, tx pgx.Tx, id string, t time.Time) error {
 	_, err := tx.Exec(ctx,
-		"UPDATE orders SET refunded_at = $1 WHERE order_id = $2",
-		t, id,
+		"UPDATE orders SET refunded_at = $1, status = $2 WHERE order_id = $3",
+		t, domain.StatusRefunded, id,
 	)
 	if err != nil {
 		return fmt.Errorf("%w: %v", domain.ErrDatabase, err)
 	}
 	return nil
 }
-
-func getFailedOrders(orderIDs, processed []string) []string {
-	processedMap := make(map[string]struct{}, len(processed))
-	for _, id := range processed {
-		processedMap[id] = struct{}{}
-	}
-
-	var failed []string
-	for _, id := range orderIDs {
-		if _, found := processedMap[id]; !found {
-			failed = append(failed, id)
-		}
-	}
-	return failed
-}
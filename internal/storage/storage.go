@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/jackc/pgx/v5"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 )
 
@@ -12,12 +11,14 @@ type OrderStorage interface {
 	SaveOrder(ctx context.Context, order domain.Order) error
 	FindOrderByID(ctx context.Context, id string) (*domain.Order, error)
 	FindOrdersByIDs(ctx context.Context, ids []string) ([]*domain.Order, error)
-	DeleteOrder(ctx context.Context, id string) error
+	ReturnOrder(ctx context.Context, id string) error
+	GetStatusHistory(ctx context.Context, id string) ([]domain.OrderStatusChange, error)
+	ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error)
 }
 
 type UserOrderStorage interface {
-	IssueOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error)
-	RefundOrders(ctx context.Context, userID string, orderIDs []string) (domain.ProcessedOrders, error)
+	IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error)
+	RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (domain.ProcessedOrders, error)
 }
 
 type ReportOrderStorage interface {
@@ -33,11 +34,30 @@ type ReportOrderStorage interface {
 type AuthStorage interface {
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	UpdateUserRole(ctx context.Context, email string, role domain.Role) error
+	SaveRefreshToken(ctx context.Context, token domain.RefreshToken) error
+	ConsumeRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	RevokeUserRefreshTokens(ctx context.Context, email string) error
 }
 
 type AuditLogStorage interface {
 	SaveLog(ctx context.Context, auditTask domain.AuditTask) error
-	BeginTx(ctx context.Context) (pgx.Tx, error)
-	FetchPendingTasksTx(context.Context, pgx.Tx, int) ([]domain.AuditTask, error)
+	SaveLogs(ctx context.Context, auditTasks []domain.AuditTask) error
+	ClaimPendingTasks(ctx context.Context, limit int, lease time.Duration) ([]domain.AuditTask, error)
+	FinishTasks(ctx context.Context, ids []int, finishedAt time.Time) error
+	ListenNewTasks(ctx context.Context, notify func()) error
+	OldestPendingTaskAge(ctx context.Context) (time.Duration, error)
 	UpdateTask(ctx context.Context, task domain.AuditTask) error
+	GetEvents(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, string, error)
+	ListDeadTasks(ctx context.Context, limit int, cursor *int) ([]domain.AuditTask, string, error)
+	GetTask(ctx context.Context, id int) (domain.AuditTask, error)
+	RequeueDeadTasks(ctx context.Context, ids []int) (int64, error)
+	CountDeadTasks(ctx context.Context) (int, error)
+	GetChainRecords(ctx context.Context, afterID, limit int) ([]domain.AuditChainRecord, error)
+	GetChainHead(ctx context.Context) (domain.AuditChainHead, error)
+}
+
+type AuditEventStorage interface {
+	SaveEvents(ctx context.Context, events []domain.AuditEvent) (int, error)
 }
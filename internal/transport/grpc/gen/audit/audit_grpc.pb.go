@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.30.1
+// source: audit/audit.proto
+
+package audit
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AuditHandler_GetAuditEvents_FullMethodName = "/transport.grpc.audit.AuditHandler/GetAuditEvents"
+)
+
+// AuditHandlerClient is the client API for AuditHandler service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AuditHandlerClient interface {
+	GetAuditEvents(ctx context.Context, in *GetAuditEventsRequest, opts ...grpc.CallOption) (*GetAuditEventsResponse, error)
+}
+
+type auditHandlerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuditHandlerClient(cc grpc.ClientConnInterface) AuditHandlerClient {
+	return &auditHandlerClient{cc}
+}
+
+func (c *auditHandlerClient) GetAuditEvents(ctx context.Context, in *GetAuditEventsRequest, opts ...grpc.CallOption) (*GetAuditEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAuditEventsResponse)
+	err := c.cc.Invoke(ctx, AuditHandler_GetAuditEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuditHandlerServer is the server API for AuditHandler service.
+// All implementations must embed UnimplementedAuditHandlerServer
+// for forward compatibility.
+type AuditHandlerServer interface {
+	GetAuditEvents(context.Context, *GetAuditEventsRequest) (*GetAuditEventsResponse, error)
+	mustEmbedUnimplementedAuditHandlerServer()
+}
+
+// UnimplementedAuditHandlerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAuditHandlerServer struct{}
+
+func (UnimplementedAuditHandlerServer) GetAuditEvents(context.Context, *GetAuditEventsRequest) (*GetAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditEvents not implemented")
+}
+func (UnimplementedAuditHandlerServer) mustEmbedUnimplementedAuditHandlerServer() {}
+func (UnimplementedAuditHandlerServer) testEmbeddedByValue()                      {}
+
+// UnsafeAuditHandlerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuditHandlerServer will
+// result in compilation errors.
+type UnsafeAuditHandlerServer interface {
+	mustEmbedUnimplementedAuditHandlerServer()
+}
+
+func RegisterAuditHandlerServer(s grpc.ServiceRegistrar, srv AuditHandlerServer) {
+	// If the following call pancis, it indicates UnimplementedAuditHandlerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AuditHandler_ServiceDesc, srv)
+}
+
+func _AuditHandler_GetAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditHandlerServer).GetAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditHandler_GetAuditEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditHandlerServer).GetAuditEvents(ctx, req.(*GetAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuditHandler_ServiceDesc is the grpc.ServiceDesc for AuditHandler service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuditHandler_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transport.grpc.audit.AuditHandler",
+	HandlerType: (*AuditHandlerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAuditEvents",
+			Handler:    _AuditHandler_GetAuditEvents_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "audit/audit.proto",
+}
@@ -0,0 +1,587 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.30.1
+// source: events/v1/order.proto
+
+// Доменные события заказа для других сервисов. Публикуются в топик
+// order_events через outbox с ключом — ID заказа, поэтому события одного
+// заказа читаются в порядке изменений. Записи кодируются в JSON с именами
+// полей как в proto; правила изменения схемы — как в audit.proto.
+
+package eventsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type OrderEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// UUID события
+	EventId string `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	// Версия схемы, для events.v1 — 1
+	SchemaVersion uint32 `protobuf:"varint,2,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	OrderId       string `protobuf:"bytes,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// Время изменения в RFC3339
+	OccurredAt string `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// Email пользователя или system:return_job
+	Actor string `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*OrderEvent_Stored
+	//	*OrderEvent_Issued
+	//	*OrderEvent_Refunded
+	//	*OrderEvent_ReturnedToCourier
+	Event         isOrderEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_events_v1_order_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_order_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_events_v1_order_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OrderEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *OrderEvent) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetEvent() isOrderEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetStored() *OrderStored {
+	if x != nil {
+		if x, ok := x.Event.(*OrderEvent_Stored); ok {
+			return x.Stored
+		}
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetIssued() *OrderIssued {
+	if x != nil {
+		if x, ok := x.Event.(*OrderEvent_Issued); ok {
+			return x.Issued
+		}
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetRefunded() *OrderRefunded {
+	if x != nil {
+		if x, ok := x.Event.(*OrderEvent_Refunded); ok {
+			return x.Refunded
+		}
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetReturnedToCourier() *OrderReturnedToCourier {
+	if x != nil {
+		if x, ok := x.Event.(*OrderEvent_ReturnedToCourier); ok {
+			return x.ReturnedToCourier
+		}
+	}
+	return nil
+}
+
+type isOrderEvent_Event interface {
+	isOrderEvent_Event()
+}
+
+type OrderEvent_Stored struct {
+	Stored *OrderStored `protobuf:"bytes,10,opt,name=stored,proto3,oneof"`
+}
+
+type OrderEvent_Issued struct {
+	Issued *OrderIssued `protobuf:"bytes,11,opt,name=issued,proto3,oneof"`
+}
+
+type OrderEvent_Refunded struct {
+	Refunded *OrderRefunded `protobuf:"bytes,12,opt,name=refunded,proto3,oneof"`
+}
+
+type OrderEvent_ReturnedToCourier struct {
+	ReturnedToCourier *OrderReturnedToCourier `protobuf:"bytes,13,opt,name=returned_to_courier,json=returnedToCourier,proto3,oneof"`
+}
+
+func (*OrderEvent_Stored) isOrderEvent_Event() {}
+
+func (*OrderEvent_Issued) isOrderEvent_Event() {}
+
+func (*OrderEvent_Refunded) isOrderEvent_Event() {}
+
+func (*OrderEvent_ReturnedToCourier) isOrderEvent_Event() {}
+
+// Заказ принят на склад.
+type OrderStored struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId string                 `protobuf:"bytes,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	// Срок хранения в RFC3339
+	Expiry string `protobuf:"bytes,2,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	// Приближенные значения price и packaging_price для старых потребителей
+	//
+	// Deprecated: Marked as deprecated in events/v1/order.proto.
+	BasePrice float64 `protobuf:"fixed64,3,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
+	// Deprecated: Marked as deprecated in events/v1/order.proto.
+	PackagePrice   float64 `protobuf:"fixed64,4,opt,name=package_price,json=packagePrice,proto3" json:"package_price,omitempty"`
+	Weight         float64 `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	Packaging      string  `protobuf:"bytes,6,opt,name=packaging,proto3" json:"packaging,omitempty"`
+	Price          *Money  `protobuf:"bytes,7,opt,name=price,proto3" json:"price,omitempty"`
+	PackagingPrice *Money  `protobuf:"bytes,8,opt,name=packaging_price,json=packagingPrice,proto3" json:"packaging_price,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OrderStored) Reset() {
+	*x = OrderStored{}
+	mi := &file_events_v1_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStored) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStored) ProtoMessage() {}
+
+func (x *OrderStored) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStored.ProtoReflect.Descriptor instead.
+func (*OrderStored) Descriptor() ([]byte, []int) {
+	return file_events_v1_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderStored) GetRecipientId() string {
+	if x != nil {
+		return x.RecipientId
+	}
+	return ""
+}
+
+func (x *OrderStored) GetExpiry() string {
+	if x != nil {
+		return x.Expiry
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in events/v1/order.proto.
+func (x *OrderStored) GetBasePrice() float64 {
+	if x != nil {
+		return x.BasePrice
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in events/v1/order.proto.
+func (x *OrderStored) GetPackagePrice() float64 {
+	if x != nil {
+		return x.PackagePrice
+	}
+	return 0
+}
+
+func (x *OrderStored) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *OrderStored) GetPackaging() string {
+	if x != nil {
+		return x.Packaging
+	}
+	return ""
+}
+
+func (x *OrderStored) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *OrderStored) GetPackagingPrice() *Money {
+	if x != nil {
+		return x.PackagingPrice
+	}
+	return nil
+}
+
+// Сумма в минимальных единицах валюты (копейках для рубля).
+type Money struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	MinorUnits int64                  `protobuf:"varint,1,opt,name=minor_units,json=minorUnits,proto3" json:"minor_units,omitempty"`
+	// Код ISO 4217
+	Currency      string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_events_v1_order_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_order_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_events_v1_order_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Money) GetMinorUnits() int64 {
+	if x != nil {
+		return x.MinorUnits
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+// Заказ выдан получателю.
+type OrderIssued struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId   string                 `protobuf:"bytes,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderIssued) Reset() {
+	*x = OrderIssued{}
+	mi := &file_events_v1_order_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderIssued) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderIssued) ProtoMessage() {}
+
+func (x *OrderIssued) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_order_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderIssued.ProtoReflect.Descriptor instead.
+func (*OrderIssued) Descriptor() ([]byte, []int) {
+	return file_events_v1_order_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrderIssued) GetRecipientId() string {
+	if x != nil {
+		return x.RecipientId
+	}
+	return ""
+}
+
+// Получатель вернул заказ.
+type OrderRefunded struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId   string                 `protobuf:"bytes,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRefunded) Reset() {
+	*x = OrderRefunded{}
+	mi := &file_events_v1_order_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRefunded) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRefunded) ProtoMessage() {}
+
+func (x *OrderRefunded) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_order_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRefunded.ProtoReflect.Descriptor instead.
+func (*OrderRefunded) Descriptor() ([]byte, []int) {
+	return file_events_v1_order_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderRefunded) GetRecipientId() string {
+	if x != nil {
+		return x.RecipientId
+	}
+	return ""
+}
+
+// Заказ возвращен курьеру: вручную или по истечении срока хранения.
+type OrderReturnedToCourier struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RecipientId   string                 `protobuf:"bytes,1,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderReturnedToCourier) Reset() {
+	*x = OrderReturnedToCourier{}
+	mi := &file_events_v1_order_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderReturnedToCourier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderReturnedToCourier) ProtoMessage() {}
+
+func (x *OrderReturnedToCourier) ProtoReflect() protoreflect.Message {
+	mi := &file_events_v1_order_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderReturnedToCourier.ProtoReflect.Descriptor instead.
+func (*OrderReturnedToCourier) Descriptor() ([]byte, []int) {
+	return file_events_v1_order_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OrderReturnedToCourier) GetRecipientId() string {
+	if x != nil {
+		return x.RecipientId
+	}
+	return ""
+}
+
+var File_events_v1_order_proto protoreflect.FileDescriptor
+
+const file_events_v1_order_proto_rawDesc = "" +
+	"\n" +
+	"\x15events/v1/order.proto\x12\tevents.v1\"\x9a\x03\n" +
+	"\n" +
+	"OrderEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12%\n" +
+	"\x0eschema_version\x18\x02 \x01(\rR\rschemaVersion\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\tR\aorderId\x12\x1f\n" +
+	"\voccurred_at\x18\x04 \x01(\tR\n" +
+	"occurredAt\x12\x14\n" +
+	"\x05actor\x18\x05 \x01(\tR\x05actor\x120\n" +
+	"\x06stored\x18\n" +
+	" \x01(\v2\x16.events.v1.OrderStoredH\x00R\x06stored\x120\n" +
+	"\x06issued\x18\v \x01(\v2\x16.events.v1.OrderIssuedH\x00R\x06issued\x126\n" +
+	"\brefunded\x18\f \x01(\v2\x18.events.v1.OrderRefundedH\x00R\brefunded\x12S\n" +
+	"\x13returned_to_courier\x18\r \x01(\v2!.events.v1.OrderReturnedToCourierH\x00R\x11returnedToCourierB\a\n" +
+	"\x05event\"\xad\x02\n" +
+	"\vOrderStored\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\tR\vrecipientId\x12\x16\n" +
+	"\x06expiry\x18\x02 \x01(\tR\x06expiry\x12!\n" +
+	"\n" +
+	"base_price\x18\x03 \x01(\x01B\x02\x18\x01R\tbasePrice\x12'\n" +
+	"\rpackage_price\x18\x04 \x01(\x01B\x02\x18\x01R\fpackagePrice\x12\x16\n" +
+	"\x06weight\x18\x05 \x01(\x01R\x06weight\x12\x1c\n" +
+	"\tpackaging\x18\x06 \x01(\tR\tpackaging\x12&\n" +
+	"\x05price\x18\a \x01(\v2\x10.events.v1.MoneyR\x05price\x129\n" +
+	"\x0fpackaging_price\x18\b \x01(\v2\x10.events.v1.MoneyR\x0epackagingPrice\"D\n" +
+	"\x05Money\x12\x1f\n" +
+	"\vminor_units\x18\x01 \x01(\x03R\n" +
+	"minorUnits\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"0\n" +
+	"\vOrderIssued\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\tR\vrecipientId\"2\n" +
+	"\rOrderRefunded\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\tR\vrecipientId\";\n" +
+	"\x16OrderReturnedToCourier\x12!\n" +
+	"\frecipient_id\x18\x01 \x01(\tR\vrecipientIdBUZSgitlab.ozon.dev/sadsnake231/homework/internal/transport/grpc/gen/events/v1;eventsv1b\x06proto3"
+
+var (
+	file_events_v1_order_proto_rawDescOnce sync.Once
+	file_events_v1_order_proto_rawDescData []byte
+)
+
+func file_events_v1_order_proto_rawDescGZIP() []byte {
+	file_events_v1_order_proto_rawDescOnce.Do(func() {
+		file_events_v1_order_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_events_v1_order_proto_rawDesc), len(file_events_v1_order_proto_rawDesc)))
+	})
+	return file_events_v1_order_proto_rawDescData
+}
+
+var file_events_v1_order_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_events_v1_order_proto_goTypes = []any{
+	(*OrderEvent)(nil),             // 0: events.v1.OrderEvent
+	(*OrderStored)(nil),            // 1: events.v1.OrderStored
+	(*Money)(nil),                  // 2: events.v1.Money
+	(*OrderIssued)(nil),            // 3: events.v1.OrderIssued
+	(*OrderRefunded)(nil),          // 4: events.v1.OrderRefunded
+	(*OrderReturnedToCourier)(nil), // 5: events.v1.OrderReturnedToCourier
+}
+var file_events_v1_order_proto_depIdxs = []int32{
+	1, // 0: events.v1.OrderEvent.stored:type_name -> events.v1.OrderStored
+	3, // 1: events.v1.OrderEvent.issued:type_name -> events.v1.OrderIssued
+	4, // 2: events.v1.OrderEvent.refunded:type_name -> events.v1.OrderRefunded
+	5, // 3: events.v1.OrderEvent.returned_to_courier:type_name -> events.v1.OrderReturnedToCourier
+	2, // 4: events.v1.OrderStored.price:type_name -> events.v1.Money
+	2, // 5: events.v1.OrderStored.packaging_price:type_name -> events.v1.Money
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_events_v1_order_proto_init() }
+func file_events_v1_order_proto_init() {
+	if File_events_v1_order_proto != nil {
+		return
+	}
+	file_events_v1_order_proto_msgTypes[0].OneofWrappers = []any{
+		(*OrderEvent_Stored)(nil),
+		(*OrderEvent_Issued)(nil),
+		(*OrderEvent_Refunded)(nil),
+		(*OrderEvent_ReturnedToCourier)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_events_v1_order_proto_rawDesc), len(file_events_v1_order_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_events_v1_order_proto_goTypes,
+		DependencyIndexes: file_events_v1_order_proto_depIdxs,
+		MessageInfos:      file_events_v1_order_proto_msgTypes,
+	}.Build()
+	File_events_v1_order_proto = out.File
+	file_events_v1_order_proto_goTypes = nil
+	file_events_v1_order_proto_depIdxs = nil
+}
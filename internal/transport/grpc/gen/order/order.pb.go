@@ -22,13 +22,17 @@ const (
 )
 
 type AcceptOrderRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	RecipientId   string                 `protobuf:"bytes,2,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
-	Expiry        string                 `protobuf:"bytes,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
-	BasePrice     float64                `protobuf:"fixed64,4,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
-	Weight        float64                `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
-	Packaging     string                 `protobuf:"bytes,6,opt,name=packaging,proto3" json:"packaging,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RecipientId string                 `protobuf:"bytes,2,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	Expiry      string                 `protobuf:"bytes,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	// Цена в рублях, используется, если price не задан
+	//
+	// Deprecated: Marked as deprecated in order/order.proto.
+	BasePrice     float64 `protobuf:"fixed64,4,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
+	Weight        float64 `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	Packaging     string  `protobuf:"bytes,6,opt,name=packaging,proto3" json:"packaging,omitempty"`
+	Price         *Money  `protobuf:"bytes,7,opt,name=price,proto3" json:"price,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -84,6 +88,7 @@ func (x *AcceptOrderRequest) GetExpiry() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in order/order.proto.
 func (x *AcceptOrderRequest) GetBasePrice() float64 {
 	if x != nil {
 		return x.BasePrice
@@ -105,6 +110,67 @@ func (x *AcceptOrderRequest) GetPackaging() string {
 	return ""
 }
 
+func (x *AcceptOrderRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+// Сумма в минимальных единицах валюты (копейках для рубля).
+type Money struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	MinorUnits int64                  `protobuf:"varint,1,opt,name=minor_units,json=minorUnits,proto3" json:"minor_units,omitempty"`
+	// Код ISO 4217, по умолчанию RUB
+	Currency      string `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_order_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_order_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_order_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Money) GetMinorUnits() int64 {
+	if x != nil {
+		return x.MinorUnits
+	}
+	return 0
+}
+
+func (x *Money) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
 type AcceptOrderResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -114,7 +180,7 @@ type AcceptOrderResponse struct {
 
 func (x *AcceptOrderResponse) Reset() {
 	*x = AcceptOrderResponse{}
-	mi := &file_order_order_proto_msgTypes[1]
+	mi := &file_order_order_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -126,7 +192,7 @@ func (x *AcceptOrderResponse) String() string {
 func (*AcceptOrderResponse) ProtoMessage() {}
 
 func (x *AcceptOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[1]
+	mi := &file_order_order_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -139,7 +205,7 @@ func (x *AcceptOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AcceptOrderResponse.ProtoReflect.Descriptor instead.
 func (*AcceptOrderResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{1}
+	return file_order_order_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *AcceptOrderResponse) GetMessage() string {
@@ -158,7 +224,7 @@ type ReturnOrderRequest struct {
 
 func (x *ReturnOrderRequest) Reset() {
 	*x = ReturnOrderRequest{}
-	mi := &file_order_order_proto_msgTypes[2]
+	mi := &file_order_order_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -170,7 +236,7 @@ func (x *ReturnOrderRequest) String() string {
 func (*ReturnOrderRequest) ProtoMessage() {}
 
 func (x *ReturnOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[2]
+	mi := &file_order_order_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -183,7 +249,7 @@ func (x *ReturnOrderRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReturnOrderRequest.ProtoReflect.Descriptor instead.
 func (*ReturnOrderRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{2}
+	return file_order_order_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ReturnOrderRequest) GetId() string {
@@ -202,7 +268,7 @@ type ReturnOrderResponse struct {
 
 func (x *ReturnOrderResponse) Reset() {
 	*x = ReturnOrderResponse{}
-	mi := &file_order_order_proto_msgTypes[3]
+	mi := &file_order_order_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -214,7 +280,7 @@ func (x *ReturnOrderResponse) String() string {
 func (*ReturnOrderResponse) ProtoMessage() {}
 
 func (x *ReturnOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[3]
+	mi := &file_order_order_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -227,7 +293,7 @@ func (x *ReturnOrderResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReturnOrderResponse.ProtoReflect.Descriptor instead.
 func (*ReturnOrderResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{3}
+	return file_order_order_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ReturnOrderResponse) GetMessage() string {
@@ -237,18 +303,184 @@ func (x *ReturnOrderResponse) GetMessage() string {
 	return ""
 }
 
-type IssueRefundRequest struct {
+type GetOrderStatusHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderStatusHistoryRequest) Reset() {
+	*x = GetOrderStatusHistoryRequest{}
+	mi := &file_order_order_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderStatusHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderStatusHistoryRequest) ProtoMessage() {}
+
+func (x *GetOrderStatusHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_order_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderStatusHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderStatusHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_order_order_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetOrderStatusHistoryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetOrderStatusHistoryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Command       string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
-	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	OrderIds      []string               `protobuf:"bytes,3,rep,name=order_ids,json=orderIds,proto3" json:"order_ids,omitempty"`
+	History       []*OrderStatusChange   `protobuf:"bytes,1,rep,name=history,proto3" json:"history,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderStatusHistoryResponse) Reset() {
+	*x = GetOrderStatusHistoryResponse{}
+	mi := &file_order_order_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderStatusHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderStatusHistoryResponse) ProtoMessage() {}
+
+func (x *GetOrderStatusHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_order_order_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderStatusHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderStatusHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_order_order_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOrderStatusHistoryResponse) GetHistory() []*OrderStatusChange {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+type OrderStatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	FromStatus    string                 `protobuf:"bytes,2,opt,name=from_status,json=fromStatus,proto3" json:"from_status,omitempty"`
+	ToStatus      string                 `protobuf:"bytes,3,opt,name=to_status,json=toStatus,proto3" json:"to_status,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	ChangedAt     string                 `protobuf:"bytes,5,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderStatusChange) Reset() {
+	*x = OrderStatusChange{}
+	mi := &file_order_order_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStatusChange) ProtoMessage() {}
+
+func (x *OrderStatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_order_order_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStatusChange.ProtoReflect.Descriptor instead.
+func (*OrderStatusChange) Descriptor() ([]byte, []int) {
+	return file_order_order_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *OrderStatusChange) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetFromStatus() string {
+	if x != nil {
+		return x.FromStatus
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetToStatus() string {
+	if x != nil {
+		return x.ToStatus
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *OrderStatusChange) GetChangedAt() string {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return ""
+}
+
+type IssueRefundRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Command  string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	UserId   string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	OrderIds []string               `protobuf:"bytes,3,rep,name=order_ids,json=orderIds,proto3" json:"order_ids,omitempty"`
+	// При ошибке в любом заказе транзакция откатывается целиком
+	Atomic        bool `protobuf:"varint,4,opt,name=atomic,proto3" json:"atomic,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *IssueRefundRequest) Reset() {
 	*x = IssueRefundRequest{}
-	mi := &file_order_order_proto_msgTypes[4]
+	mi := &file_order_order_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -260,7 +492,7 @@ func (x *IssueRefundRequest) String() string {
 func (*IssueRefundRequest) ProtoMessage() {}
 
 func (x *IssueRefundRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[4]
+	mi := &file_order_order_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -273,7 +505,7 @@ func (x *IssueRefundRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IssueRefundRequest.ProtoReflect.Descriptor instead.
 func (*IssueRefundRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{4}
+	return file_order_order_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *IssueRefundRequest) GetCommand() string {
@@ -297,18 +529,26 @@ func (x *IssueRefundRequest) GetOrderIds() []string {
 	return nil
 }
 
+func (x *IssueRefundRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
 type IssueRefundResponse struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	ProcessedOrderIds []string               `protobuf:"bytes,1,rep,name=processed_order_ids,json=processedOrderIds,proto3" json:"processed_order_ids,omitempty"`
 	FailedOrderIds    []string               `protobuf:"bytes,2,rep,name=failed_order_ids,json=failedOrderIds,proto3" json:"failed_order_ids,omitempty"`
 	Error             string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Results           []*OrderResult         `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *IssueRefundResponse) Reset() {
 	*x = IssueRefundResponse{}
-	mi := &file_order_order_proto_msgTypes[5]
+	mi := &file_order_order_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -320,7 +560,7 @@ func (x *IssueRefundResponse) String() string {
 func (*IssueRefundResponse) ProtoMessage() {}
 
 func (x *IssueRefundResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[5]
+	mi := &file_order_order_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -333,7 +573,7 @@ func (x *IssueRefundResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IssueRefundResponse.ProtoReflect.Descriptor instead.
 func (*IssueRefundResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{5}
+	return file_order_order_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *IssueRefundResponse) GetProcessedOrderIds() []string {
@@ -357,6 +597,92 @@ func (x *IssueRefundResponse) GetError() string {
 	return ""
 }
 
+func (x *IssueRefundResponse) GetResults() []*OrderResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// Итог обработки одного заказа. status: processed, failed, rolled_back.
+// reason (код ошибки) и message заполнены для всех, кроме processed;
+// changed_at (RFC3339) — только для processed.
+type OrderResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	ChangedAt     string                 `protobuf:"bytes,5,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderResult) Reset() {
+	*x = OrderResult{}
+	mi := &file_order_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderResult) ProtoMessage() {}
+
+func (x *OrderResult) ProtoReflect() protoreflect.Message {
+	mi := &file_order_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderResult.ProtoReflect.Descriptor instead.
+func (*OrderResult) Descriptor() ([]byte, []int) {
+	return file_order_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OrderResult) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *OrderResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *OrderResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *OrderResult) GetChangedAt() string {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return ""
+}
+
 type GetUserOrdersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -369,7 +695,7 @@ type GetUserOrdersRequest struct {
 
 func (x *GetUserOrdersRequest) Reset() {
 	*x = GetUserOrdersRequest{}
-	mi := &file_order_order_proto_msgTypes[6]
+	mi := &file_order_order_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -381,7 +707,7 @@ func (x *GetUserOrdersRequest) String() string {
 func (*GetUserOrdersRequest) ProtoMessage() {}
 
 func (x *GetUserOrdersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[6]
+	mi := &file_order_order_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -394,7 +720,7 @@ func (x *GetUserOrdersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserOrdersRequest.ProtoReflect.Descriptor instead.
 func (*GetUserOrdersRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{6}
+	return file_order_order_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *GetUserOrdersRequest) GetUserId() string {
@@ -435,7 +761,7 @@ type GetUserOrdersResponse struct {
 
 func (x *GetUserOrdersResponse) Reset() {
 	*x = GetUserOrdersResponse{}
-	mi := &file_order_order_proto_msgTypes[7]
+	mi := &file_order_order_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -447,7 +773,7 @@ func (x *GetUserOrdersResponse) String() string {
 func (*GetUserOrdersResponse) ProtoMessage() {}
 
 func (x *GetUserOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[7]
+	mi := &file_order_order_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -460,7 +786,7 @@ func (x *GetUserOrdersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserOrdersResponse.ProtoReflect.Descriptor instead.
 func (*GetUserOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{7}
+	return file_order_order_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *GetUserOrdersResponse) GetOrders() []*Order {
@@ -487,7 +813,7 @@ type GetRefundedOrdersRequest struct {
 
 func (x *GetRefundedOrdersRequest) Reset() {
 	*x = GetRefundedOrdersRequest{}
-	mi := &file_order_order_proto_msgTypes[8]
+	mi := &file_order_order_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -499,7 +825,7 @@ func (x *GetRefundedOrdersRequest) String() string {
 func (*GetRefundedOrdersRequest) ProtoMessage() {}
 
 func (x *GetRefundedOrdersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[8]
+	mi := &file_order_order_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -512,7 +838,7 @@ func (x *GetRefundedOrdersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRefundedOrdersRequest.ProtoReflect.Descriptor instead.
 func (*GetRefundedOrdersRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{8}
+	return file_order_order_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *GetRefundedOrdersRequest) GetLimit() int32 {
@@ -539,7 +865,7 @@ type GetRefundedOrdersResponse struct {
 
 func (x *GetRefundedOrdersResponse) Reset() {
 	*x = GetRefundedOrdersResponse{}
-	mi := &file_order_order_proto_msgTypes[9]
+	mi := &file_order_order_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -551,7 +877,7 @@ func (x *GetRefundedOrdersResponse) String() string {
 func (*GetRefundedOrdersResponse) ProtoMessage() {}
 
 func (x *GetRefundedOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[9]
+	mi := &file_order_order_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -564,7 +890,7 @@ func (x *GetRefundedOrdersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetRefundedOrdersResponse.ProtoReflect.Descriptor instead.
 func (*GetRefundedOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{9}
+	return file_order_order_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *GetRefundedOrdersResponse) GetOrders() []*Order {
@@ -592,7 +918,7 @@ type GetOrderHistoryRequest struct {
 
 func (x *GetOrderHistoryRequest) Reset() {
 	*x = GetOrderHistoryRequest{}
-	mi := &file_order_order_proto_msgTypes[10]
+	mi := &file_order_order_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -604,7 +930,7 @@ func (x *GetOrderHistoryRequest) String() string {
 func (*GetOrderHistoryRequest) ProtoMessage() {}
 
 func (x *GetOrderHistoryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[10]
+	mi := &file_order_order_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -617,7 +943,7 @@ func (x *GetOrderHistoryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderHistoryRequest.ProtoReflect.Descriptor instead.
 func (*GetOrderHistoryRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{10}
+	return file_order_order_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetOrderHistoryRequest) GetLimit() int32 {
@@ -651,7 +977,7 @@ type GetOrderHistoryResponse struct {
 
 func (x *GetOrderHistoryResponse) Reset() {
 	*x = GetOrderHistoryResponse{}
-	mi := &file_order_order_proto_msgTypes[11]
+	mi := &file_order_order_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -663,7 +989,7 @@ func (x *GetOrderHistoryResponse) String() string {
 func (*GetOrderHistoryResponse) ProtoMessage() {}
 
 func (x *GetOrderHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[11]
+	mi := &file_order_order_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -676,7 +1002,7 @@ func (x *GetOrderHistoryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderHistoryResponse.ProtoReflect.Descriptor instead.
 func (*GetOrderHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{11}
+	return file_order_order_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GetOrderHistoryResponse) GetOrders() []*Order {
@@ -702,7 +1028,7 @@ type GetUserActiveOrdersRequest struct {
 
 func (x *GetUserActiveOrdersRequest) Reset() {
 	*x = GetUserActiveOrdersRequest{}
-	mi := &file_order_order_proto_msgTypes[12]
+	mi := &file_order_order_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -714,7 +1040,7 @@ func (x *GetUserActiveOrdersRequest) String() string {
 func (*GetUserActiveOrdersRequest) ProtoMessage() {}
 
 func (x *GetUserActiveOrdersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[12]
+	mi := &file_order_order_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -727,7 +1053,7 @@ func (x *GetUserActiveOrdersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserActiveOrdersRequest.ProtoReflect.Descriptor instead.
 func (*GetUserActiveOrdersRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{12}
+	return file_order_order_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *GetUserActiveOrdersRequest) GetUserId() string {
@@ -746,7 +1072,7 @@ type GetUserActiveOrdersResponse struct {
 
 func (x *GetUserActiveOrdersResponse) Reset() {
 	*x = GetUserActiveOrdersResponse{}
-	mi := &file_order_order_proto_msgTypes[13]
+	mi := &file_order_order_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -758,7 +1084,7 @@ func (x *GetUserActiveOrdersResponse) String() string {
 func (*GetUserActiveOrdersResponse) ProtoMessage() {}
 
 func (x *GetUserActiveOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[13]
+	mi := &file_order_order_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -771,7 +1097,7 @@ func (x *GetUserActiveOrdersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetUserActiveOrdersResponse.ProtoReflect.Descriptor instead.
 func (*GetUserActiveOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{13}
+	return file_order_order_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *GetUserActiveOrdersResponse) GetOrders() []*Order {
@@ -790,7 +1116,7 @@ type GetAllActiveOrdersRequest struct {
 
 func (x *GetAllActiveOrdersRequest) Reset() {
 	*x = GetAllActiveOrdersRequest{}
-	mi := &file_order_order_proto_msgTypes[14]
+	mi := &file_order_order_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -802,7 +1128,7 @@ func (x *GetAllActiveOrdersRequest) String() string {
 func (*GetAllActiveOrdersRequest) ProtoMessage() {}
 
 func (x *GetAllActiveOrdersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[14]
+	mi := &file_order_order_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -815,7 +1141,7 @@ func (x *GetAllActiveOrdersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAllActiveOrdersRequest.ProtoReflect.Descriptor instead.
 func (*GetAllActiveOrdersRequest) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{14}
+	return file_order_order_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *GetAllActiveOrdersRequest) GetCursor() string {
@@ -834,7 +1160,7 @@ type GetAllActiveOrdersResponse struct {
 
 func (x *GetAllActiveOrdersResponse) Reset() {
 	*x = GetAllActiveOrdersResponse{}
-	mi := &file_order_order_proto_msgTypes[15]
+	mi := &file_order_order_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -846,7 +1172,7 @@ func (x *GetAllActiveOrdersResponse) String() string {
 func (*GetAllActiveOrdersResponse) ProtoMessage() {}
 
 func (x *GetAllActiveOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[15]
+	mi := &file_order_order_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -859,7 +1185,7 @@ func (x *GetAllActiveOrdersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAllActiveOrdersResponse.ProtoReflect.Descriptor instead.
 func (*GetAllActiveOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{15}
+	return file_order_order_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *GetAllActiveOrdersResponse) GetOrders() []*Order {
@@ -878,7 +1204,7 @@ type GetOrderHistoryV2Request struct {
 
 func (x *GetOrderHistoryV2Request) Reset() {
 	*x = GetOrderHistoryV2Request{}
-	mi := &file_order_order_proto_msgTypes[16]
+	mi := &file_order_order_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -890,7 +1216,7 @@ func (x *GetOrderHistoryV2Request) String() string {
 func (*GetOrderHistoryV2Request) ProtoMessage() {}
 
 func (x *GetOrderHistoryV2Request) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[16]
+	mi := &file_order_order_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -903,7 +1229,7 @@ func (x *GetOrderHistoryV2Request) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderHistoryV2Request.ProtoReflect.Descriptor instead.
 func (*GetOrderHistoryV2Request) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{16}
+	return file_order_order_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *GetOrderHistoryV2Request) GetCursor() string {
@@ -922,7 +1248,7 @@ type GetOrderHistoryV2Response struct {
 
 func (x *GetOrderHistoryV2Response) Reset() {
 	*x = GetOrderHistoryV2Response{}
-	mi := &file_order_order_proto_msgTypes[17]
+	mi := &file_order_order_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -934,7 +1260,7 @@ func (x *GetOrderHistoryV2Response) String() string {
 func (*GetOrderHistoryV2Response) ProtoMessage() {}
 
 func (x *GetOrderHistoryV2Response) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[17]
+	mi := &file_order_order_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -947,7 +1273,7 @@ func (x *GetOrderHistoryV2Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetOrderHistoryV2Response.ProtoReflect.Descriptor instead.
 func (*GetOrderHistoryV2Response) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{17}
+	return file_order_order_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *GetOrderHistoryV2Response) GetOrders() []*Order {
@@ -958,20 +1284,24 @@ func (x *GetOrderHistoryV2Response) GetOrders() []*Order {
 }
 
 type Order struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	RecipientId   string                 `protobuf:"bytes,2,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
-	Expiry        string                 `protobuf:"bytes,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
-	BasePrice     float64                `protobuf:"fixed64,4,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
-	Weight        float64                `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
-	Packaging     string                 `protobuf:"bytes,6,opt,name=packaging,proto3" json:"packaging,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RecipientId string                 `protobuf:"bytes,2,opt,name=recipient_id,json=recipientId,proto3" json:"recipient_id,omitempty"`
+	Expiry      string                 `protobuf:"bytes,3,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	// Приближенное значение price, оставлено для старых клиентов
+	//
+	// Deprecated: Marked as deprecated in order/order.proto.
+	BasePrice     float64 `protobuf:"fixed64,4,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
+	Weight        float64 `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	Packaging     string  `protobuf:"bytes,6,opt,name=packaging,proto3" json:"packaging,omitempty"`
+	Price         *Money  `protobuf:"bytes,7,opt,name=price,proto3" json:"price,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Order) Reset() {
 	*x = Order{}
-	mi := &file_order_order_proto_msgTypes[18]
+	mi := &file_order_order_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -983,7 +1313,7 @@ func (x *Order) String() string {
 func (*Order) ProtoMessage() {}
 
 func (x *Order) ProtoReflect() protoreflect.Message {
-	mi := &file_order_order_proto_msgTypes[18]
+	mi := &file_order_order_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -996,7 +1326,7 @@ func (x *Order) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Order.ProtoReflect.Descriptor instead.
 func (*Order) Descriptor() ([]byte, []int) {
-	return file_order_order_proto_rawDescGZIP(), []int{18}
+	return file_order_order_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *Order) GetId() string {
@@ -1020,6 +1350,7 @@ func (x *Order) GetExpiry() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in order/order.proto.
 func (x *Order) GetBasePrice() float64 {
 	if x != nil {
 		return x.BasePrice
@@ -1041,33 +1372,66 @@ func (x *Order) GetPackaging() string {
 	return ""
 }
 
+func (x *Order) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
 var File_order_order_proto protoreflect.FileDescriptor
 
 const file_order_order_proto_rawDesc = "" +
 	"\n" +
-	"\x11order/order.proto\x12\x0etransport.grpc\"\xb4\x01\n" +
+	"\x11order/order.proto\x12\x0etransport.grpc\"\xe5\x01\n" +
 	"\x12AcceptOrderRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
 	"\frecipient_id\x18\x02 \x01(\tR\vrecipientId\x12\x16\n" +
-	"\x06expiry\x18\x03 \x01(\tR\x06expiry\x12\x1d\n" +
+	"\x06expiry\x18\x03 \x01(\tR\x06expiry\x12!\n" +
 	"\n" +
-	"base_price\x18\x04 \x01(\x01R\tbasePrice\x12\x16\n" +
+	"base_price\x18\x04 \x01(\x01B\x02\x18\x01R\tbasePrice\x12\x16\n" +
 	"\x06weight\x18\x05 \x01(\x01R\x06weight\x12\x1c\n" +
-	"\tpackaging\x18\x06 \x01(\tR\tpackaging\"/\n" +
+	"\tpackaging\x18\x06 \x01(\tR\tpackaging\x12+\n" +
+	"\x05price\x18\a \x01(\v2\x15.transport.grpc.MoneyR\x05price\"D\n" +
+	"\x05Money\x12\x1f\n" +
+	"\vminor_units\x18\x01 \x01(\x03R\n" +
+	"minorUnits\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"/\n" +
 	"\x13AcceptOrderResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\"$\n" +
 	"\x12ReturnOrderRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
 	"\x13ReturnOrderResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"d\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\".\n" +
+	"\x1cGetOrderStatusHistoryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\\\n" +
+	"\x1dGetOrderStatusHistoryResponse\x12;\n" +
+	"\ahistory\x18\x01 \x03(\v2!.transport.grpc.OrderStatusChangeR\ahistory\"\xa1\x01\n" +
+	"\x11OrderStatusChange\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x1f\n" +
+	"\vfrom_status\x18\x02 \x01(\tR\n" +
+	"fromStatus\x12\x1b\n" +
+	"\tto_status\x18\x03 \x01(\tR\btoStatus\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x1d\n" +
+	"\n" +
+	"changed_at\x18\x05 \x01(\tR\tchangedAt\"|\n" +
 	"\x12IssueRefundRequest\x12\x18\n" +
 	"\acommand\x18\x01 \x01(\tR\acommand\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x1b\n" +
-	"\torder_ids\x18\x03 \x03(\tR\borderIds\"\x85\x01\n" +
+	"\torder_ids\x18\x03 \x03(\tR\borderIds\x12\x16\n" +
+	"\x06atomic\x18\x04 \x01(\bR\x06atomic\"\xbc\x01\n" +
 	"\x13IssueRefundResponse\x12.\n" +
 	"\x13processed_order_ids\x18\x01 \x03(\tR\x11processedOrderIds\x12(\n" +
 	"\x10failed_order_ids\x18\x02 \x03(\tR\x0efailedOrderIds\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"u\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x125\n" +
+	"\aresults\x18\x04 \x03(\v2\x1b.transport.grpc.OrderResultR\aresults\"\x91\x01\n" +
+	"\vOrderResult\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"changed_at\x18\x05 \x01(\tR\tchangedAt\"u\n" +
 	"\x14GetUserOrdersRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
@@ -1103,18 +1467,20 @@ const file_order_order_proto_rawDesc = "" +
 	"\x18GetOrderHistoryV2Request\x12\x16\n" +
 	"\x06cursor\x18\x01 \x01(\tR\x06cursor\"J\n" +
 	"\x19GetOrderHistoryV2Response\x12-\n" +
-	"\x06orders\x18\x01 \x03(\v2\x15.transport.grpc.OrderR\x06orders\"\xa7\x01\n" +
+	"\x06orders\x18\x01 \x03(\v2\x15.transport.grpc.OrderR\x06orders\"\xd8\x01\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
 	"\frecipient_id\x18\x02 \x01(\tR\vrecipientId\x12\x16\n" +
-	"\x06expiry\x18\x03 \x01(\tR\x06expiry\x12\x1d\n" +
+	"\x06expiry\x18\x03 \x01(\tR\x06expiry\x12!\n" +
 	"\n" +
-	"base_price\x18\x04 \x01(\x01R\tbasePrice\x12\x16\n" +
+	"base_price\x18\x04 \x01(\x01B\x02\x18\x01R\tbasePrice\x12\x16\n" +
 	"\x06weight\x18\x05 \x01(\x01R\x06weight\x12\x1c\n" +
-	"\tpackaging\x18\x06 \x01(\tR\tpackaging2\x8f\a\n" +
+	"\tpackaging\x18\x06 \x01(\tR\tpackaging\x12+\n" +
+	"\x05price\x18\a \x01(\v2\x15.transport.grpc.MoneyR\x05price2\x85\b\n" +
 	"\fOrderHandler\x12V\n" +
 	"\vAcceptOrder\x12\".transport.grpc.AcceptOrderRequest\x1a#.transport.grpc.AcceptOrderResponse\x12V\n" +
-	"\vReturnOrder\x12\".transport.grpc.ReturnOrderRequest\x1a#.transport.grpc.ReturnOrderResponse\x12\\\n" +
+	"\vReturnOrder\x12\".transport.grpc.ReturnOrderRequest\x1a#.transport.grpc.ReturnOrderResponse\x12t\n" +
+	"\x15GetOrderStatusHistory\x12,.transport.grpc.GetOrderStatusHistoryRequest\x1a-.transport.grpc.GetOrderStatusHistoryResponse\x12\\\n" +
 	"\x11IssueRefundOrders\x12\".transport.grpc.IssueRefundRequest\x1a#.transport.grpc.IssueRefundResponse\x12\\\n" +
 	"\rGetUserOrders\x12$.transport.grpc.GetUserOrdersRequest\x1a%.transport.grpc.GetUserOrdersResponse\x12h\n" +
 	"\x11GetRefundedOrders\x12(.transport.grpc.GetRefundedOrdersRequest\x1a).transport.grpc.GetRefundedOrdersResponse\x12b\n" +
@@ -1135,58 +1501,69 @@ func file_order_order_proto_rawDescGZIP() []byte {
 	return file_order_order_proto_rawDescData
 }
 
-var file_order_order_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_order_order_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_order_order_proto_goTypes = []any{
-	(*AcceptOrderRequest)(nil),          // 0: transport.grpc.AcceptOrderRequest
-	(*AcceptOrderResponse)(nil),         // 1: transport.grpc.AcceptOrderResponse
-	(*ReturnOrderRequest)(nil),          // 2: transport.grpc.ReturnOrderRequest
-	(*ReturnOrderResponse)(nil),         // 3: transport.grpc.ReturnOrderResponse
-	(*IssueRefundRequest)(nil),          // 4: transport.grpc.IssueRefundRequest
-	(*IssueRefundResponse)(nil),         // 5: transport.grpc.IssueRefundResponse
-	(*GetUserOrdersRequest)(nil),        // 6: transport.grpc.GetUserOrdersRequest
-	(*GetUserOrdersResponse)(nil),       // 7: transport.grpc.GetUserOrdersResponse
-	(*GetRefundedOrdersRequest)(nil),    // 8: transport.grpc.GetRefundedOrdersRequest
-	(*GetRefundedOrdersResponse)(nil),   // 9: transport.grpc.GetRefundedOrdersResponse
-	(*GetOrderHistoryRequest)(nil),      // 10: transport.grpc.GetOrderHistoryRequest
-	(*GetOrderHistoryResponse)(nil),     // 11: transport.grpc.GetOrderHistoryResponse
-	(*GetUserActiveOrdersRequest)(nil),  // 12: transport.grpc.GetUserActiveOrdersRequest
-	(*GetUserActiveOrdersResponse)(nil), // 13: transport.grpc.GetUserActiveOrdersResponse
-	(*GetAllActiveOrdersRequest)(nil),   // 14: transport.grpc.GetAllActiveOrdersRequest
-	(*GetAllActiveOrdersResponse)(nil),  // 15: transport.grpc.GetAllActiveOrdersResponse
-	(*GetOrderHistoryV2Request)(nil),    // 16: transport.grpc.GetOrderHistoryV2Request
-	(*GetOrderHistoryV2Response)(nil),   // 17: transport.grpc.GetOrderHistoryV2Response
-	(*Order)(nil),                       // 18: transport.grpc.Order
+	(*AcceptOrderRequest)(nil),            // 0: transport.grpc.AcceptOrderRequest
+	(*Money)(nil),                         // 1: transport.grpc.Money
+	(*AcceptOrderResponse)(nil),           // 2: transport.grpc.AcceptOrderResponse
+	(*ReturnOrderRequest)(nil),            // 3: transport.grpc.ReturnOrderRequest
+	(*ReturnOrderResponse)(nil),           // 4: transport.grpc.ReturnOrderResponse
+	(*GetOrderStatusHistoryRequest)(nil),  // 5: transport.grpc.GetOrderStatusHistoryRequest
+	(*GetOrderStatusHistoryResponse)(nil), // 6: transport.grpc.GetOrderStatusHistoryResponse
+	(*OrderStatusChange)(nil),             // 7: transport.grpc.OrderStatusChange
+	(*IssueRefundRequest)(nil),            // 8: transport.grpc.IssueRefundRequest
+	(*IssueRefundResponse)(nil),           // 9: transport.grpc.IssueRefundResponse
+	(*OrderResult)(nil),                   // 10: transport.grpc.OrderResult
+	(*GetUserOrdersRequest)(nil),          // 11: transport.grpc.GetUserOrdersRequest
+	(*GetUserOrdersResponse)(nil),         // 12: transport.grpc.GetUserOrdersResponse
+	(*GetRefundedOrdersRequest)(nil),      // 13: transport.grpc.GetRefundedOrdersRequest
+	(*GetRefundedOrdersResponse)(nil),     // 14: transport.grpc.GetRefundedOrdersResponse
+	(*GetOrderHistoryRequest)(nil),        // 15: transport.grpc.GetOrderHistoryRequest
+	(*GetOrderHistoryResponse)(nil),       // 16: transport.grpc.GetOrderHistoryResponse
+	(*GetUserActiveOrdersRequest)(nil),    // 17: transport.grpc.GetUserActiveOrdersRequest
+	(*GetUserActiveOrdersResponse)(nil),   // 18: transport.grpc.GetUserActiveOrdersResponse
+	(*GetAllActiveOrdersRequest)(nil),     // 19: transport.grpc.GetAllActiveOrdersRequest
+	(*GetAllActiveOrdersResponse)(nil),    // 20: transport.grpc.GetAllActiveOrdersResponse
+	(*GetOrderHistoryV2Request)(nil),      // 21: transport.grpc.GetOrderHistoryV2Request
+	(*GetOrderHistoryV2Response)(nil),     // 22: transport.grpc.GetOrderHistoryV2Response
+	(*Order)(nil),                         // 23: transport.grpc.Order
 }
 var file_order_order_proto_depIdxs = []int32{
-	18, // 0: transport.grpc.GetUserOrdersResponse.orders:type_name -> transport.grpc.Order
-	18, // 1: transport.grpc.GetRefundedOrdersResponse.orders:type_name -> transport.grpc.Order
-	18, // 2: transport.grpc.GetOrderHistoryResponse.orders:type_name -> transport.grpc.Order
-	18, // 3: transport.grpc.GetUserActiveOrdersResponse.orders:type_name -> transport.grpc.Order
-	18, // 4: transport.grpc.GetAllActiveOrdersResponse.orders:type_name -> transport.grpc.Order
-	18, // 5: transport.grpc.GetOrderHistoryV2Response.orders:type_name -> transport.grpc.Order
-	0,  // 6: transport.grpc.OrderHandler.AcceptOrder:input_type -> transport.grpc.AcceptOrderRequest
-	2,  // 7: transport.grpc.OrderHandler.ReturnOrder:input_type -> transport.grpc.ReturnOrderRequest
-	4,  // 8: transport.grpc.OrderHandler.IssueRefundOrders:input_type -> transport.grpc.IssueRefundRequest
-	6,  // 9: transport.grpc.OrderHandler.GetUserOrders:input_type -> transport.grpc.GetUserOrdersRequest
-	8,  // 10: transport.grpc.OrderHandler.GetRefundedOrders:input_type -> transport.grpc.GetRefundedOrdersRequest
-	10, // 11: transport.grpc.OrderHandler.GetOrderHistory:input_type -> transport.grpc.GetOrderHistoryRequest
-	12, // 12: transport.grpc.OrderHandler.GetUserActiveOrders:input_type -> transport.grpc.GetUserActiveOrdersRequest
-	14, // 13: transport.grpc.OrderHandler.GetAllActiveOrders:input_type -> transport.grpc.GetAllActiveOrdersRequest
-	16, // 14: transport.grpc.OrderHandler.GetOrderHistoryV2:input_type -> transport.grpc.GetOrderHistoryV2Request
-	1,  // 15: transport.grpc.OrderHandler.AcceptOrder:output_type -> transport.grpc.AcceptOrderResponse
-	3,  // 16: transport.grpc.OrderHandler.ReturnOrder:output_type -> transport.grpc.ReturnOrderResponse
-	5,  // 17: transport.grpc.OrderHandler.IssueRefundOrders:output_type -> transport.grpc.IssueRefundResponse
-	7,  // 18: transport.grpc.OrderHandler.GetUserOrders:output_type -> transport.grpc.GetUserOrdersResponse
-	9,  // 19: transport.grpc.OrderHandler.GetRefundedOrders:output_type -> transport.grpc.GetRefundedOrdersResponse
-	11, // 20: transport.grpc.OrderHandler.GetOrderHistory:output_type -> transport.grpc.GetOrderHistoryResponse
-	13, // 21: transport.grpc.OrderHandler.GetUserActiveOrders:output_type -> transport.grpc.GetUserActiveOrdersResponse
-	15, // 22: transport.grpc.OrderHandler.GetAllActiveOrders:output_type -> transport.grpc.GetAllActiveOrdersResponse
-	17, // 23: transport.grpc.OrderHandler.GetOrderHistoryV2:output_type -> transport.grpc.GetOrderHistoryV2Response
-	15, // [15:24] is the sub-list for method output_type
-	6,  // [6:15] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	1,  // 0: transport.grpc.AcceptOrderRequest.price:type_name -> transport.grpc.Money
+	7,  // 1: transport.grpc.GetOrderStatusHistoryResponse.history:type_name -> transport.grpc.OrderStatusChange
+	10, // 2: transport.grpc.IssueRefundResponse.results:type_name -> transport.grpc.OrderResult
+	23, // 3: transport.grpc.GetUserOrdersResponse.orders:type_name -> transport.grpc.Order
+	23, // 4: transport.grpc.GetRefundedOrdersResponse.orders:type_name -> transport.grpc.Order
+	23, // 5: transport.grpc.GetOrderHistoryResponse.orders:type_name -> transport.grpc.Order
+	23, // 6: transport.grpc.GetUserActiveOrdersResponse.orders:type_name -> transport.grpc.Order
+	23, // 7: transport.grpc.GetAllActiveOrdersResponse.orders:type_name -> transport.grpc.Order
+	23, // 8: transport.grpc.GetOrderHistoryV2Response.orders:type_name -> transport.grpc.Order
+	1,  // 9: transport.grpc.Order.price:type_name -> transport.grpc.Money
+	0,  // 10: transport.grpc.OrderHandler.AcceptOrder:input_type -> transport.grpc.AcceptOrderRequest
+	3,  // 11: transport.grpc.OrderHandler.ReturnOrder:input_type -> transport.grpc.ReturnOrderRequest
+	5,  // 12: transport.grpc.OrderHandler.GetOrderStatusHistory:input_type -> transport.grpc.GetOrderStatusHistoryRequest
+	8,  // 13: transport.grpc.OrderHandler.IssueRefundOrders:input_type -> transport.grpc.IssueRefundRequest
+	11, // 14: transport.grpc.OrderHandler.GetUserOrders:input_type -> transport.grpc.GetUserOrdersRequest
+	13, // 15: transport.grpc.OrderHandler.GetRefundedOrders:input_type -> transport.grpc.GetRefundedOrdersRequest
+	15, // 16: transport.grpc.OrderHandler.GetOrderHistory:input_type -> transport.grpc.GetOrderHistoryRequest
+	17, // 17: transport.grpc.OrderHandler.GetUserActiveOrders:input_type -> transport.grpc.GetUserActiveOrdersRequest
+	19, // 18: transport.grpc.OrderHandler.GetAllActiveOrders:input_type -> transport.grpc.GetAllActiveOrdersRequest
+	21, // 19: transport.grpc.OrderHandler.GetOrderHistoryV2:input_type -> transport.grpc.GetOrderHistoryV2Request
+	2,  // 20: transport.grpc.OrderHandler.AcceptOrder:output_type -> transport.grpc.AcceptOrderResponse
+	4,  // 21: transport.grpc.OrderHandler.ReturnOrder:output_type -> transport.grpc.ReturnOrderResponse
+	6,  // 22: transport.grpc.OrderHandler.GetOrderStatusHistory:output_type -> transport.grpc.GetOrderStatusHistoryResponse
+	9,  // 23: transport.grpc.OrderHandler.IssueRefundOrders:output_type -> transport.grpc.IssueRefundResponse
+	12, // 24: transport.grpc.OrderHandler.GetUserOrders:output_type -> transport.grpc.GetUserOrdersResponse
+	14, // 25: transport.grpc.OrderHandler.GetRefundedOrders:output_type -> transport.grpc.GetRefundedOrdersResponse
+	16, // 26: transport.grpc.OrderHandler.GetOrderHistory:output_type -> transport.grpc.GetOrderHistoryResponse
+	18, // 27: transport.grpc.OrderHandler.GetUserActiveOrders:output_type -> transport.grpc.GetUserActiveOrdersResponse
+	20, // 28: transport.grpc.OrderHandler.GetAllActiveOrders:output_type -> transport.grpc.GetAllActiveOrdersResponse
+	22, // 29: transport.grpc.OrderHandler.GetOrderHistoryV2:output_type -> transport.grpc.GetOrderHistoryV2Response
+	20, // [20:30] is the sub-list for method output_type
+	10, // [10:20] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_order_order_proto_init() }
@@ -1200,7 +1577,7 @@ func file_order_order_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_order_order_proto_rawDesc), len(file_order_order_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   19,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,15 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderHandler_AcceptOrder_FullMethodName         = "/transport.grpc.OrderHandler/AcceptOrder"
-	OrderHandler_ReturnOrder_FullMethodName         = "/transport.grpc.OrderHandler/ReturnOrder"
-	OrderHandler_IssueRefundOrders_FullMethodName   = "/transport.grpc.OrderHandler/IssueRefundOrders"
-	OrderHandler_GetUserOrders_FullMethodName       = "/transport.grpc.OrderHandler/GetUserOrders"
-	OrderHandler_GetRefundedOrders_FullMethodName   = "/transport.grpc.OrderHandler/GetRefundedOrders"
-	OrderHandler_GetOrderHistory_FullMethodName     = "/transport.grpc.OrderHandler/GetOrderHistory"
-	OrderHandler_GetUserActiveOrders_FullMethodName = "/transport.grpc.OrderHandler/GetUserActiveOrders"
-	OrderHandler_GetAllActiveOrders_FullMethodName  = "/transport.grpc.OrderHandler/GetAllActiveOrders"
-	OrderHandler_GetOrderHistoryV2_FullMethodName   = "/transport.grpc.OrderHandler/GetOrderHistoryV2"
+	OrderHandler_AcceptOrder_FullMethodName           = "/transport.grpc.OrderHandler/AcceptOrder"
+	OrderHandler_ReturnOrder_FullMethodName           = "/transport.grpc.OrderHandler/ReturnOrder"
+	OrderHandler_GetOrderStatusHistory_FullMethodName = "/transport.grpc.OrderHandler/GetOrderStatusHistory"
+	OrderHandler_IssueRefundOrders_FullMethodName     = "/transport.grpc.OrderHandler/IssueRefundOrders"
+	OrderHandler_GetUserOrders_FullMethodName         = "/transport.grpc.OrderHandler/GetUserOrders"
+	OrderHandler_GetRefundedOrders_FullMethodName     = "/transport.grpc.OrderHandler/GetRefundedOrders"
+	OrderHandler_GetOrderHistory_FullMethodName       = "/transport.grpc.OrderHandler/GetOrderHistory"
+	OrderHandler_GetUserActiveOrders_FullMethodName   = "/transport.grpc.OrderHandler/GetUserActiveOrders"
+	OrderHandler_GetAllActiveOrders_FullMethodName    = "/transport.grpc.OrderHandler/GetAllActiveOrders"
+	OrderHandler_GetOrderHistoryV2_FullMethodName     = "/transport.grpc.OrderHandler/GetOrderHistoryV2"
 )
 
 // OrderHandlerClient is the client API for OrderHandler service.
@@ -37,6 +38,7 @@ type OrderHandlerClient interface {
 	// Orders
 	AcceptOrder(ctx context.Context, in *AcceptOrderRequest, opts ...grpc.CallOption) (*AcceptOrderResponse, error)
 	ReturnOrder(ctx context.Context, in *ReturnOrderRequest, opts ...grpc.CallOption) (*ReturnOrderResponse, error)
+	GetOrderStatusHistory(ctx context.Context, in *GetOrderStatusHistoryRequest, opts ...grpc.CallOption) (*GetOrderStatusHistoryResponse, error)
 	// Actions
 	IssueRefundOrders(ctx context.Context, in *IssueRefundRequest, opts ...grpc.CallOption) (*IssueRefundResponse, error)
 	// Reports
@@ -76,6 +78,16 @@ func (c *orderHandlerClient) ReturnOrder(ctx context.Context, in *ReturnOrderReq
 	return out, nil
 }
 
+func (c *orderHandlerClient) GetOrderStatusHistory(ctx context.Context, in *GetOrderStatusHistoryRequest, opts ...grpc.CallOption) (*GetOrderStatusHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderStatusHistoryResponse)
+	err := c.cc.Invoke(ctx, OrderHandler_GetOrderStatusHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orderHandlerClient) IssueRefundOrders(ctx context.Context, in *IssueRefundRequest, opts ...grpc.CallOption) (*IssueRefundResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(IssueRefundResponse)
@@ -153,6 +165,7 @@ type OrderHandlerServer interface {
 	// Orders
 	AcceptOrder(context.Context, *AcceptOrderRequest) (*AcceptOrderResponse, error)
 	ReturnOrder(context.Context, *ReturnOrderRequest) (*ReturnOrderResponse, error)
+	GetOrderStatusHistory(context.Context, *GetOrderStatusHistoryRequest) (*GetOrderStatusHistoryResponse, error)
 	// Actions
 	IssueRefundOrders(context.Context, *IssueRefundRequest) (*IssueRefundResponse, error)
 	// Reports
@@ -178,6 +191,9 @@ func (UnimplementedOrderHandlerServer) AcceptOrder(context.Context, *AcceptOrder
 func (UnimplementedOrderHandlerServer) ReturnOrder(context.Context, *ReturnOrderRequest) (*ReturnOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReturnOrder not implemented")
 }
+func (UnimplementedOrderHandlerServer) GetOrderStatusHistory(context.Context, *GetOrderStatusHistoryRequest) (*GetOrderStatusHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderStatusHistory not implemented")
+}
 func (UnimplementedOrderHandlerServer) IssueRefundOrders(context.Context, *IssueRefundRequest) (*IssueRefundResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method IssueRefundOrders not implemented")
 }
@@ -256,6 +272,24 @@ func _OrderHandler_ReturnOrder_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderHandler_GetOrderStatusHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderStatusHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderHandlerServer).GetOrderStatusHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderHandler_GetOrderStatusHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderHandlerServer).GetOrderStatusHistory(ctx, req.(*GetOrderStatusHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrderHandler_IssueRefundOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(IssueRefundRequest)
 	if err := dec(in); err != nil {
@@ -397,6 +431,10 @@ var OrderHandler_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReturnOrder",
 			Handler:    _OrderHandler_ReturnOrder_Handler,
 		},
+		{
+			MethodName: "GetOrderStatusHistory",
+			Handler:    _OrderHandler_GetOrderStatusHistory_Handler,
+		},
 		{
 			MethodName: "IssueRefundOrders",
 			Handler:    _OrderHandler_IssueRefundOrders_Handler,
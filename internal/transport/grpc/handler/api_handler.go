@@ -2,19 +2,17 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/order"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type OrderHandler struct {
@@ -31,7 +29,13 @@ func (h *OrderHandler) AcceptOrder(ctx context.Context, req *order.AcceptOrderRe
 	expiry, err := time.Parse("2006-01-02", req.GetExpiry())
 	if err != nil {
 		metrics.FailedOrderCount.Inc()
-		return nil, status.Errorf(codes.InvalidArgument, "неправильный формат времени: %v", err)
+		return nil, apperr.GRPC(ctx, domain.ErrInvalidTime.With("field", "expiry"))
+	}
+
+	price, err := orderPrice(req)
+	if err != nil {
+		metrics.FailedOrderCount.Inc()
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	storedAt := time.Now().UTC()
@@ -39,7 +43,7 @@ func (h *OrderHandler) AcceptOrder(ctx context.Context, req *order.AcceptOrderRe
 		ID:          req.GetId(),
 		RecipientID: req.GetRecipientId(),
 		Expiry:      expiry.Add(24 * time.Hour).UTC(),
-		BasePrice:   req.GetBasePrice(),
+		BasePrice:   price,
 		Weight:      req.GetWeight(),
 		Packaging:   domain.PackagingType(req.GetPackaging()),
 		StoredAt:    &storedAt,
@@ -47,15 +51,12 @@ func (h *OrderHandler) AcceptOrder(ctx context.Context, req *order.AcceptOrderRe
 
 	if err := h.service.AcceptOrder(ctx, orderToAccept); err != nil {
 		metrics.FailedOrderCount.Inc()
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
-	h.pipeline.SendEvent(domain.EventStatusChange, map[string]any{
-		"order_id": req.GetId(),
-		"status":   domain.StatusStored,
-	})
+	h.pipeline.SendEvent(ctx, domain.EventStatusChange, audit.StatusChanged(req.GetId(), domain.StatusStored))
 
-	metrics.ObserveOrderValue(req.GetBasePrice())
+	metrics.ObserveOrderValue(string(price.Currency), price.Float64())
 	metrics.ObserveOrderWeight(req.GetWeight())
 	metrics.IncOrdersByStatus("stored")
 
@@ -64,23 +65,47 @@ func (h *OrderHandler) AcceptOrder(ctx context.Context, req *order.AcceptOrderRe
 
 func (h *OrderHandler) ReturnOrder(ctx context.Context, req *order.ReturnOrderRequest) (*order.ReturnOrderResponse, error) {
 	if req.GetId() == "" {
-		return nil, status.Error(codes.InvalidArgument, "нужно указать order id")
+		return nil, apperr.GRPC(ctx, domain.ErrMissingField.With("field", "order id"))
 	}
 
 	if err := h.service.ReturnOrder(ctx, req.GetId()); err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
-	h.pipeline.SendEvent(domain.EventStatusChange, map[string]any{
-		"order_id": req.GetId(),
-		"status":   "Deleted",
-	})
+	h.pipeline.SendEvent(ctx, domain.EventStatusChange, audit.StatusChanged(req.GetId(), domain.StatusReturnedToCourier))
 
 	metrics.IncOrderReturns()
 
 	return &order.ReturnOrderResponse{Message: "заказ удален"}, nil
 }
 
+func (h *OrderHandler) GetOrderStatusHistory(
+	ctx context.Context,
+	req *order.GetOrderStatusHistoryRequest,
+) (*order.GetOrderStatusHistoryResponse, error) {
+	if req.GetId() == "" {
+		return nil, apperr.GRPC(ctx, domain.ErrMissingField.With("field", "order id"))
+	}
+
+	history, err := h.service.GetOrderStatusHistory(ctx, req.GetId())
+	if err != nil {
+		return nil, apperr.GRPC(ctx, err)
+	}
+
+	pbHistory := make([]*order.OrderStatusChange, 0, len(history))
+	for _, change := range history {
+		pbHistory = append(pbHistory, &order.OrderStatusChange{
+			OrderId:    change.OrderID,
+			FromStatus: string(change.From),
+			ToStatus:   string(change.To),
+			Actor:      change.Actor,
+			ChangedAt:  change.ChangedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &order.GetOrderStatusHistoryResponse{History: pbHistory}, nil
+}
+
 func (h *OrderHandler) IssueRefundOrders(ctx context.Context, req *order.IssueRefundRequest) (*order.IssueRefundResponse, error) {
 	var (
 		result      *service.IssueRefundResponse
@@ -90,24 +115,21 @@ func (h *OrderHandler) IssueRefundOrders(ctx context.Context, req *order.IssueRe
 
 	switch req.GetCommand() {
 	case "issue":
-		result, err = h.service.IssueOrders(ctx, req.GetUserId(), req.GetOrderIds())
+		result, err = h.service.IssueOrders(ctx, req.GetUserId(), req.GetOrderIds(), req.GetAtomic())
 		orderStatus = domain.StatusIssued
 	case "refund":
-		result, err = h.service.RefundOrders(ctx, req.GetUserId(), req.GetOrderIds())
+		result, err = h.service.RefundOrders(ctx, req.GetUserId(), req.GetOrderIds(), req.GetAtomic())
 		orderStatus = domain.StatusRefunded
 	default:
-		return nil, status.Error(codes.InvalidArgument, "неверная команда")
+		return nil, apperr.GRPC(ctx, domain.ErrInvalidCommand)
 	}
 
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	for _, id := range result.ProcessedOrderIDs {
-		h.pipeline.SendEvent(domain.EventStatusChange, map[string]any{
-			"order_id": id,
-			"status":   orderStatus,
-		})
+		h.pipeline.SendEvent(ctx, domain.EventStatusChange, audit.StatusChanged(id, orderStatus))
 
 		switch orderStatus {
 		case "issue":
@@ -117,19 +139,43 @@ func (h *OrderHandler) IssueRefundOrders(ctx context.Context, req *order.IssueRe
 		}
 	}
 
+	lang := apperr.ContextLang(ctx)
+	var errMessage string
+	if result.Error != nil {
+		errMessage = apperr.Message(lang, result.Error)
+	}
+
 	return &order.IssueRefundResponse{
 		ProcessedOrderIds: result.ProcessedOrderIDs,
 		FailedOrderIds:    result.FailedOrderIds,
-		Error:             result.Error,
+		Error:             errMessage,
+		Results:           mapOrderResults(apperr.LocalizeResults(lang, result.Results)),
 	}, nil
 }
 
+func mapOrderResults(results []domain.OrderResult) []*order.OrderResult {
+	pbResults := make([]*order.OrderResult, 0, len(results))
+	for _, r := range results {
+		pbResult := &order.OrderResult{
+			OrderId: r.OrderID,
+			Status:  string(r.Status),
+			Reason:  string(r.Reason),
+			Message: r.Message,
+		}
+		if r.ChangedAt != nil {
+			pbResult.ChangedAt = r.ChangedAt.Format(time.RFC3339)
+		}
+		pbResults = append(pbResults, pbResult)
+	}
+	return pbResults
+}
+
 func (h *OrderHandler) GetUserOrders(ctx context.Context, req *order.GetUserOrdersRequest) (*order.GetUserOrdersResponse, error) {
 	var cursorInt *int
 	if cursor := req.GetCursor(); cursor != "" {
 		val, err := strconv.Atoi(cursor)
 		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, "неверный формат курсора")
+			return nil, apperr.GRPC(ctx, domain.ErrInvalidCursor)
 		}
 		cursorInt = &val
 	}
@@ -143,7 +189,7 @@ func (h *OrderHandler) GetUserOrders(ctx context.Context, req *order.GetUserOrde
 	)
 
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	domainOrders := convertServiceOrdersToDomain(orders)
@@ -162,7 +208,7 @@ func (h *OrderHandler) GetRefundedOrders(
 	if cursor := req.GetCursor(); cursor != "" {
 		val, err := strconv.Atoi(cursor)
 		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, "неверный формат курсора")
+			return nil, apperr.GRPC(ctx, domain.ErrInvalidCursor)
 		}
 		cursorInt = &val
 	}
@@ -174,7 +220,7 @@ func (h *OrderHandler) GetRefundedOrders(
 	)
 
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	domainOrders := convertServiceOrdersToDomain(orders)
@@ -191,7 +237,7 @@ func (h *OrderHandler) GetOrderHistory(
 ) (*order.GetOrderHistoryResponse, error) {
 	lastUpdatedCursor, err := time.Parse(time.RFC3339, req.GetLastUpdatedCursor())
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "неверный формат курсора: %v", err)
+		return nil, apperr.GRPC(ctx, domain.ErrInvalidCursor)
 	}
 	idCursor := int(req.GetIdCursor())
 
@@ -203,7 +249,7 @@ func (h *OrderHandler) GetOrderHistory(
 	)
 
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	domainOrders := convertServiceOrdersToDomain(orders)
@@ -230,12 +276,12 @@ func (h *OrderHandler) GetUserActiveOrders(
 	req *order.GetUserActiveOrdersRequest,
 ) (*order.GetUserActiveOrdersResponse, error) {
 	if req.GetUserId() == "" {
-		return nil, status.Error(codes.InvalidArgument, "нужно указать user_id")
+		return nil, apperr.GRPC(ctx, domain.ErrMissingField.With("field", "user_id"))
 	}
 
 	orders, err := h.service.GetUserActiveOrders(ctx, req.GetUserId())
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	return &order.GetUserActiveOrdersResponse{
@@ -249,7 +295,7 @@ func (h *OrderHandler) GetAllActiveOrders(
 ) (*order.GetAllActiveOrdersResponse, error) {
 	orders, err := h.service.GetAllActiveOrders(ctx)
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	return &order.GetAllActiveOrdersResponse{
@@ -263,12 +309,21 @@ func (h *OrderHandler) GetOrderHistoryV2(
 ) (*order.GetOrderHistoryV2Response, error) {
 	orders, err := h.service.GetOrderHistoryV2(ctx)
 	if err != nil {
-		return nil, convertOrderError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	return &order.GetOrderHistoryV2Response{Orders: convertOrdersToPB(orders)}, nil
 }
 
+// orderPrice берет цену из price, а если его нет — из base_price в рублях,
+// как присылают старые клиенты.
+func orderPrice(req *order.AcceptOrderRequest) (domain.Money, error) {
+	if price := req.GetPrice(); price != nil {
+		return domain.NewMoney(price.GetMinorUnits(), domain.Currency(price.GetCurrency()))
+	}
+	return domain.ParseMoney(strconv.FormatFloat(req.GetBasePrice(), 'f', -1, 64), domain.DefaultCurrency)
+}
+
 func convertOrdersToPB(orders []domain.Order) []*order.Order {
 	pbOrders := make([]*order.Order, 0, len(orders))
 	for _, o := range orders {
@@ -276,9 +331,10 @@ func convertOrdersToPB(orders []domain.Order) []*order.Order {
 			Id:          o.ID,
 			RecipientId: o.RecipientID,
 			Expiry:      o.Expiry.Format(time.RFC3339),
-			BasePrice:   o.BasePrice,
+			BasePrice:   o.BasePrice.Float64(),
 			Weight:      o.Weight,
 			Packaging:   string(o.Packaging),
+			Price:       &order.Money{MinorUnits: o.BasePrice.MinorUnits, Currency: string(o.BasePrice.Currency)},
 		}
 
 		pbOrders = append(pbOrders, pbOrder)
@@ -286,17 +342,6 @@ func convertOrdersToPB(orders []domain.Order) []*order.Order {
 	return pbOrders
 }
 
-func convertOrderError(err error) error {
-	switch {
-	case errors.Is(err, domain.ErrDuplicateOrder):
-		return status.Error(codes.AlreadyExists, err.Error())
-	case errors.Is(err, domain.ErrDatabase):
-		return status.Error(codes.Internal, err.Error())
-	default:
-		return status.Error(codes.InvalidArgument, err.Error())
-	}
-}
-
 func convertServiceOrdersToDomain(orders []service.OrderResponse) []domain.Order {
 	domainOrders := make([]domain.Order, 0, len(orders))
 	for _, o := range orders {
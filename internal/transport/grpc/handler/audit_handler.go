@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/audit"
+)
+
+type AuditHandler struct {
+	audit.UnimplementedAuditHandlerServer
+	service service.AuditService
+}
+
+func NewAuditHandler(service service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+func (h *AuditHandler) GetAuditEvents(ctx context.Context, req *audit.GetAuditEventsRequest) (*audit.GetAuditEventsResponse, error) {
+	filter := domain.AuditEventFilter{
+		Type:      domain.EventType(req.GetType()),
+		OrderID:   req.GetOrderId(),
+		User:      req.GetUser(),
+		RequestID: req.GetRequestId(),
+		Path:      req.GetPath(),
+		Status:    req.GetStatus(),
+		Limit:     int(req.GetLimit()),
+	}
+
+	if cursor := req.GetCursor(); cursor != "" {
+		val, err := strconv.Atoi(cursor)
+		if err != nil {
+			return nil, apperr.GRPC(ctx, domain.ErrInvalidCursor)
+		}
+		filter.Cursor = &val
+	}
+
+	var err error
+	if filter.From, err = parseTime(req.GetFrom()); err != nil {
+		return nil, apperr.GRPC(ctx, domain.ErrInvalidTime.With("field", "from"))
+	}
+	if filter.To, err = parseTime(req.GetTo()); err != nil {
+		return nil, apperr.GRPC(ctx, domain.ErrInvalidTime.With("field", "to"))
+	}
+
+	events, nextCursor, err := h.service.GetEvents(ctx, filter)
+	if err != nil {
+		return nil, apperr.GRPC(ctx, err)
+	}
+
+	pbEvents := make([]*audit.AuditEvent, 0, len(events))
+	for _, e := range events {
+		pbEvents = append(pbEvents, &audit.AuditEvent{
+			Id:            int64(e.ID),
+			Type:          string(e.Type),
+			Data:          string(e.Data),
+			Time:          e.Time.Format(time.RFC3339Nano),
+			User:          e.User,
+			RequestId:     e.RequestID,
+			TraceId:       e.TraceID,
+			ClientIp:      e.ClientIP,
+			UserAgent:     e.UserAgent,
+			EventId:       e.EventID,
+			SchemaVersion: int32(e.SchemaVersion),
+		})
+	}
+
+	return &audit.GetAuditEventsResponse{
+		Events:     pbEvents,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -2,14 +2,14 @@ package handler
 
 import (
 	"context"
-	"errors"
+	"strings"
 
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/auth"
 	"go.uber.org/zap"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/metadata"
 )
 
 type AuthHandler struct {
@@ -24,7 +24,7 @@ func NewAuthHandler(service service.AuthService, logger *zap.SugaredLogger) *Aut
 
 func (h *AuthHandler) Signup(ctx context.Context, req *auth.SignupRequest) (*auth.SignupResponse, error) {
 	if req.GetEmail() == "" || req.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "нужно указать email и пароль")
+		return nil, apperr.GRPC(ctx, domain.ErrMissingField.With("field", "email, password"))
 	}
 
 	user := domain.User{
@@ -33,7 +33,7 @@ func (h *AuthHandler) Signup(ctx context.Context, req *auth.SignupRequest) (*aut
 	}
 
 	if err := h.service.Register(ctx, &user); err != nil {
-		return nil, convertAuthError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
 	return &auth.SignupResponse{Message: "пользователь зарегистрирован"}, nil
@@ -46,29 +46,56 @@ func (h *AuthHandler) Login(ctx context.Context, req *auth.LoginRequest) (*auth.
 	}
 
 	if err := h.service.Login(ctx, &user); err != nil {
-		return nil, convertAuthError(err)
+		return nil, apperr.GRPC(ctx, err)
 	}
 
-	token, err := h.service.GenerateToken(user.Email)
+	tokens, err := h.service.IssueTokens(ctx, &user)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "ошибка генерации токена")
+		return nil, apperr.GRPC(ctx, domain.ErrTokenGeneration)
 	}
 
 	return &auth.LoginResponse{
-		Message: "вход успешен",
-		Token:   token,
+		Message:      "вход успешен",
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
 
-func convertAuthError(err error) error {
-	switch {
-	case errors.Is(err, domain.ErrUserAlreadyExists):
-		return status.Error(codes.AlreadyExists, err.Error())
-	case errors.Is(err, domain.ErrInvalidCredentials):
-		return status.Error(codes.Unauthenticated, err.Error())
-	case errors.Is(err, domain.ErrDatabase), errors.Is(err, domain.ErrHashPassword):
-		return status.Error(codes.Internal, err.Error())
-	default:
-		return status.Error(codes.InvalidArgument, err.Error())
+func (h *AuthHandler) Refresh(ctx context.Context, req *auth.RefreshRequest) (*auth.RefreshResponse, error) {
+	tokens, err := h.service.Refresh(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, apperr.GRPC(ctx, err)
+	}
+
+	return &auth.RefreshResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+func (h *AuthHandler) Logout(ctx context.Context, req *auth.LogoutRequest) (*auth.LogoutResponse, error) {
+	var accessToken string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			accessToken = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+
+	if err := h.service.Logout(ctx, accessToken, req.GetRefreshToken()); err != nil {
+		return nil, apperr.GRPC(ctx, err)
 	}
+
+	return &auth.LogoutResponse{Message: "выход выполнен"}, nil
+}
+
+func (h *AuthHandler) AssignRole(ctx context.Context, req *auth.AssignRoleRequest) (*auth.AssignRoleResponse, error) {
+	if req.GetEmail() == "" || req.GetRole() == "" {
+		return nil, apperr.GRPC(ctx, domain.ErrMissingField.With("field", "email, role"))
+	}
+
+	if err := h.service.AssignRole(ctx, req.GetEmail(), domain.Role(req.GetRole())); err != nil {
+		return nil, apperr.GRPC(ctx, err)
+	}
+
+	return &auth.AssignRoleResponse{Message: "роль назначена"}, nil
 }
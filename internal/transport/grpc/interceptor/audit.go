@@ -4,17 +4,21 @@ import (
 	"context"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/schema"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/metrics"
+	eventsv1 "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/events/v1"
 	"google.golang.org/grpc"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 func AuditInterceptor(p *audit.Pipeline) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		metrics.IncHTTPRequest(info.FullMethod)
 
-		p.SendEvent(domain.EventAPIRequest, map[string]any{
-			"method": info.FullMethod,
+		p.SendEvent(ctx, domain.EventAPIRequest, &eventsv1.ApiRequest{
+			Transport: schema.TransportGRPC,
+			Method:    info.FullMethod,
 		})
 
 		resp, err := handler(ctx, req)
@@ -26,9 +30,10 @@ func AuditInterceptor(p *audit.Pipeline) grpc.UnaryServerInterceptor {
 
 		metrics.IncHTTPResponse(status, info.FullMethod)
 
-		p.SendEvent(domain.EventAPIResponse, map[string]any{
-			"method": info.FullMethod,
-			"status": status,
+		p.SendEvent(ctx, domain.EventAPIResponse, &eventsv1.ApiResponse{
+			Transport: schema.TransportGRPC,
+			Method:    info.FullMethod,
+			GrpcCode:  grpcstatus.Code(err).String(),
 		})
 
 		return resp, err
@@ -4,57 +4,82 @@ import (
 	"context"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/status"
 )
 
-func AuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	if shouldSkipAuth(info.FullMethod) {
-		return handler(ctx, req)
-	}
+// Права, необходимые для вызова метода. Метод, которого нет в списке,
+// доступен только администратору.
+var methodPermissions = map[string]domain.Permission{
+	"/transport.grpc.OrderHandler/AcceptOrder":           domain.PermissionAcceptOrder,
+	"/transport.grpc.OrderHandler/ReturnOrder":           domain.PermissionReturnOrder,
+	"/transport.grpc.OrderHandler/GetOrderStatusHistory": domain.PermissionViewOrder,
+	"/transport.grpc.OrderHandler/IssueRefundOrders":     domain.PermissionIssueRefund,
+	"/transport.grpc.OrderHandler/GetUserOrders":         domain.PermissionViewReports,
+	"/transport.grpc.OrderHandler/GetRefundedOrders":     domain.PermissionViewReports,
+	"/transport.grpc.OrderHandler/GetOrderHistory":       domain.PermissionViewReports,
+	"/transport.grpc.OrderHandler/GetUserActiveOrders":   domain.PermissionViewReports,
+	"/transport.grpc.OrderHandler/GetAllActiveOrders":    domain.PermissionViewReports,
+	"/transport.grpc.OrderHandler/GetOrderHistoryV2":     domain.PermissionViewReports,
+	"/transport.grpc.auth.AuthHandler/AssignRole":        domain.PermissionManageUsers,
+	"/transport.grpc.audit.AuditHandler/GetAuditEvents":  domain.PermissionViewAudit,
+}
 
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "отсутствуют метаданные")
-	}
+func AuthInterceptor(authService service.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if shouldSkipAuth(info.FullMethod) {
+			return handler(ctx, req)
+		}
 
-	tokenString, err := extractTokenFromMetadata(md)
-	if err != nil {
-		return nil, err
-	}
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return nil, apperr.GRPC(ctx, domain.ErrUnauthenticated)
+		}
 
-	token, err := jwt.Parse(tokenString, jwtKeyFunc)
-	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "ошибка проверки токена: %v", err)
-	}
-	if !token.Valid {
-		return nil, status.Error(codes.Unauthenticated, "неверный токен")
-	}
+		tokenString, err := extractTokenFromMetadata(md)
+		if err != nil {
+			return nil, apperr.GRPC(ctx, err)
+		}
+
+		claims, err := authService.ParseToken(ctx, tokenString)
+		if err != nil {
+			return nil, apperr.GRPC(ctx, domain.ErrInvalidToken)
+		}
 
-	return handler(ctx, req)
+		if !isAllowed(claims.Role, info.FullMethod) {
+			return nil, apperr.GRPC(ctx, domain.ErrForbidden)
+		}
+
+		return handler(domain.ContextWithActor(ctx, claims.Email), req)
+	}
 }
 
 func shouldSkipAuth(fullMethod string) bool {
 	skipMethods := map[string]bool{
-		"/transport.grpc.auth.AuthHandler/Login":  true,
-		"/transport.grpc.auth.AuthHandler/Signup": true,
+		"/transport.grpc.auth.AuthHandler/Login":   true,
+		"/transport.grpc.auth.AuthHandler/Signup":  true,
+		"/transport.grpc.auth.AuthHandler/Refresh": true,
+		"/transport.grpc.auth.AuthHandler/Logout":  true,
 	}
 	return skipMethods[fullMethod]
 }
 
+func isAllowed(role domain.Role, fullMethod string) bool {
+	permission, ok := methodPermissions[fullMethod]
+	if !ok {
+		return role == domain.RoleAdmin
+	}
+	return role.HasPermission(permission)
+}
+
 func extractTokenFromMetadata(md metadata.MD) (string, error) {
 	authHeader := md.Get("authorization")
 	if len(authHeader) == 0 {
-		return "", status.Error(codes.Unauthenticated, "отсутствует заголовок авторизации")
+		return "", domain.ErrUnauthenticated
 	}
 
 	return strings.TrimPrefix(authHeader[0], "Bearer "), nil
 }
-
-func jwtKeyFunc(token *jwt.Token) (any, error) {
-	return []byte(domain.JwtSecret), nil
-}
@@ -0,0 +1,155 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const idempotencyKey = "idempotency-key"
+
+// Изменяющие методы, которые поддерживают ключ идемпотентности.
+var idempotentMethods = map[string]bool{
+	"/transport.grpc.OrderHandler/AcceptOrder":       true,
+	"/transport.grpc.OrderHandler/ReturnOrder":       true,
+	"/transport.grpc.OrderHandler/IssueRefundOrders": true,
+}
+
+// Коды, с которыми ответ не сохраняется: запрос можно повторить с тем же
+// ключом.
+var retryableCodes = map[codes.Code]bool{
+	codes.Canceled:          true,
+	codes.Unknown:           true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Aborted:           true,
+	codes.Internal:          true,
+	codes.Unavailable:       true,
+}
+
+// IdempotencyInterceptor отдает на повтор вызова с метаданными
+// idempotency-key ответ первого вызова. Ответ вызова, выполнявшегося дольше
+// блокировки ключа, не сохраняется: ключ мог занять повтор. Ставится после
+// AuthInterceptor, потому что ключи хранятся отдельно для каждого
+// пользователя.
+func IdempotencyInterceptor(idempotency service.IdempotencyService, logger *zap.SugaredLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := firstValue(md, idempotencyKey)
+		if key == "" || !idempotentMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		reqMsg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+		if err != nil {
+			return nil, apperr.GRPC(ctx, domain.ErrWrongJSON)
+		}
+
+		idemReq := domain.IdempotentRequest{
+			Scope:       "grpc",
+			Key:         key,
+			Fingerprint: service.IdempotencyFingerprint([]byte(info.FullMethod), body),
+		}
+
+		saved, token, err := idempotency.Begin(ctx, idemReq)
+		switch {
+		case errors.Is(err, domain.ErrInvalidIdempotencyKey),
+			errors.Is(err, domain.ErrIdempotencyKeyReused),
+			errors.Is(err, domain.ErrIdempotencyInProgress):
+			return nil, apperr.GRPC(ctx, err)
+		case err != nil:
+			logger.Errorw("failed to check idempotency key", "error", err)
+			return nil, apperr.GRPC(ctx, domain.ErrIdempotencyUnavailable)
+		case saved != nil:
+			_ = grpc.SetHeader(ctx, metadata.Pairs("idempotent-replayed", "true"))
+			return replay(ctx, saved)
+		}
+
+		resp, handlerErr := handler(ctx, req)
+
+		finishCtx, cancel := service.IdempotencyFinishContext(ctx)
+		defer cancel()
+
+		code := status.Code(handlerErr)
+		if retryableCodes[code] {
+			if err := idempotency.Abort(finishCtx, idemReq, token); err != nil {
+				logger.Errorw("failed to release idempotency key", "error", err)
+			}
+			return resp, handlerErr
+		}
+
+		response, err := savedResponse(resp, handlerErr)
+		if err != nil {
+			logger.Errorw("failed to encode idempotent response", "error", err)
+			_ = idempotency.Abort(finishCtx, idemReq, token)
+			return resp, handlerErr
+		}
+		if err := idempotency.Finish(finishCtx, idemReq, token, response); err != nil {
+			logger.Errorw("failed to save idempotent response", "error", err)
+		}
+		return resp, handlerErr
+	}
+}
+
+// savedResponse кодирует ответ для сохранения. Для ошибки сохраняется
+// статус целиком, вместе с деталями.
+func savedResponse(resp any, handlerErr error) (domain.IdempotentResponse, error) {
+	if handlerErr != nil {
+		st := status.Convert(handlerErr)
+		body, err := proto.Marshal(st.Proto())
+		if err != nil {
+			return domain.IdempotentResponse{}, err
+		}
+		return domain.IdempotentResponse{Code: int(st.Code()), Message: st.Message(), Body: body}, nil
+	}
+
+	respMsg, ok := resp.(proto.Message)
+	if !ok {
+		return domain.IdempotentResponse{}, errors.New("ответ не сообщение proto")
+	}
+	body, err := proto.Marshal(respMsg)
+	if err != nil {
+		return domain.IdempotentResponse{}, err
+	}
+	return domain.IdempotentResponse{
+		Code:        int(codes.OK),
+		ContentType: string(respMsg.ProtoReflect().Descriptor().FullName()),
+		Body:        body,
+	}, nil
+}
+
+func replay(ctx context.Context, saved *domain.IdempotentResponse) (any, error) {
+	if code := codes.Code(saved.Code); code != codes.OK {
+		st := &spb.Status{}
+		if len(saved.Body) == 0 || proto.Unmarshal(saved.Body, st) != nil {
+			return nil, status.Error(code, saved.Message)
+		}
+		return nil, status.ErrorProto(st)
+	}
+
+	msgType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(saved.ContentType))
+	if err != nil {
+		return nil, apperr.GRPC(ctx, domain.ErrInternal)
+	}
+	resp := msgType.New().Interface()
+	if err := proto.Unmarshal(saved.Body, resp); err != nil {
+		return nil, apperr.GRPC(ctx, domain.ErrInternal)
+	}
+	return resp, nil
+}
@@ -0,0 +1,59 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const requestIDKey = "x-request-id"
+
+// RequestInfoInterceptor сохраняет в контексте данные запроса для аудита.
+// Должен стоять после otelgrpc, чтобы span запроса уже был открыт.
+func RequestInfoInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := domain.RequestIDOrNew(firstValue(md, requestIDKey))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	var traceID string
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		traceID = spanContext.TraceID().String()
+	}
+
+	var clientIP string
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = hostOnly(p.Addr.String())
+	}
+
+	ctx = domain.ContextWithRequestInfo(ctx, domain.RequestInfo{
+		RequestID: requestID,
+		TraceID:   traceID,
+		ClientIP:  clientIP,
+		UserAgent: firstValue(md, "user-agent"),
+	})
+
+	return handler(ctx, req)
+}
+
+// hostOnly убирает порт из адреса клиента, чтобы IP в аудите совпадал с REST.
+// Адрес без порта (например, unix-сокет) возвращается как есть.
+func hostOnly(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
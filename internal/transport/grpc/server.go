@@ -1,10 +1,12 @@
 package grpc
 
 import (
+	"context"
 	"net"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	auditpb "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/auth"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/order"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/handler"
@@ -22,23 +24,29 @@ type Server struct {
 func NewServer(
 	orderService service.OrderService,
 	authService service.AuthService,
+	auditService service.AuditService,
+	idempotencyService service.IdempotencyService,
 	auditPipeline *audit.Pipeline,
 	logger *zap.SugaredLogger,
 ) *Server {
 	interceptors := grpc.ChainUnaryInterceptor(
+		otelgrpc.UnaryServerInterceptor(),
+		interceptor.RequestInfoInterceptor,
 		interceptor.MetricsInterceptor,
-		interceptor.AuthInterceptor,
+		interceptor.AuthInterceptor(authService),
 		interceptor.AuditInterceptor(auditPipeline),
-		otelgrpc.UnaryServerInterceptor(),
+		interceptor.IdempotencyInterceptor(idempotencyService, logger),
 	)
 
 	grpcServer := grpc.NewServer(interceptors)
 
 	orderHandler := handler.NewOrderHandler(orderService, auditPipeline)
 	authHandler := handler.NewAuthHandler(authService, logger)
+	auditHandler := handler.NewAuditHandler(auditService)
 
 	order.RegisterOrderHandlerServer(grpcServer, orderHandler)
 	auth.RegisterAuthHandlerServer(grpcServer, authHandler)
+	auditpb.RegisterAuditHandlerServer(grpcServer, auditHandler)
 
 	return &Server{
 		server: grpcServer,
@@ -55,6 +63,19 @@ func (s *Server) Run(port string) error {
 	return s.server.Serve(lis)
 }
 
-func (s *Server) Stop() {
-	s.server.GracefulStop()
+// GracefulStop перестает принимать новые вызовы и ждет завершения текущих.
+// Если ctx истек раньше, оставшиеся вызовы прерываются.
+func (s *Server) GracefulStop(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.server.GracefulStop()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.server.Stop()
+		<-done
+	}
 }
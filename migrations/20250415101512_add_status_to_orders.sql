@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN status VARCHAR(32);
+
+UPDATE orders SET status = CASE
+    WHEN refunded_at IS NOT NULL AND refunded_at >= COALESCE(issued_at, '0001-01-01'::timestamp) THEN 'refunded'
+    WHEN issued_at IS NOT NULL THEN 'issued'
+    ELSE 'stored'
+END;
+
+ALTER TABLE orders ALTER COLUMN status SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE order_status_history(
+    id BIGSERIAL PRIMARY KEY,
+    order_id VARCHAR(36) NOT NULL,
+    from_status VARCHAR(32),
+    to_status VARCHAR(32) NOT NULL,
+    actor VARCHAR(255),
+    changed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id, changed_at);
+
+INSERT INTO order_status_history (order_id, from_status, to_status, changed_at)
+SELECT order_id, NULL, 'stored', stored_at FROM orders WHERE stored_at IS NOT NULL
+UNION ALL
+SELECT order_id, 'stored', 'issued', issued_at FROM orders WHERE issued_at IS NOT NULL
+UNION ALL
+SELECT order_id, 'issued', 'refunded', refunded_at FROM orders WHERE refunded_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_status_history;
+-- +goose StatementEnd
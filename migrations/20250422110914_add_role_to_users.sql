@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'guest'
+    CHECK (role IN ('guest', 'operator', 'manager', 'admin'));
+
+-- Пользователи, зарегистрированные до появления ролей, имели права оператора
+UPDATE users SET role = 'operator';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN role;
+-- +goose StatementEnd
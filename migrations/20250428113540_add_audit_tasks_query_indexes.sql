@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_audit_tasks_type ON audit_tasks ((audit_log->>'Type'), id);
+CREATE INDEX idx_audit_tasks_order_id ON audit_tasks ((audit_log->'Data'->>'order_id'), id);
+CREATE INDEX idx_audit_tasks_user ON audit_tasks ((audit_log->>'User'), id);
+CREATE INDEX idx_audit_tasks_path ON audit_tasks ((audit_log->'Data'->>'path'), id);
+CREATE INDEX idx_audit_tasks_created_at ON audit_tasks (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_tasks_created_at;
+DROP INDEX IF EXISTS idx_audit_tasks_path;
+DROP INDEX IF EXISTS idx_audit_tasks_user;
+DROP INDEX IF EXISTS idx_audit_tasks_order_id;
+DROP INDEX IF EXISTS idx_audit_tasks_type;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_audit_tasks_request_id ON audit_tasks ((audit_log->>'RequestID'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_tasks_request_id;
+-- +goose StatementEnd
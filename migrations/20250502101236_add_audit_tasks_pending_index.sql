@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_audit_tasks_pending ON audit_tasks (created_at)
+WHERE status IN ('CREATED', 'FAILED', 'PROCESSING');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_tasks_pending;
+-- +goose StatementEnd
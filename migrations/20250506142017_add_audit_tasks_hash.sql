@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit_tasks ADD COLUMN hash BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_tasks DROP COLUMN IF EXISTS hash;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Партиции по месяцам создает consumer перед первой записью в месяц
+CREATE TABLE audit_events (
+    task_id INT NOT NULL,
+    type TEXT NOT NULL,
+    data JSONB,
+    event_time TIMESTAMP NOT NULL,
+    actor TEXT,
+    request_id TEXT,
+    trace_id TEXT,
+    client_ip TEXT,
+    user_agent TEXT,
+    consumed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, event_time)
+) PARTITION BY RANGE (event_time);
+
+CREATE INDEX idx_audit_events_type_time ON audit_events (type, event_time);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor, event_time);
+CREATE INDEX idx_audit_events_request_id ON audit_events (request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
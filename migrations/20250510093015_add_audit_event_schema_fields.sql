@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- У событий, записанных до появления схем, event_id нет, а schema_version = 0
+ALTER TABLE audit_events
+    ADD COLUMN event_id UUID,
+    ADD COLUMN schema_version SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_audit_events_event_id ON audit_events (event_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_events_event_id;
+ALTER TABLE audit_events
+    DROP COLUMN IF EXISTS schema_version,
+    DROP COLUMN IF EXISTS event_id;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Задачи без topic — события аудита (топик аудита, ключ — ID задачи).
+-- События заказов пишутся с topic и message_key = ID заказа.
+ALTER TABLE audit_tasks
+    ADD COLUMN topic TEXT,
+    ADD COLUMN message_key TEXT;
+
+-- Для проверки, что более ранние события заказа уже отправлены
+CREATE INDEX idx_audit_tasks_message_key ON audit_tasks (message_key, id)
+    WHERE message_key IS NOT NULL AND status IN ('CREATED', 'FAILED', 'PROCESSING');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_audit_tasks_message_key;
+DELETE FROM audit_tasks WHERE topic IS NOT NULL;
+ALTER TABLE audit_tasks
+    DROP COLUMN IF EXISTS message_key,
+    DROP COLUMN IF EXISTS topic;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- base_price хранится в валюте заказа, цены упаковок — в рублях
+ALTER TABLE orders
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'RUB';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS currency;
+-- +goose StatementEnd
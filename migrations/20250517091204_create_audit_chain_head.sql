@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Голова цепочки хешей audit_tasks: id и хеш последнего звена. Строка одна,
+-- ее блокировка упорядочивает запись в цепочку.
+CREATE TABLE audit_chain_head(
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_id INT NOT NULL DEFAULT 0,
+    hash BYTEA,
+    updated_at TIMESTAMP
+);
+
+INSERT INTO audit_chain_head (last_id, hash, updated_at)
+SELECT COALESCE(last.id, 0), last.hash, NOW()
+FROM (SELECT 1) AS one
+LEFT JOIN (
+    SELECT id, hash
+    FROM audit_tasks
+    WHERE hash IS NOT NULL
+    ORDER BY id DESC
+    LIMIT 1
+) AS last ON TRUE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_chain_head;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Повторное использование проверяется только для токенов, обменянных в
+-- Refresh. Токены, отозванные до появления колонки, считаются обменянными
+ALTER TABLE refresh_tokens
+    ADD COLUMN revoke_reason VARCHAR(16);
+UPDATE refresh_tokens SET revoke_reason = 'rotated' WHERE revoked_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS revoke_reason;
+-- +goose StatementEnd
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/api"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/orderevents"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/authrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/orderrepo"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/reportrepo"
@@ -318,8 +319,8 @@ func setupRouter(db *pgxpool.Pool) *gin.Engine {
 	sugarLogger := logger.Sugar()
 	defer sugarLogger.Sync()
 
-	orderStorage := orderstorage.NewOrderStorage(db)
-	userOrderStorage := userorder.NewUserOrderStorage(db)
+	orderStorage := orderstorage.NewOrderStorage(db, orderevents.Marshal)
+	userOrderStorage := userorder.NewUserOrderStorage(db, orderevents.Marshal)
 	reportOrderStorage := reportorder.NewReportOrderStorage(db)
 	authStorage := authstorage.NewAuthStorage(db)
 
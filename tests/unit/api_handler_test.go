@@ -12,8 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/api"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
 )
 
 type MockOrderService struct {
@@ -30,13 +32,13 @@ func (m *MockOrderService) ReturnOrder(ctx context.Context, orderID string) erro
 	return args.Error(0)
 }
 
-func (m *MockOrderService) IssueOrders(ctx context.Context, userID string, orderIDs []string) (*service.IssueRefundResponse, error) {
-	args := m.Called(ctx, userID, orderIDs)
+func (m *MockOrderService) IssueOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (*service.IssueRefundResponse, error) {
+	args := m.Called(ctx, userID, orderIDs, atomic)
 	return args.Get(0).(*service.IssueRefundResponse), args.Error(1)
 }
 
-func (m *MockOrderService) RefundOrders(ctx context.Context, userID string, orderIDs []string) (*service.IssueRefundResponse, error) {
-	args := m.Called(ctx, userID, orderIDs)
+func (m *MockOrderService) RefundOrders(ctx context.Context, userID string, orderIDs []string, atomic bool) (*service.IssueRefundResponse, error) {
+	args := m.Called(ctx, userID, orderIDs, atomic)
 	return args.Get(0).(*service.IssueRefundResponse), args.Error(1)
 }
 
@@ -55,9 +57,51 @@ func (m *MockOrderService) GetOrderHistory(ctx context.Context, limit int, lastU
 	return args.Get(0).([]service.OrderResponse), args.String(1), args.Error(2)
 }
 
+func (m *MockOrderService) GetUserActiveOrders(ctx context.Context, userID string) ([]domain.Order, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetAllActiveOrders(ctx context.Context) ([]domain.Order, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrderHistoryV2(ctx context.Context) ([]domain.Order, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrderStatusHistory(ctx context.Context, orderID string) ([]domain.OrderStatusChange, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]domain.OrderStatusChange), args.Error(1)
+}
+
+func (m *MockOrderService) ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error) {
+	args := m.Called(ctx, limit, dryRun)
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderService) CacheRefresh(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func (m *MockOrderService) InitCache(ctx context.Context) {
+	m.Called(ctx)
+}
+
+// newPipeline — конвейер аудита без приемников: события остаются в очереди.
+func newPipeline() *audit.Pipeline {
+	return audit.NewPipeline(
+		audit.QueueConfig{Capacity: 10, Policy: audit.OverflowDropOldest},
+		audit.BatchConfig{Size: 1, FlushInterval: time.Second},
+		zap.NewNop().Sugar(),
+	)
+}
+
 func TestAPIHandler_AcceptOrder_Success(t *testing.T) {
 	mockService := new(MockOrderService)
-	handler := api.NewAPIHandler(mockService)
+	handler := api.NewAPIHandler(mockService, newPipeline())
 
 	//expiryTime := time.Now().Add(24 * time.Hour).UTC()
 	mockService.On("AcceptOrder", mock.Anything, mock.MatchedBy(func(order domain.Order) bool {
@@ -87,7 +131,7 @@ func TestAPIHandler_AcceptOrder_Success(t *testing.T) {
 }
 
 func TestAPIHandler_AcceptOrder_InvalidExpiry(t *testing.T) {
-	handler := api.NewAPIHandler(nil)
+	handler := api.NewAPIHandler(nil, newPipeline())
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -113,7 +157,7 @@ func TestAPIHandler_AcceptOrder_InvalidExpiry(t *testing.T) {
 
 func TestAPIHandler_ReturnOrder_ServiceError(t *testing.T) {
 	mockService := new(MockOrderService)
-	handler := api.NewAPIHandler(mockService)
+	handler := api.NewAPIHandler(mockService, newPipeline())
 
 	mockService.On("ReturnOrder", mock.Anything, "123").Return(domain.ErrDatabase)
 
@@ -130,7 +174,7 @@ func TestAPIHandler_ReturnOrder_ServiceError(t *testing.T) {
 
 func TestAPIHandler_GetUserOrders_Success(t *testing.T) {
 	mockService := new(MockOrderService)
-	handler := api.NewAPIHandler(mockService)
+	handler := api.NewAPIHandler(mockService, newPipeline())
 
 	mockService.On("GetUserOrders", mock.Anything, "user1", 10, (*int)(nil), "").
 		Return([]service.OrderResponse{
@@ -151,7 +195,7 @@ func TestAPIHandler_GetUserOrders_Success(t *testing.T) {
 }
 
 func TestAPIHandler_IssueRefundOrders_InvalidCommand(t *testing.T) {
-	handler := api.NewAPIHandler(nil)
+	handler := api.NewAPIHandler(nil, newPipeline())
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -173,7 +217,7 @@ func TestAPIHandler_IssueRefundOrders_InvalidCommand(t *testing.T) {
 }
 
 func TestAPIHandler_GetRefundedOrders_InvalidLimit(t *testing.T) {
-	handler := api.NewAPIHandler(nil)
+	handler := api.NewAPIHandler(nil, newPipeline())
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
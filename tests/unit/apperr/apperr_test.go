@@ -0,0 +1,133 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/apperr"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCatalog_NoInternalStatusForClientErrors(t *testing.T) {
+	internal := map[domain.ErrorCode]bool{
+		domain.CodeDatabase:        true,
+		domain.CodeCache:           true,
+		domain.CodeHashPassword:    true,
+		domain.CodeTokenGeneration: true,
+		domain.CodeInternal:        true,
+	}
+
+	for _, code := range apperr.Codes() {
+		err := domain.NewError(code, "ошибка")
+		httpStatus, body := apperr.HTTP(apperr.LangEN, err)
+		grpcStatus, _ := status.FromError(apperr.GRPC(context.Background(), err))
+
+		assert.Equal(t, code, body.Code)
+		assert.NotEmpty(t, body.Error, code)
+		if internal[code] {
+			assert.Equal(t, http.StatusInternalServerError, httpStatus, code)
+			assert.Equal(t, codes.Internal, grpcStatus.Code(), code)
+			continue
+		}
+		assert.NotEqual(t, http.StatusInternalServerError, httpStatus, code)
+		assert.NotEqual(t, codes.Internal, grpcStatus.Code(), code)
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	tests := []struct {
+		header string
+		want   apperr.Lang
+	}{
+		{"", apperr.LangRU},
+		{"ru-RU,ru;q=0.9", apperr.LangRU},
+		{"en-US,en;q=0.9", apperr.LangEN},
+		{"de-DE,en;q=0.5", apperr.LangEN},
+		{"fr", apperr.LangRU},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, apperr.ParseLang(tt.header), tt.header)
+	}
+}
+
+func TestHTTP_WrappedErrorWithParams(t *testing.T) {
+	err := fmt.Errorf("issue: %w", &domain.ErrUserDoesntOwnOrder{OrderID: "1", UserID: "2"})
+
+	code, body := apperr.HTTP(apperr.LangEN, err)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, domain.CodeOrderNotOwned, body.Code)
+	assert.Equal(t, "order 1 does not belong to user 2", body.Error)
+	assert.Equal(t, map[string]string{"order_id": "1", "user_id": "2"}, body.Params)
+
+	_, body = apperr.HTTP(apperr.LangRU, err)
+	assert.Equal(t, "Заказ 1 не принадлежит пользователю 2", body.Error)
+}
+
+func TestHTTP_UnknownErrorIsInternal(t *testing.T) {
+	code, body := apperr.HTTP(apperr.LangRU, errors.New("pq: connection refused"))
+
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Equal(t, domain.CodeInternal, body.Code)
+	assert.Equal(t, domain.ErrInternal.Error(), body.Error)
+}
+
+func TestWith_KeepsSentinel(t *testing.T) {
+	err := domain.ErrMissingField.With("field", "user_id")
+
+	assert.ErrorIs(t, err, domain.ErrMissingField)
+	assert.Equal(t, "нужно указать user_id", err.Error())
+	assert.Equal(t, "user_id is required", apperr.Message(apperr.LangEN, err))
+	assert.Empty(t, domain.ErrMissingField.Params())
+}
+
+func TestGRPC_Details(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "en"))
+
+	err := apperr.GRPC(ctx, domain.ErrNotFoundOrder)
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "order with this ID does not exist", st.Message())
+
+	var info *errdetails.ErrorInfo
+	var localized *errdetails.LocalizedMessage
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.LocalizedMessage:
+			localized = d
+		}
+	}
+	require.NotNil(t, info)
+	require.NotNil(t, localized)
+	assert.Equal(t, string(domain.CodeOrderNotFound), info.Reason)
+	assert.Equal(t, apperr.ErrorDomain, info.Domain)
+	assert.Equal(t, "en", localized.Locale)
+	assert.Equal(t, st.Message(), localized.Message)
+}
+
+func TestLocalizeResults(t *testing.T) {
+	results := []domain.OrderResult{
+		{OrderID: "1", Status: domain.OrderResultProcessed},
+		{OrderID: "2", Status: domain.OrderResultFailed, Reason: domain.ReasonNotStored,
+			Message: domain.ErrNotStoredOrder.Error(), Err: domain.ErrNotStoredOrder},
+	}
+
+	localized := apperr.LocalizeResults(apperr.LangEN, results)
+
+	assert.Empty(t, localized[0].Message)
+	assert.Equal(t, "the order is not at the pickup point", localized[1].Message)
+	assert.Equal(t, domain.ErrNotStoredOrder.Error(), results[1].Message)
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+func TestNewFilterFunc(t *testing.T) {
+	event := domain.Event{
+		Type: domain.EventStatusChange,
+		User: "manager@example.com",
+		Data: audit.StatusChanged("42", domain.StatusIssued),
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"", true},
+		{"   ", true},
+		{`type == "status_change"`, true},
+		{`data.status in ["issued", "refunded"]`, true},
+		{`data.order_id == "42" && user endsWith "@example.com"`, true},
+		{`data.status == "refunded"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filterFunc, err := audit.NewFilterFunc(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, filterFunc(event))
+		})
+	}
+}
+
+func TestNewFilterFunc_RejectsOldKeywordSyntax(t *testing.T) {
+	tests := []string{
+		// Ключевые слова старого формата
+		"issued",
+		"status_change",
+		"500",
+		`"issued"`,
+		"/orders",
+		// Опечатка в имени поля
+		`stauts == "issued"`,
+		// Выражение без полей
+		"true",
+	}
+
+	for _, expression := range tests {
+		t.Run(expression, func(t *testing.T) {
+			_, err := audit.NewFilterFunc(expression)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+// outboxService — задачи outbox в памяти. ListenNewTasks отдает функцию
+// уведомления в канал listeners.
+type outboxService struct {
+	service.AuditService
+	mu        sync.Mutex
+	pending   []domain.AuditTask
+	claims    []int
+	finished  []int
+	updated   []domain.AuditTask
+	listeners chan func()
+}
+
+func newOutboxService(count int) *outboxService {
+	s := &outboxService{listeners: make(chan func(), 1)}
+	s.add(count)
+	return s
+}
+
+// add добавляет count новых задач.
+func (s *outboxService) add(count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range count {
+		id := len(s.pending) + len(s.finished) + len(s.updated) + 1
+		s.pending = append(s.pending, domain.AuditTask{ID: id, AuditLog: []byte(strconv.Itoa(id))})
+	}
+}
+
+func (s *outboxService) ClaimPendingTasks(_ context.Context, limit int, _ time.Duration) ([]domain.AuditTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := min(limit, len(s.pending))
+	tasks := s.pending[:n:n]
+	s.pending = s.pending[n:]
+	s.claims = append(s.claims, n)
+	return tasks, nil
+}
+
+func (s *outboxService) FinishTasks(_ context.Context, ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = append(s.finished, ids...)
+	return nil
+}
+
+func (s *outboxService) UpdateTask(_ context.Context, task domain.AuditTask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updated = append(s.updated, task)
+	return nil
+}
+
+func (s *outboxService) ListenNewTasks(ctx context.Context, notify func()) error {
+	s.listeners <- notify
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (s *outboxService) CountDeadTasks(context.Context) (int, error) { return 0, nil }
+
+func (s *outboxService) OldestPendingTaskAge(context.Context) (time.Duration, error) { return 0, nil }
+
+func (s *outboxService) state() (claims, finished []int, updated []domain.AuditTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.claims...), append([]int(nil), s.finished...), append([]domain.AuditTask(nil), s.updated...)
+}
+
+// outboxProducer запоминает отправленные записи. Пачки отклоняются при
+// failBatch, отдельные записи — по значению из failValues.
+type outboxProducer struct {
+	mu         sync.Mutex
+	failBatch  bool
+	failValues map[string]bool
+	sent       []string
+}
+
+func (p *outboxProducer) SendTransactional(_ context.Context, message kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failValues[string(message.Value)] {
+		return errors.New("kafka unavailable")
+	}
+	p.sent = append(p.sent, string(message.Value))
+	return nil
+}
+
+func (p *outboxProducer) SendTransactionalBatch(_ context.Context, messages []kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failBatch {
+		return errors.New("transaction aborted")
+	}
+	for _, message := range messages {
+		p.sent = append(p.sent, string(message.Value))
+	}
+	return nil
+}
+
+func (p *outboxProducer) sentValues() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.sent...)
+}
+
+// startOutbox запускает воркер с одним обработчиком и опросом раз в час,
+// чтобы задачи забирались только при старте и по уведомлению.
+func startOutbox(t *testing.T, svc *outboxService, producer *outboxProducer, batchSize int) {
+	t.Helper()
+	worker := audit.NewOutboxWorker(
+		svc,
+		[]audit.TaskProducer{producer},
+		zap.NewNop().Sugar(),
+		audit.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Second, MaxDelay: time.Second},
+		time.Hour,
+		batchSize,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestOutboxWorker_DrainsFullBatches(t *testing.T) {
+	svc := newOutboxService(5)
+	producer := &outboxProducer{}
+	startOutbox(t, svc, producer, 2)
+
+	require.Eventually(t, func() bool { return len(producer.sentValues()) == 5 }, time.Second, 5*time.Millisecond)
+
+	// Полные пачки забираются подряд, неполная завершает разбор
+	claims, finished, _ := svc.state()
+	assert.Equal(t, []int{2, 2, 1}, claims)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, finished)
+}
+
+func TestOutboxWorker_StopsDrainingOnEmptyBatch(t *testing.T) {
+	svc := newOutboxService(4)
+	producer := &outboxProducer{}
+	startOutbox(t, svc, producer, 2)
+
+	require.Eventually(t, func() bool { return len(producer.sentValues()) == 4 }, time.Second, 5*time.Millisecond)
+
+	// После двух полных пачек пустая завершает разбор до следующего пробуждения
+	assert.Never(t, func() bool {
+		claims, _, _ := svc.state()
+		return len(claims) > 3
+	}, 50*time.Millisecond, 5*time.Millisecond)
+	claims, _, _ := svc.state()
+	assert.Equal(t, []int{2, 2, 0}, claims)
+}
+
+func TestOutboxWorker_WakesUpOnNotify(t *testing.T) {
+	svc := newOutboxService(0)
+	producer := &outboxProducer{}
+	startOutbox(t, svc, producer, 10)
+
+	var notify func()
+	select {
+	case notify = <-svc.listeners:
+	case <-time.After(time.Second):
+		t.Fatal("воркер не подписался на уведомления")
+	}
+	require.Eventually(t, func() bool {
+		claims, _, _ := svc.state()
+		return len(claims) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Опрос раз в час: задачи забираются только по уведомлению
+	svc.add(3)
+	notify()
+
+	require.Eventually(t, func() bool { return len(producer.sentValues()) == 3 }, time.Second, 5*time.Millisecond)
+	_, finished, _ := svc.state()
+	assert.Equal(t, []int{1, 2, 3}, finished)
+}
+
+func TestOutboxWorker_FallsBackToSingleTasks(t *testing.T) {
+	svc := newOutboxService(3)
+	svc.pending[2].AttemptNumber = 1
+	producer := &outboxProducer{failBatch: true, failValues: map[string]bool{"2": true, "3": true}}
+	startOutbox(t, svc, producer, 10)
+
+	require.Eventually(t, func() bool {
+		_, _, updated := svc.state()
+		return len(updated) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	// Пачка не ушла: задачи отправлены по одной, у каждой свой счетчик попыток
+	_, finished, updated := svc.state()
+	assert.Empty(t, finished)
+	assert.Equal(t, []string{"1"}, producer.sentValues())
+
+	assert.Equal(t, domain.StatusFinished, updated[0].Status)
+	assert.Equal(t, 0, updated[0].AttemptNumber)
+
+	assert.Equal(t, domain.StatusFailed, updated[1].Status)
+	assert.Equal(t, 1, updated[1].AttemptNumber)
+	assert.True(t, updated[1].NextRetry.After(time.Now()))
+
+	// Последняя попытка из MaxAttempts
+	assert.Equal(t, domain.StatusNoAttemptsLeft, updated[2].Status)
+	assert.Equal(t, 2, updated[2].AttemptNumber)
+}
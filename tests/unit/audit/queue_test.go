@@ -0,0 +1,232 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"go.uber.org/zap"
+)
+
+var testBatch = audit.BatchConfig{Size: 1, FlushInterval: 10 * time.Millisecond}
+
+// collector запоминает request ID событий, обработанных воркерами.
+type collector struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (c *collector) handle(events []domain.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, event := range events {
+		c.ids = append(c.ids, event.RequestID)
+	}
+	return nil
+}
+
+func (c *collector) collected() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.ids...)
+}
+
+func apiEvent(requestID string) domain.Event {
+	return domain.Event{Type: domain.EventAPIRequest, RequestID: requestID}
+}
+
+func newPool(t *testing.T, cfg audit.QueueConfig) *audit.WorkerPool {
+	t.Helper()
+	pool, err := audit.NewWorkerPool("test", cfg, testBatch, zap.NewNop().Sugar())
+	require.NoError(t, err)
+	return pool
+}
+
+// enqueueAndDrain ставит события в очередь без воркеров, затем запускает
+// воркеры и ждет, пока они обработают очередь.
+func enqueueAndDrain(t *testing.T, cfg audit.QueueConfig, ids ...string) []string {
+	t.Helper()
+	pool := newPool(t, cfg)
+	for _, id := range ids {
+		require.NoError(t, pool.Enqueue(apiEvent(id)))
+	}
+
+	c := &collector{}
+	pool.StartWorkers(context.Background(), c.handle)
+	pool.Close()
+	return c.collected()
+}
+
+func TestQueue_DropOldest(t *testing.T) {
+	ids := enqueueAndDrain(t, audit.QueueConfig{Capacity: 2, Policy: audit.OverflowDropOldest}, "1", "2", "3", "4")
+
+	assert.Equal(t, []string{"3", "4"}, ids)
+}
+
+func TestQueue_DropNewest(t *testing.T) {
+	ids := enqueueAndDrain(t, audit.QueueConfig{Capacity: 2, Policy: audit.OverflowDropNewest}, "1", "2", "3", "4")
+
+	assert.Equal(t, []string{"1", "2"}, ids)
+}
+
+func TestQueue_BlockDropsAfterTimeout(t *testing.T) {
+	pool := newPool(t, audit.QueueConfig{Capacity: 1, Policy: audit.OverflowBlock, BlockTimeout: 50 * time.Millisecond})
+	require.NoError(t, pool.Enqueue(apiEvent("1")))
+
+	start := time.Now()
+	require.NoError(t, pool.Enqueue(apiEvent("2")))
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+
+	c := &collector{}
+	pool.StartWorkers(context.Background(), c.handle)
+	pool.Close()
+	assert.Equal(t, []string{"1"}, c.collected())
+}
+
+func TestQueue_BlockWaitsForSpace(t *testing.T) {
+	pool := newPool(t, audit.QueueConfig{Capacity: 1, Policy: audit.OverflowBlock, BlockTimeout: 5 * time.Second})
+	require.NoError(t, pool.Enqueue(apiEvent("1")))
+
+	c := &collector{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		pool.StartWorkers(context.Background(), c.handle)
+	}()
+
+	start := time.Now()
+	require.NoError(t, pool.Enqueue(apiEvent("2")))
+	assert.Less(t, time.Since(start), time.Second)
+
+	pool.Close()
+	assert.Equal(t, []string{"1", "2"}, c.collected())
+}
+
+func TestQueue_ClosedRejectsEvents(t *testing.T) {
+	pool := newPool(t, audit.QueueConfig{Capacity: 1, Policy: audit.OverflowDropNewest})
+	pool.Close()
+
+	assert.Error(t, pool.Enqueue(apiEvent("1")))
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestQueue_SpillAndReplay(t *testing.T) {
+	cfg := audit.QueueConfig{Capacity: 2, Policy: audit.OverflowSpill, SpillDir: t.TempDir()}
+	pool := newPool(t, cfg)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, pool.Enqueue(apiEvent(id)))
+	}
+	spillPath := filepath.Join(cfg.SpillDir, "test_api.jsonl")
+	assert.Equal(t, 2, countLines(t, spillPath))
+
+	c := &collector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.StartWorkers(ctx, c.handle)
+
+	// Сброшенные события возвращаются в очередь, порядок не сохраняется
+	assert.Eventually(t, func() bool { return len(c.collected()) == 4 }, 5*time.Second, 20*time.Millisecond)
+	assert.ElementsMatch(t, []string{"1", "2", "3", "4"}, c.collected())
+	assert.NoFileExists(t, spillPath)
+
+	cancel()
+	pool.Close()
+}
+
+func TestQueue_ReplayAfterRestart(t *testing.T) {
+	cfg := audit.QueueConfig{Capacity: 1, Policy: audit.OverflowSpill, SpillDir: t.TempDir()}
+
+	// Воркеры не запускались: событие из памяти теряется, сброшенные на диск остаются
+	first := newPool(t, cfg)
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, first.Enqueue(apiEvent(id)))
+	}
+	first.Close()
+
+	second := newPool(t, cfg)
+	c := &collector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	second.StartWorkers(ctx, c.handle)
+
+	assert.Eventually(t, func() bool { return len(c.collected()) == 2 }, 5*time.Second, 20*time.Millisecond)
+	assert.ElementsMatch(t, []string{"2", "3"}, c.collected())
+
+	cancel()
+	second.Close()
+}
+
+// slowSink ждет отмены контекста в Write и запоминает, закрыли ли его
+// во время записи.
+type slowSink struct {
+	writing       atomic.Int32
+	closedInWrite atomic.Bool
+	closed        atomic.Bool
+}
+
+func (s *slowSink) Write(ctx context.Context, _ domain.Event) error {
+	s.writing.Add(1)
+	defer s.writing.Add(-1)
+	<-ctx.Done()
+	// Запись еще идет, когда контекст отменен
+	time.Sleep(20 * time.Millisecond)
+	return ctx.Err()
+}
+
+func (s *slowSink) Close() error {
+	if s.writing.Load() > 0 {
+		s.closedInWrite.Store(true)
+	}
+	s.closed.Store(true)
+	return nil
+}
+
+func TestPipeline_ShutdownWaitsForWorkersBeforeClosingSinks(t *testing.T) {
+	pipeline := audit.NewPipeline(
+		audit.QueueConfig{Capacity: 10, Policy: audit.OverflowDropNewest},
+		testBatch,
+		zap.NewNop().Sugar(),
+	)
+	sink := &slowSink{}
+	require.NoError(t, pipeline.AddSink("slow", sink, nil))
+	pipeline.StartWorkers(context.Background())
+
+	for range 3 {
+		pipeline.SendEvent(context.Background(), domain.EventStatusChange, audit.StatusChanged("1", domain.StatusIssued))
+	}
+	require.Eventually(t, func() bool { return sink.writing.Load() > 0 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := pipeline.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, sink.closed.Load())
+	assert.False(t, sink.closedInWrite.Load(), "получатель закрыт во время записи")
+}
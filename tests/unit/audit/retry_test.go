@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := audit.RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+	tests := []struct {
+		attempt int
+		full    time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{100, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		// Вторая половина задержки случайна: проверяем границы на многих запусках
+		for range 100 {
+			delay := policy.Delay(tt.attempt)
+			assert.GreaterOrEqual(t, delay, tt.full/2, "attempt %d", tt.attempt)
+			assert.LessOrEqual(t, delay, tt.full, "attempt %d", tt.attempt)
+		}
+	}
+}
+
+func TestRetryPolicy_DelayIsRandomized(t *testing.T) {
+	policy := audit.RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	seen := make(map[time.Duration]bool)
+	for range 20 {
+		seen[policy.Delay(3)] = true
+	}
+	assert.Greater(t, len(seen), 1)
+}
+
+func TestRetryPolicy_TinyDelay(t *testing.T) {
+	policy := audit.RetryPolicy{BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond}
+
+	assert.Equal(t, time.Nanosecond, policy.Delay(1))
+	assert.Equal(t, time.Nanosecond, policy.Delay(5))
+}
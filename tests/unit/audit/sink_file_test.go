@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+// Событие занимает около 300 КБ, в файл размером 1 МБ помещается три.
+var bigPayload = strings.Repeat("x", 300<<10)
+
+func newFileSink(t *testing.T, maxBackups int) (audit.Sink, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewSink("file", audit.SinkDeps{Config: &config.Config{
+		AuditFilePath:       path,
+		AuditFileMaxSizeMB:  1,
+		AuditFileMaxBackups: maxBackups,
+	}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sink.Close() })
+	return sink, path
+}
+
+func write(sink audit.Sink, requestID string) error {
+	return sink.Write(context.Background(), domain.Event{
+		Type:      domain.EventAPIRequest,
+		RequestID: requestID,
+		Data:      bigPayload,
+	})
+}
+
+// readRequestIDs читает request ID событий файла по порядку.
+func readRequestIDs(t *testing.T, path string) []string {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var event domain.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.RequestID)
+	}
+	require.NoError(t, scanner.Err())
+	return ids
+}
+
+func TestFileSink_Rotate(t *testing.T) {
+	sink, path := newFileSink(t, 2)
+
+	for _, id := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"} {
+		require.NoError(t, write(sink, id))
+	}
+
+	// Первые события вытеснены из копий
+	assert.Equal(t, []string{"10"}, readRequestIDs(t, path))
+	assert.Equal(t, []string{"7", "8", "9"}, readRequestIDs(t, path+".1"))
+	assert.Equal(t, []string{"4", "5", "6"}, readRequestIDs(t, path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestFileSink_RotateWithoutBackups(t *testing.T) {
+	sink, path := newFileSink(t, 0)
+
+	for _, id := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, write(sink, id))
+	}
+
+	assert.Equal(t, []string{"4"}, readRequestIDs(t, path))
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestFileSink_RotateFailureKeepsWriting(t *testing.T) {
+	sink, path := newFileSink(t, 1)
+
+	// Непустой каталог на месте копии нельзя ни удалить, ни заменить файлом
+	blocker := path + ".1"
+	require.NoError(t, os.Mkdir(blocker, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(blocker, "file"), nil, 0o600))
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, write(sink, id))
+	}
+	assert.Error(t, write(sink, "4"))
+	assert.Error(t, write(sink, "5"))
+
+	// События не потеряны: файл снова открыт и запись продолжается в него
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, readRequestIDs(t, path))
+
+	require.NoError(t, os.RemoveAll(blocker))
+	require.NoError(t, write(sink, "6"))
+
+	assert.Equal(t, []string{"6"}, readRequestIDs(t, path))
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, readRequestIDs(t, path+".1"))
+	assert.NoError(t, sink.Close())
+}
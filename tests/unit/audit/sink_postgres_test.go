@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+// fakeAuditService реализует только SaveLog и SaveLogs. Пачка не
+// сохраняется, если в ней есть событие из broken.
+type fakeAuditService struct {
+	service.AuditService
+	broken  map[string]bool
+	batches int
+	saved   []string
+}
+
+func (s *fakeAuditService) SaveLogs(_ context.Context, events []domain.Event) error {
+	s.batches++
+	for _, event := range events {
+		if s.broken[event.RequestID] {
+			return errors.New("COPY failed")
+		}
+	}
+	for _, event := range events {
+		s.saved = append(s.saved, event.RequestID)
+	}
+	return nil
+}
+
+func (s *fakeAuditService) SaveLog(_ context.Context, event domain.Event) error {
+	if s.broken[event.RequestID] {
+		return errors.New("INSERT failed")
+	}
+	s.saved = append(s.saved, event.RequestID)
+	return nil
+}
+
+func newPostgresSink(t *testing.T, svc *fakeAuditService) audit.BatchSink {
+	t.Helper()
+	sink, err := audit.NewSink("postgres", audit.SinkDeps{AuditService: svc, Logger: zap.NewNop().Sugar()})
+	require.NoError(t, err)
+	batchSink, ok := sink.(audit.BatchSink)
+	require.True(t, ok)
+	return batchSink
+}
+
+func TestPostgresSink_WriteBatch(t *testing.T) {
+	svc := &fakeAuditService{}
+	sink := newPostgresSink(t, svc)
+
+	err := sink.WriteBatch(context.Background(), []domain.Event{apiEvent("1"), apiEvent("2")})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, svc.batches)
+	assert.Equal(t, []string{"1", "2"}, svc.saved)
+}
+
+func TestPostgresSink_WriteBatchFallsBackToSingleInserts(t *testing.T) {
+	svc := &fakeAuditService{broken: map[string]bool{"2": true}}
+	sink := newPostgresSink(t, svc)
+
+	err := sink.WriteBatch(context.Background(), []domain.Event{apiEvent("1"), apiEvent("2"), apiEvent("3")})
+
+	// Теряется только событие, которое не сохраняется и по одному
+	assert.Error(t, err)
+	assert.Equal(t, []string{"1", "3"}, svc.saved)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/api"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+)
+
+// fakeAuditService реализует только GetTask, остальные методы в тестах
+// обработчика не используются.
+type fakeAuditService struct {
+	service.AuditService
+	tasks map[int]domain.AuditTask
+}
+
+func (s *fakeAuditService) GetTask(_ context.Context, id int) (domain.AuditTask, error) {
+	task, ok := s.tasks[id]
+	if !ok {
+		return domain.AuditTask{}, domain.ErrAuditTaskNotFound
+	}
+	return task, nil
+}
+
+func TestAuditHandler_GetDeadTask(t *testing.T) {
+	handler := api.NewAuditHandler(&fakeAuditService{tasks: map[int]domain.AuditTask{
+		1: {ID: 1, Status: domain.StatusNoAttemptsLeft, AuditLog: []byte(`{}`)},
+		2: {ID: 2, Status: domain.StatusCreated, AuditLog: []byte(`{}`)},
+		3: {ID: 3, Status: domain.StatusFinished, AuditLog: []byte(`{}`)},
+	}})
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"1", http.StatusOK},
+		{"2", http.StatusNotFound},
+		{"3", http.StatusNotFound},
+		{"4", http.StatusNotFound},
+		{"abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/audit/dead-tasks/"+tt.id, nil)
+		c.AddParam("id", tt.id)
+
+		handler.GetDeadTask(c)
+
+		assert.Equal(t, tt.want, w.Code, tt.id)
+	}
+}
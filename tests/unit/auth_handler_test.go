@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/api"
 	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/jwtkeys"
 
 	"go.uber.org/zap"
 )
@@ -31,11 +32,41 @@ func (m *MockAuthService) Login(ctx context.Context, user *domain.User) error {
 	return args.Error(0)
 }
 
-func (m *MockAuthService) GenerateToken(email string) (string, error) {
-	args := m.Called(email)
+func (m *MockAuthService) GenerateToken(user *domain.User) (string, error) {
+	args := m.Called(user.Email)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthService) IssueTokens(ctx context.Context, user *domain.User) (domain.TokenPair, error) {
+	args := m.Called(user.Email)
+	return domain.TokenPair{AccessToken: args.String(0)}, args.Error(1)
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, refreshToken string) (domain.TokenPair, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(domain.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	args := m.Called(ctx, accessToken, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ParseToken(ctx context.Context, tokenString string) (*domain.Claims, error) {
+	args := m.Called(ctx, tokenString)
+	return args.Get(0).(*domain.Claims), args.Error(1)
+}
+
+func (m *MockAuthService) AssignRole(ctx context.Context, email string, role domain.Role) error {
+	args := m.Called(ctx, email, role)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) JWKS() jwtkeys.JWKS {
+	args := m.Called()
+	return args.Get(0).(jwtkeys.JWKS)
+}
+
 func TestAuthHandler_Signup_Success(t *testing.T) {
 	mockService := new(MockAuthService)
 	logger := zap.NewNop().Sugar()
@@ -129,7 +160,7 @@ func TestAuthHandler_Login_Success(t *testing.T) {
 		Password: "validpassword",
 	}
 	mockService.On("Login", mock.Anything, user).Return(nil)
-	mockService.On("GenerateToken", user.Email).Return("test-token", nil)
+	mockService.On("IssueTokens", user.Email).Return("test-token", nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -179,7 +210,7 @@ func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 
 	handler.Login(c)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	assert.Contains(t, w.Body.String(), domain.ErrInvalidCredentials.Error())
 	mockService.AssertExpectations(t)
 }
@@ -194,7 +225,7 @@ func TestAuthHandler_Login_TokenGenerationError(t *testing.T) {
 		Password: "validpassword",
 	}
 	mockService.On("Login", mock.Anything, user).Return(nil)
-	mockService.On("GenerateToken", user.Email).Return("", domain.ErrTokenGeneration)
+	mockService.On("IssueTokens", user.Email).Return("", domain.ErrTokenGeneration)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -245,7 +276,7 @@ func TestAuthHandler_Login_Success_JSONStructure(t *testing.T) {
 		Password: "validpassword",
 	}
 	mockService.On("Login", mock.Anything, user).Return(nil)
-	mockService.On("GenerateToken", user.Email).Return("test-token", nil)
+	mockService.On("IssueTokens", user.Email).Return("test-token", nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
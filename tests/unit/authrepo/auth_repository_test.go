@@ -0,0 +1,98 @@
+package authrepo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/authrepo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage"
+	"go.uber.org/zap"
+)
+
+// memoryTokens — refresh токены в памяти с той же логикой отзыва, что и в
+// хранилище postgres.
+type memoryTokens struct {
+	storage.AuthStorage
+	tokens map[string]*domain.RefreshToken
+	// revoked — причина отзыва по хешу токена
+	revoked map[string]domain.RevokeReason
+}
+
+func newMemoryTokens(email string, hashes ...string) *memoryTokens {
+	s := &memoryTokens{
+		tokens:  make(map[string]*domain.RefreshToken),
+		revoked: make(map[string]domain.RevokeReason),
+	}
+	for _, hash := range hashes {
+		s.tokens[hash] = &domain.RefreshToken{TokenHash: hash, Email: email}
+	}
+	return s
+}
+
+func (s *memoryTokens) ConsumeRefreshToken(_ context.Context, hash string) (domain.RefreshToken, error) {
+	token, ok := s.tokens[hash]
+	if !ok {
+		return domain.RefreshToken{}, domain.ErrInvalidRefreshToken
+	}
+	if reason, ok := s.revoked[hash]; ok {
+		return domain.RefreshToken{TokenHash: hash, Email: token.Email, RevokeReason: reason}, domain.ErrTokenRevoked
+	}
+	s.revoked[hash] = domain.RevokeRotated
+	return *token, nil
+}
+
+func (s *memoryTokens) RevokeRefreshToken(_ context.Context, hash string) error {
+	if _, ok := s.revoked[hash]; !ok {
+		s.revoked[hash] = domain.RevokeLogout
+	}
+	return nil
+}
+
+func (s *memoryTokens) RevokeUserRefreshTokens(_ context.Context, email string) error {
+	for hash, token := range s.tokens {
+		if _, ok := s.revoked[hash]; !ok && token.Email == email {
+			s.revoked[hash] = domain.RevokeReuse
+		}
+	}
+	return nil
+}
+
+func TestConsumeRefreshToken_AfterLogout(t *testing.T) {
+	tokens := newMemoryTokens("user@example.com", "tab1", "tab2")
+	repo := authrepo.NewAuthRepository(tokens, zap.NewNop().Sugar())
+
+	require.NoError(t, repo.RevokeRefreshToken(context.Background(), "tab1"))
+
+	// Повтор refresh после выхода отклоняется, но сессии не сбрасываются
+	_, err := repo.ConsumeRefreshToken(context.Background(), "tab1")
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+
+	email, err := repo.ConsumeRefreshToken(context.Background(), "tab2")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", email)
+}
+
+func TestConsumeRefreshToken_ReuseRevokesAllTokens(t *testing.T) {
+	tokens := newMemoryTokens("user@example.com", "stolen", "other")
+	repo := authrepo.NewAuthRepository(tokens, zap.NewNop().Sugar())
+
+	_, err := repo.ConsumeRefreshToken(context.Background(), "stolen")
+	require.NoError(t, err)
+
+	_, err = repo.ConsumeRefreshToken(context.Background(), "stolen")
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+	assert.Equal(t, domain.RevokeReuse, tokens.revoked["other"])
+
+	_, err = repo.ConsumeRefreshToken(context.Background(), "other")
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+}
+
+func TestConsumeRefreshToken_Unknown(t *testing.T) {
+	repo := authrepo.NewAuthRepository(newMemoryTokens("user@example.com"), zap.NewNop().Sugar())
+
+	_, err := repo.ConsumeRefreshToken(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrInvalidRefreshToken)
+}
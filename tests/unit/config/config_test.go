@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/config"
+)
+
+// inEmptyDir запускает тест в каталоге с пустым .env: Load без него
+// завершает процесс.
+func inEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/.env", nil, 0o600))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	inEmptyDir(t)
+
+	cfg, err := config.Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, cfg.ReturnJobInterval)
+	assert.Equal(t, 5*time.Second, cfg.OutboxPollInterval)
+	assert.Empty(t, cfg.TrustedProxies)
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	inEmptyDir(t)
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1, 192.168.0.0/16")
+
+	cfg, err := config.Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "192.168.0.0/16"}, cfg.TrustedProxies)
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1,proxy.local")
+	_, err = config.Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "proxy.local")
+}
+
+func TestLoad_RejectsNonPositiveValues(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"RETURN_JOB_INTERVAL", "0s"},
+		{"RETURN_JOB_INTERVAL", "-1m"},
+		{"OUTBOX_POLL_INTERVAL", "0s"},
+		{"OUTBOX_POLL_INTERVAL", "-5s"},
+		{"SHUTDOWN_TIMEOUT", "0s"},
+		{"AUDIT_FLUSH_INTERVAL", "0s"},
+		{"AUDIT_DRAIN_TIMEOUT", "-1s"},
+		{"IDEMPOTENCY_TTL", "0s"},
+		{"IDEMPOTENCY_LOCK_TTL", "0s"},
+		{"IDEMPOTENCY_LOCK_TTL", "-30s"},
+		{"AUDIT_QUEUE_SIZE", "0"},
+		{"AUDIT_QUEUE_SIZE", "-10"},
+		{"AUDIT_BATCH_SIZE", "-1"},
+		{"RETURN_JOB_LIMIT", "0"},
+		{"RETURN_JOB_LIMIT", "-5"},
+		{"OUTBOX_BATCH_SIZE", "0"},
+		{"OUTBOX_BATCH_SIZE", "-1"},
+		{"OUTBOX_WORKERS", "0"},
+		{"OUTBOX_MAX_ATTEMPTS", "0"},
+		{"CONSUMER_MAX_ATTEMPTS", "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			inEmptyDir(t)
+			t.Setenv(tt.key, tt.value)
+
+			cfg, err := config.Load()
+
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+			assert.Contains(t, err.Error(), tt.key)
+		})
+	}
+}
+
+func TestLoad_AuditFilterAppliesOnlyToStdout(t *testing.T) {
+	inEmptyDir(t)
+	t.Setenv("AUDIT_SINKS", "postgres,stdout,file")
+	t.Setenv("AUDIT_FILTER", `type == "api_request"`)
+	t.Setenv("AUDIT_FILTER_FILE", `user == "admin"`)
+
+	cfg, err := config.Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"postgres": "",
+		"stdout":   `type == "api_request"`,
+		"file":     `user == "admin"`,
+	}, cfg.AuditSinkFilters)
+
+	t.Setenv("AUDIT_FILTER_STDOUT", "")
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.AuditSinkFilters["stdout"])
+}
+
+func TestLoad_AuditKafkaTopic(t *testing.T) {
+	inEmptyDir(t)
+	t.Setenv("KAFKA_TOPIC", "audit_logs")
+	t.Setenv("AUDIT_SINKS", "postgres,kafka")
+
+	cfg, err := config.Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, "audit_events.sink", cfg.AuditKafkaTopic)
+
+	// Записи с ключом request ID попали бы в DLQ consumer
+	t.Setenv("AUDIT_KAFKA_TOPIC", "audit_logs")
+	_, err = config.Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AUDIT_KAFKA_TOPIC")
+}
+
+func TestLoad_ReplayDLQTopic(t *testing.T) {
+	inEmptyDir(t)
+
+	cfg, err := config.Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, "audit_logs.replay.dlq", cfg.KafkaReplayDLQTopic)
+
+	t.Setenv("KAFKA_REPLAY_DLQ_TOPIC", "audit_logs.dlq")
+	_, err = config.Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "KAFKA_REPLAY_DLQ_TOPIC")
+}
@@ -0,0 +1,437 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/schema"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+	"go.uber.org/zap"
+)
+
+const topic = "audit_logs"
+
+// fakeKafka — брокер в памяти с одной партицией. Хранит записи и
+// закоммиченное смещение группы; новый клиент читает с этого смещения,
+// как consumer после перезапуска.
+type fakeKafka struct {
+	mu        sync.Mutex
+	records   []*kgo.Record
+	committed int64
+	produced  chan struct{}
+}
+
+func newFakeKafka() *fakeKafka {
+	return &fakeKafka{produced: make(chan struct{})}
+}
+
+func (k *fakeKafka) Produce(key string, value []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.records = append(k.records, &kgo.Record{
+		Topic:  topic,
+		Key:    []byte(key),
+		Value:  value,
+		Offset: int64(len(k.records)),
+	})
+	close(k.produced)
+	k.produced = make(chan struct{})
+}
+
+func (k *fakeKafka) Committed() int64 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.committed
+}
+
+func (k *fakeKafka) Rewind() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.committed = 0
+}
+
+func (k *fakeKafka) NewClient() *fakeClient {
+	return &fakeClient{broker: k, position: k.Committed()}
+}
+
+type fakeClient struct {
+	broker   *fakeKafka
+	position int64
+	marked   int64
+}
+
+func (c *fakeClient) PollFetches(ctx context.Context) kgo.Fetches {
+	for {
+		c.broker.mu.Lock()
+		records := c.broker.records[c.position:]
+		produced := c.broker.produced
+		c.broker.mu.Unlock()
+
+		if len(records) > 0 {
+			c.position += int64(len(records))
+			return kgo.Fetches{{Topics: []kgo.FetchTopic{{
+				Topic: topic,
+				Partitions: []kgo.FetchPartition{{
+					HighWatermark: c.position,
+					Records:       records,
+				}},
+			}}}}
+		}
+
+		select {
+		case <-ctx.Done():
+			return kgo.NewErrFetch(ctx.Err())
+		case <-produced:
+		}
+	}
+}
+
+func (c *fakeClient) MarkCommitRecords(rs ...*kgo.Record) {
+	for _, r := range rs {
+		c.marked = r.Offset + 1
+	}
+}
+
+func (c *fakeClient) CommitUncommittedOffsets(context.Context) error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.broker.committed = c.marked
+	return nil
+}
+
+func (c *fakeClient) Close() {}
+
+// memoryEventService хранит события по ID задачи, как audit_events. Может
+// отказать первые failures раз и отказывает на событиях из failIDs, пока
+// их не вернут через Recover.
+type memoryEventService struct {
+	mu       sync.Mutex
+	events   map[int]domain.AuditEvent
+	failures int
+	failIDs  map[int]bool
+	rejected int
+}
+
+func newMemoryEventService() *memoryEventService {
+	return &memoryEventService{
+		events:  make(map[int]domain.AuditEvent),
+		failIDs: make(map[int]bool),
+	}
+}
+
+func (s *memoryEventService) SaveEvents(_ context.Context, events []domain.AuditEvent) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return 0, errors.New("database is down")
+	}
+	for _, event := range events {
+		if s.failIDs[event.ID] {
+			s.rejected++
+			return 0, errors.New("database is down")
+		}
+	}
+
+	inserted := 0
+	for _, event := range events {
+		if _, exists := s.events[event.ID]; exists {
+			continue
+		}
+		s.events[event.ID] = event
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (s *memoryEventService) Recover(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failIDs, id)
+}
+
+func (s *memoryEventService) Rejected() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rejected
+}
+
+func (s *memoryEventService) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func (s *memoryEventService) Get(id int) domain.AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events[id]
+}
+
+type deadRecord struct {
+	key      string
+	cause    string
+	attempts int
+}
+
+type memoryDeadLetter struct {
+	mu      sync.Mutex
+	records []deadRecord
+}
+
+func (d *memoryDeadLetter) Publish(_ context.Context, record *kgo.Record, cause error, attempts int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.records = append(d.records, deadRecord{key: string(record.Key), cause: cause.Error(), attempts: attempts})
+	return nil
+}
+
+func (d *memoryDeadLetter) Records() []deadRecord {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]deadRecord(nil), d.records...)
+}
+
+func produceEvent(t *testing.T, broker *fakeKafka, taskID int, event domain.Event) {
+	value, err := json.Marshal(event)
+	require.NoError(t, err)
+	broker.Produce(strconv.Itoa(taskID), value)
+}
+
+// runConsumer запускает consumer и останавливает его после того, как группа
+// закоммитит смещение offset.
+func runConsumer(t *testing.T, broker *fakeKafka, service *memoryEventService, deadLetter *memoryDeadLetter, offset int64) {
+	stop := startConsumer(t, broker, service, deadLetter, 0)
+	require.Eventually(t, func() bool {
+		return broker.Committed() == offset
+	}, 2*time.Second, 5*time.Millisecond)
+	stop()
+}
+
+// startConsumer запускает consumer и возвращает функцию, которая его
+// останавливает. maxAttempts 0 — повторять временные ошибки без предела.
+func startConsumer(t *testing.T, broker *fakeKafka, service *memoryEventService, deadLetter *memoryDeadLetter, maxAttempts int) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger := zap.NewNop().Sugar()
+
+	materializer := audit.NewMaterializer(service)
+	handlers := audit.NewHandlerRegistry()
+	handlers.Register(domain.EventStatusChange, materializer.Handle)
+	handlers.Register(domain.EventAPIRequest, materializer.Handle)
+
+	consumer := kafka.NewConsumer(
+		broker.NewClient(),
+		handlers.Handle,
+		kafka.Retry{
+			AlertAfter:  3,
+			MaxAttempts: maxAttempts,
+			Delay:       func(int) time.Duration { return time.Millisecond },
+		},
+		deadLetter,
+		logger,
+	)
+
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	return func() {
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestConsumerStoresEventsOnce(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+	eventTime := time.Date(2025, 5, 8, 12, 0, 0, 0, time.UTC)
+
+	produceEvent(t, broker, 1, domain.Event{
+		Type:      domain.EventStatusChange,
+		Data:      map[string]any{"order_id": "42", "status": "issued"},
+		Time:      eventTime,
+		User:      "manager@example.com",
+		RequestID: "req-1",
+	})
+	produceEvent(t, broker, 2, domain.Event{Type: domain.EventAPIRequest, Time: eventTime})
+	// Повторная отправка той же задачи outbox
+	produceEvent(t, broker, 2, domain.Event{Type: domain.EventAPIRequest, Time: eventTime})
+	produceEvent(t, broker, 3, domain.Event{Type: domain.EventAPIRequest, Time: eventTime})
+
+	runConsumer(t, broker, service, deadLetter, 4)
+
+	assert.Equal(t, 3, service.Len())
+	assert.Empty(t, deadLetter.Records())
+
+	event := service.Get(1)
+	assert.Equal(t, domain.EventStatusChange, event.Type)
+	assert.Equal(t, "manager@example.com", event.User)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.True(t, eventTime.Equal(event.Time))
+	assert.JSONEq(t, `{"order_id": "42", "status": "issued"}`, string(event.Data))
+}
+
+func TestConsumerRetriesFailedBatch(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+	service.failures = 2
+
+	produceEvent(t, broker, 1, domain.Event{Type: domain.EventAPIRequest, Time: time.Now()})
+	produceEvent(t, broker, 2, domain.Event{Type: domain.EventStatusChange, Time: time.Now()})
+
+	runConsumer(t, broker, service, deadLetter, 2)
+
+	assert.Equal(t, 2, service.Len())
+	assert.Zero(t, service.failures)
+	assert.Empty(t, deadLetter.Records())
+}
+
+func TestConsumerMovesPoisonRecordsToDeadLetter(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+
+	produceEvent(t, broker, 1, domain.Event{Type: domain.EventStatusChange, Time: time.Now()})
+	broker.Produce("not-a-task-id", []byte(`{}`))
+	produceEvent(t, broker, 3, domain.Event{Type: domain.EventAPIResponse, Time: time.Now()})
+	produceEvent(t, broker, 4, domain.Event{Type: domain.EventAPIRequest, Time: time.Now()})
+
+	runConsumer(t, broker, service, deadLetter, 4)
+
+	assert.Equal(t, 2, service.Len())
+	assert.NotZero(t, service.Get(1).ID)
+	assert.NotZero(t, service.Get(4).ID)
+
+	// Неразборчивая запись и тип без обработчика не повторяются
+	dead := deadLetter.Records()
+	require.Len(t, dead, 2)
+	assert.Equal(t, "not-a-task-id", dead[0].key)
+	assert.Equal(t, 1, dead[0].attempts)
+	assert.Equal(t, "3", dead[1].key)
+	assert.Equal(t, 1, dead[1].attempts)
+	assert.Contains(t, dead[1].cause, "api_response")
+}
+
+func TestConsumerBlocksOnTransientErrorUntilItPasses(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+	service.failIDs[2] = true
+
+	for id := 1; id <= 3; id++ {
+		produceEvent(t, broker, id, domain.Event{Type: domain.EventStatusChange, Time: time.Now()})
+	}
+
+	stop := startConsumer(t, broker, service, deadLetter, 0)
+	defer stop()
+
+	// Попытки давно превысили AlertAfter, но запись не уходит в DeadLetter
+	// и смещение не двигается
+	require.Eventually(t, func() bool { return service.Rejected() > 10 }, 2*time.Second, 5*time.Millisecond)
+	assert.Zero(t, broker.Committed())
+	assert.Empty(t, deadLetter.Records())
+
+	service.Recover(2)
+
+	require.Eventually(t, func() bool { return broker.Committed() == 3 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 3, service.Len())
+	assert.Empty(t, deadLetter.Records())
+}
+
+func TestConsumerMovesRecordToDeadLetterAfterMaxAttempts(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+	service.failIDs[2] = true
+
+	for id := 1; id <= 3; id++ {
+		produceEvent(t, broker, id, domain.Event{Type: domain.EventStatusChange, Time: time.Now()})
+	}
+
+	stop := startConsumer(t, broker, service, deadLetter, 4)
+	defer stop()
+
+	// Ошибка повторяется на каждой попытке: после MaxAttempts запись уходит
+	// в DeadLetter, и партиция идет дальше
+	require.Eventually(t, func() bool { return broker.Committed() == 3 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 2, service.Len())
+
+	dead := deadLetter.Records()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "2", dead[0].key)
+	assert.Equal(t, 4, dead[0].attempts)
+	assert.Contains(t, dead[0].cause, "database is down")
+}
+
+func TestConsumerRedeliveryAfterRestart(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+
+	for id := 1; id <= 3; id++ {
+		produceEvent(t, broker, id, domain.Event{Type: domain.EventStatusChange, Time: time.Now()})
+	}
+	runConsumer(t, broker, service, deadLetter, 3)
+
+	// Смещение потерялось, например, consumer упал до коммита
+	broker.Rewind()
+	produceEvent(t, broker, 4, domain.Event{Type: domain.EventStatusChange, Time: time.Now()})
+	runConsumer(t, broker, service, deadLetter, 4)
+
+	assert.Equal(t, 4, service.Len())
+}
+
+func TestConsumerValidatesEventSchema(t *testing.T) {
+	broker := newFakeKafka()
+	service := newMemoryEventService()
+	deadLetter := &memoryDeadLetter{}
+
+	eventID := uuid.NewString()
+	versioned := func(data string) domain.Event {
+		return domain.Event{
+			Type:          domain.EventStatusChange,
+			EventID:       eventID,
+			SchemaVersion: schema.Version,
+			Data:          json.RawMessage(data),
+			Time:          time.Now(),
+		}
+	}
+
+	produceEvent(t, broker, 1, versioned(`{"order_id":"42","status":"issued"}`))
+	produceEvent(t, broker, 2, versioned(`{"order_id":"42","status":"Deleted"}`))
+	produceEvent(t, broker, 3, versioned(`{"order_id":"42","status":"issued","from":"stored"}`))
+	withoutID := versioned(`{"order_id":"42","status":"issued"}`)
+	withoutID.EventID = ""
+	produceEvent(t, broker, 4, withoutID)
+	future := versioned(`{"order_id":"42","status":"issued"}`)
+	future.SchemaVersion = schema.Version + 1
+	produceEvent(t, broker, 5, future)
+
+	runConsumer(t, broker, service, deadLetter, 5)
+
+	assert.Equal(t, 1, service.Len())
+	assert.Equal(t, eventID, service.Get(1).EventID)
+	assert.Equal(t, schema.Version, service.Get(1).SchemaVersion)
+
+	dead := deadLetter.Records()
+	require.Len(t, dead, 4)
+	for i, reason := range []string{"Deleted", "from", "EventID", "версия"} {
+		assert.Equal(t, strconv.Itoa(i+2), dead[i].key)
+		assert.Equal(t, 1, dead[i].attempts)
+		assert.Contains(t, dead[i].cause, reason)
+	}
+}
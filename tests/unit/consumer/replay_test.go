@@ -0,0 +1,198 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/kafka"
+)
+
+func TestParseReplayRange(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		from    string
+		offsets string
+		until   string
+		want    *kafka.ReplayRange
+		wantErr string
+	}{
+		{name: "no replay"},
+		{name: "from", from: "2025-05-01T00:00:00Z", want: &kafka.ReplayRange{From: from}},
+		{
+			name: "from and until", from: "2025-05-01T00:00:00Z", until: "2025-05-02T00:00:00Z",
+			want: &kafka.ReplayRange{From: from, Until: until},
+		},
+		{
+			name: "offsets and until", offsets: "0:120, 1:80", until: "2025-05-02T00:00:00Z",
+			want: &kafka.ReplayRange{Offsets: map[int32]int64{0: 120, 1: 80}, Until: until},
+		},
+		{name: "until alone", until: "2025-05-02T00:00:00Z", wantErr: "-replay-until"},
+		{name: "from and offsets", from: "2025-05-01T00:00:00Z", offsets: "0:1", wantErr: "либо"},
+		{name: "bad from", from: "2025-05-01", wantErr: "-replay-from"},
+		{name: "bad until", from: "2025-05-01T00:00:00Z", until: "tomorrow", wantErr: "-replay-until"},
+		{name: "until before from", from: "2025-05-02T00:00:00Z", until: "2025-05-01T00:00:00Z", wantErr: "раньше"},
+		{name: "until equals from", from: "2025-05-01T00:00:00Z", until: "2025-05-01T00:00:00Z", wantErr: "раньше"},
+		{name: "bad offsets", offsets: "0", wantErr: "партиция:смещение"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kafka.ParseReplayRange(tt.from, tt.offsets, tt.until)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePartitionOffsets(t *testing.T) {
+	offsets, err := kafka.ParsePartitionOffsets("0:1200,2:800, 3:0")
+	require.NoError(t, err)
+	assert.Equal(t, map[int32]int64{0: 1200, 2: 800, 3: 0}, offsets)
+
+	for _, value := range []string{"", "0", "0:", ":5", "a:1", "0:b", "0:-1", "0:1,,1:2", "99999999999:1"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := kafka.ParsePartitionOffsets(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// scriptedClient отдает заранее заданные выборки по одной на опрос и
+// запоминает закоммиченные смещения партиций.
+type scriptedClient struct {
+	fetches   []kgo.Fetches
+	marked    map[int32]int64
+	committed map[int32]int64
+}
+
+func newScriptedClient(fetches ...kgo.Fetches) *scriptedClient {
+	return &scriptedClient{
+		fetches:   fetches,
+		marked:    make(map[int32]int64),
+		committed: make(map[int32]int64),
+	}
+}
+
+func (c *scriptedClient) PollFetches(context.Context) kgo.Fetches {
+	if len(c.fetches) == 0 {
+		return nil
+	}
+	fetches := c.fetches[0]
+	c.fetches = c.fetches[1:]
+	return fetches
+}
+
+func (c *scriptedClient) MarkCommitRecords(rs ...*kgo.Record) {
+	for _, r := range rs {
+		c.marked[r.Partition] = r.Offset + 1
+	}
+}
+
+func (c *scriptedClient) CommitUncommittedOffsets(context.Context) error {
+	for partition, offset := range c.marked {
+		c.committed[partition] = offset
+	}
+	return nil
+}
+
+func (c *scriptedClient) Close() {}
+
+// partitionFetch собирает выборку одной партиции с записями from..to.
+func partitionFetch(partition int32, from, to int64) kgo.Fetches {
+	var records []*kgo.Record
+	for offset := from; offset <= to; offset++ {
+		records = append(records, &kgo.Record{Topic: topic, Partition: partition, Offset: offset})
+	}
+	return kgo.Fetches{{Topics: []kgo.FetchTopic{{
+		Topic:      topic,
+		Partitions: []kgo.FetchPartition{{Partition: partition, Records: records}},
+	}}}}
+}
+
+func offsets(fetches kgo.Fetches) []int64 {
+	var result []int64
+	fetches.EachRecord(func(r *kgo.Record) {
+		result = append(result, r.Offset)
+	})
+	return result
+}
+
+func isDone(r *kafka.ReplayClient) bool {
+	select {
+	case <-r.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestReplayClient_StopsAtEnd(t *testing.T) {
+	client := newScriptedClient(
+		partitionFetch(0, 10, 12),
+		partitionFetch(0, 13, 16),
+		partitionFetch(1, 0, 3),
+	)
+	replay := kafka.WrapReplayClient(client, map[int32]kafka.ReplayPartition{0: {Start: 10, End: 15}})
+	ctx := context.Background()
+
+	first := replay.PollFetches(ctx)
+	assert.Equal(t, []int64{10, 11, 12}, offsets(first))
+	first.EachPartition(func(p kgo.FetchTopicPartition) { replay.MarkCommitRecords(p.Records...) })
+	require.NoError(t, replay.CommitUncommittedOffsets(ctx))
+	assert.False(t, isDone(replay))
+
+	// Записи за концом диапазона отбрасываются
+	second := replay.PollFetches(ctx)
+	assert.Equal(t, []int64{13, 14}, offsets(second))
+
+	// Помеченные, но не закоммиченные записи не завершают повтор
+	second.EachPartition(func(p kgo.FetchTopicPartition) { replay.MarkCommitRecords(p.Records...) })
+	assert.False(t, isDone(replay))
+	require.NoError(t, replay.CommitUncommittedOffsets(ctx))
+	assert.True(t, isDone(replay))
+	assert.Equal(t, map[int32]int64{0: 15}, replay.Progress())
+	assert.Equal(t, int64(15), client.committed[0])
+
+	// Партиции вне плана не читаются
+	assert.Empty(t, offsets(replay.PollFetches(ctx)))
+}
+
+func TestReplayClient_WaitsForEveryPartition(t *testing.T) {
+	client := newScriptedClient(partitionFetch(0, 0, 1), partitionFetch(1, 5, 6))
+	replay := kafka.WrapReplayClient(client, map[int32]kafka.ReplayPartition{
+		0: {Start: 0, End: 2},
+		1: {Start: 5, End: 7},
+	})
+	ctx := context.Background()
+
+	for range 2 {
+		assert.False(t, isDone(replay))
+		fetches := replay.PollFetches(ctx)
+		fetches.EachPartition(func(p kgo.FetchTopicPartition) { replay.MarkCommitRecords(p.Records...) })
+		require.NoError(t, replay.CommitUncommittedOffsets(ctx))
+	}
+
+	assert.True(t, isDone(replay))
+}
+
+func TestReplayClient_EmptyRangeIsDone(t *testing.T) {
+	replay := kafka.WrapReplayClient(newScriptedClient(), map[int32]kafka.ReplayPartition{
+		0: {Start: 7, End: 7},
+		1: {Start: 3, End: 3},
+	})
+
+	assert.True(t, isDone(replay))
+}
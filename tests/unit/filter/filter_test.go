@@ -0,0 +1,158 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/filter"
+)
+
+var event = map[string]any{
+	"type":       "status_change",
+	"user":       "manager@example.com",
+	"request_id": "",
+	"data": map[string]any{
+		"order_id": "42",
+		"status":   "issued",
+		"code":     500,
+		"path":     "/orders/42",
+		"comment":  "Заказ выдан",
+		"nested":   map[string]any{"ok": true},
+	},
+}
+
+func TestCompile_Lexing(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"spaces and tabs", "  type\t==\n\"status_change\"  ", true},
+		{"escaped quote", `data.comment != "say \"hi\""`, true},
+		{"negative number", "data.code > -1", true},
+		{"fractional number", "data.code >= 499.5", true},
+		{"two-char operators before one-char", "data.code <= 500 && data.code >= 500", true},
+		{"cyrillic string", `data.comment == "Заказ выдан"`, true},
+		{"cyrillic string contains", `data.comment contains "выдан"`, true},
+		{"cyrillic identifier", "data.комментарий", false},
+		{"underscore identifier", "data.order_id == \"42\"", true},
+		{"nested field", "data.nested.ok == true", true},
+		{"empty list", "type in []", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := filter.Compile(tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.Match(event))
+		})
+	}
+}
+
+func TestCompile_Precedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// && связывает сильнее ||
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"false && false || true", true},
+		{"false && (false || true)", false},
+		// ! применяется к ближайшему операнду
+		{"!false && false", false},
+		{"!(false && false)", true},
+		{"!!true", true},
+		// сравнение связывает сильнее логики
+		{`type == "status_change" && data.status == "issued"`, true},
+		{`type == "api_request" || data.status in ["issued", "refunded"]`, true},
+		{`!(type == "status_change")`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			expr, err := filter.Compile(tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.Match(event))
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+	}{
+		{`type == "status_change`, 8},
+		{"type == @", 8},
+		{`"Заказ" == @`, 16},
+		{"type ==", 7},
+		{"type == 1.2.3", 8},
+		{"(type", 5},
+		{"type)", 4},
+		{`type in "issued"`, 5},
+		{`type in ["a" "b"]`, 13},
+		{"in == 1", 0},
+		{"data..status", 0},
+		{"data.", 0},
+		{"&& type", 0},
+		{"type == \xff", 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := filter.Compile(tt.src)
+
+			var syntaxErr *filter.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "ожидалась SyntaxError, получено %v", err)
+			assert.Equal(t, tt.pos, syntaxErr.Pos)
+		})
+	}
+}
+
+func TestExpr_Match(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`type == "status_change"`, true},
+		{`type != "status_change"`, false},
+		{"data.code == 500", true},
+		{"data.code > 499 && data.code < 501", true},
+		{`data.code == "500"`, false},
+		{`data.code > "1"`, false},
+		{`data.status > "a"`, true},
+		{`data.status in ["issued", "refunded"]`, true},
+		{`data.status in ["refunded"]`, false},
+		{`data.path startsWith "/orders"`, true},
+		{`data.path endsWith "/42"`, true},
+		{`data.path contains "rders"`, true},
+		{`data.code startsWith "5"`, false},
+		// Поле без сравнения истинно, если оно есть и не пустое
+		{"user", true},
+		{"request_id", false},
+		{"data.missing", false},
+		{"data.missing == null", true},
+		{"data.nested", true},
+		{"data == null", false},
+		// Обращение к полю внутри не-map
+		{"type.name == null", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			expr, err := filter.Compile(tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.Match(event))
+		})
+	}
+}
+
+func TestExpr_Fields(t *testing.T) {
+	expr, err := filter.Compile(`type == "a" && !(data.status in [user, "b"]) || 1 < 2`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"type", "data.status", "user"}, expr.Fields())
+}
@@ -0,0 +1,45 @@
+package hashchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/hashchain"
+)
+
+var createdAt = time.Date(2025, 5, 6, 14, 20, 17, 123456000, time.UTC)
+
+func TestLink_DependsOnEveryField(t *testing.T) {
+	chain := hashchain.New("secret")
+	base := chain.Link([]byte("prev"), 1, createdAt, []byte(`{"a": 1}`))
+
+	assert.Equal(t, base, chain.Link([]byte("prev"), 1, createdAt, []byte(`{"a": 1}`)))
+	assert.NotEqual(t, base, chain.Link([]byte("other"), 1, createdAt, []byte(`{"a": 1}`)))
+	assert.NotEqual(t, base, chain.Link(nil, 1, createdAt, []byte(`{"a": 1}`)))
+	assert.NotEqual(t, base, chain.Link([]byte("prev"), 2, createdAt, []byte(`{"a": 1}`)))
+	assert.NotEqual(t, base, chain.Link([]byte("prev"), 1, createdAt.Add(time.Microsecond), []byte(`{"a": 1}`)))
+	assert.NotEqual(t, base, chain.Link([]byte("prev"), 1, createdAt, []byte(`{"a": 2}`)))
+}
+
+func TestLink_Key(t *testing.T) {
+	data := []byte(`{"a": 1}`)
+	plain := hashchain.New("").Link(nil, 1, createdAt, data)
+	keyed := hashchain.New("secret").Link(nil, 1, createdAt, data)
+
+	assert.Len(t, plain, 32)
+	assert.Len(t, keyed, 32)
+	assert.NotEqual(t, plain, keyed)
+	assert.NotEqual(t, keyed, hashchain.New("other").Link(nil, 1, createdAt, data))
+}
+
+func TestLink_MicrosecondPrecision(t *testing.T) {
+	// База хранит время с точностью до микросекунд
+	chain := hashchain.New("")
+	data := []byte(`{}`)
+
+	assert.Equal(t,
+		chain.Link(nil, 1, createdAt, data),
+		chain.Link(nil, 1, createdAt.Add(999*time.Nanosecond), data),
+	)
+}
@@ -0,0 +1,169 @@
+package hashchain
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/hashchain"
+	repository "gitlab.ozon.dev/sadsnake2311/homework/internal/repository/auditlogrepo"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+)
+
+// fakeChainRepo отдает записи цепочки и голову из памяти.
+type fakeChainRepo struct {
+	repository.AuditRepository
+	records []domain.AuditChainRecord
+	head    domain.AuditChainHead
+}
+
+func (r *fakeChainRepo) GetChainRecords(_ context.Context, afterID, limit int) ([]domain.AuditChainRecord, error) {
+	var page []domain.AuditChainRecord
+	for _, record := range r.records {
+		if record.ID > afterID && len(page) < limit {
+			page = append(page, record)
+		}
+	}
+	return page, nil
+}
+
+func (r *fakeChainRepo) GetChainHead(context.Context) (domain.AuditChainHead, error) {
+	return r.head, nil
+}
+
+// newChainRepo строит цепочку звеньев с id first..last, как ее сохраняет база.
+func newChainRepo(chain *hashchain.Chain, first, last int) *fakeChainRepo {
+	repo := &fakeChainRepo{}
+	var prev []byte
+	for id := first; id <= last; id++ {
+		record := domain.AuditChainRecord{
+			ID:        id,
+			CreatedAt: createdAt.Add(time.Duration(id) * time.Second),
+			Data:      fmt.Appendf(nil, `{"RequestID": "%d"}`, id),
+		}
+		record.Hash = chain.Link(prev, record.ID, record.CreatedAt, record.Data)
+		prev = record.Hash
+		repo.records = append(repo.records, record)
+	}
+	repo.head = domain.AuditChainHead{LastID: last, Hash: prev}
+	return repo
+}
+
+func (r *fakeChainRepo) delete(id int) {
+	for i, record := range r.records {
+		if record.ID == id {
+			r.records = append(r.records[:i], r.records[i+1:]...)
+			return
+		}
+	}
+}
+
+func verify(t *testing.T, chain *hashchain.Chain, repo *fakeChainRepo) domain.AuditChainReport {
+	t.Helper()
+	report, err := service.NewAuditService(repo, chain).VerifyChain(context.Background())
+	require.NoError(t, err)
+	return report
+}
+
+func TestVerifyChain_Valid(t *testing.T) {
+	chain := hashchain.New("secret")
+	repo := newChainRepo(chain, 1, 5)
+
+	report := verify(t, chain, repo)
+
+	assert.True(t, report.Valid)
+	assert.Nil(t, report.Broken)
+	assert.Equal(t, 5, report.Checked)
+	assert.Equal(t, 1, report.FirstID)
+	assert.Equal(t, 5, report.LastID)
+	assert.NotEmpty(t, report.Head)
+}
+
+func TestVerifyChain_Empty(t *testing.T) {
+	chain := hashchain.New("")
+
+	report := verify(t, chain, &fakeChainRepo{})
+
+	assert.True(t, report.Valid)
+	assert.Zero(t, report.Checked)
+}
+
+func TestVerifyChain_SkipsRecordsBeforeChain(t *testing.T) {
+	chain := hashchain.New("")
+	repo := newChainRepo(chain, 2, 4)
+	// Задача, сохраненная до появления цепочки
+	repo.records = append([]domain.AuditChainRecord{{ID: 1, Data: []byte(`{}`)}}, repo.records...)
+
+	report := verify(t, chain, repo)
+
+	assert.True(t, report.Valid)
+	assert.Equal(t, 2, report.FirstID)
+	assert.Equal(t, 3, report.Checked)
+}
+
+func TestVerifyChain_ChangedRecord(t *testing.T) {
+	chain := hashchain.New("secret")
+	repo := newChainRepo(chain, 1, 5)
+	repo.records[2].Data = []byte(`{"RequestID": "forged"}`)
+
+	report := verify(t, chain, repo)
+
+	assert.False(t, report.Valid)
+	require.NotNil(t, report.Broken)
+	assert.Equal(t, 3, report.Broken.ID)
+	assert.Equal(t, 2, report.LastID)
+}
+
+func TestVerifyChain_DeletedRecord(t *testing.T) {
+	chain := hashchain.New("secret")
+	repo := newChainRepo(chain, 1, 5)
+	repo.delete(3)
+
+	report := verify(t, chain, repo)
+
+	assert.False(t, report.Valid)
+	require.NotNil(t, report.Broken)
+	assert.Equal(t, 4, report.Broken.ID)
+}
+
+func TestVerifyChain_DeletedTail(t *testing.T) {
+	chain := hashchain.New("secret")
+	repo := newChainRepo(chain, 1, 5)
+	repo.delete(5)
+	repo.delete(4)
+
+	report := verify(t, chain, repo)
+
+	assert.False(t, report.Valid)
+	require.NotNil(t, report.Broken)
+	assert.Equal(t, 5, report.Broken.ID)
+	assert.Equal(t, 3, report.LastID)
+}
+
+func TestVerifyChain_RecordWithoutHash(t *testing.T) {
+	chain := hashchain.New("secret")
+	repo := newChainRepo(chain, 1, 5)
+	repo.records[3].Hash = nil
+
+	report := verify(t, chain, repo)
+
+	require.NotNil(t, report.Broken)
+	assert.Equal(t, 4, report.Broken.ID)
+	assert.Contains(t, report.Broken.Reason, "в обход цепочки")
+}
+
+func TestVerifyChain_IgnoresRecordsAfterHead(t *testing.T) {
+	chain := hashchain.New("secret")
+	repo := newChainRepo(chain, 1, 5)
+	// Звенья 4 и 5 добавлены после чтения головы
+	repo.head = domain.AuditChainHead{LastID: 3, Hash: repo.records[2].Hash}
+
+	report := verify(t, chain, repo)
+
+	assert.True(t, report.Valid)
+	assert.Equal(t, 3, report.Checked)
+}
@@ -0,0 +1,337 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/cache"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/middleware"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/order"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/interceptor"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryStore — хранилище ответов в памяти вместо Redis. Время жизни
+// ключей не учитывается, истечение блокировки имитирует Expire. Как и клиент
+// Redis, не пишет с отмененным контекстом.
+type memoryStore struct {
+	mu        sync.Mutex
+	responses map[string]domain.IdempotentResponse
+}
+
+func (s *memoryStore) Expire(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.responses, key)
+}
+
+func (s *memoryStore) Get(key string) domain.IdempotentResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.responses[key]
+}
+
+// ownedBy сообщает, занят ли ключ резервированием с токеном token.
+func (s *memoryStore) ownedBy(key, token string) bool {
+	saved, ok := s.responses[key]
+	return ok && saved.Pending && saved.Token == token
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{responses: make(map[string]domain.IdempotentResponse)}
+}
+
+func (s *memoryStore) Reserve(_ context.Context, key string, pending domain.IdempotentResponse, _ time.Duration) (*domain.IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if saved, ok := s.responses[key]; ok {
+		return &saved, nil
+	}
+	s.responses[key] = pending
+	return nil, nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, key, token string, response domain.IdempotentResponse, _ time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ownedBy(key, token) {
+		return cache.ErrReservationLost
+	}
+	s.responses[key] = response
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ownedBy(key, token) {
+		return cache.ErrReservationLost
+	}
+	delete(s.responses, key)
+	return nil
+}
+
+func setupRouter(store *memoryStore, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	idempotency := service.NewIdempotencyService(store, time.Hour, time.Minute)
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(domain.ContextWithActor(c.Request.Context(), c.GetHeader("X-User")))
+	}, middleware.IdempotencyMiddleware(idempotency, zap.NewNop().Sugar()))
+	router.POST("/orders", handler)
+	return router
+}
+
+func doRequest(router *gin.Engine, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(domain.IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddlewareReplaysFirstResponse(t *testing.T) {
+	store := newMemoryStore()
+	calls := 0
+	router := setupRouter(store, func(c *gin.Context) {
+		calls++
+		if calls > 1 {
+			c.JSON(http.StatusConflict, gin.H{"error": domain.ErrDuplicateOrder.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "заказ принят"})
+	})
+
+	first := doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`)
+	retry := doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`)
+
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+
+	// Без ключа и с ключом другого пользователя запрос выполняется заново
+	assert.Equal(t, http.StatusConflict, doRequest(router, "dev@ozon.ru", "", `{"id":"1"}`).Code)
+	assert.Equal(t, http.StatusConflict, doRequest(router, "ops@ozon.ru", "key-1", `{"id":"1"}`).Code)
+}
+
+func TestIdempotencyMiddlewareRejectsReusedKey(t *testing.T) {
+	store := newMemoryStore()
+	router := setupRouter(store, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"message": "заказ принят"})
+	})
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`).Code)
+
+	resp := doRequest(router, "dev@ozon.ru", "key-1", `{"id":"2"}`)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), domain.ErrIdempotencyKeyReused.Error())
+
+	resp = doRequest(router, "dev@ozon.ru", strings.Repeat("k", domain.MaxIdempotencyKeyLength+1), `{"id":"1"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestIdempotencyMiddlewareDoesNotKeepServerErrors(t *testing.T) {
+	store := newMemoryStore()
+	calls := 0
+	router := setupRouter(store, func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrDatabase.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "заказ принят"})
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`).Code)
+	assert.Equal(t, http.StatusCreated, doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`).Code)
+	assert.Equal(t, http.StatusCreated, doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`).Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyInterceptorReplaysResponsesAndErrors(t *testing.T) {
+	store := newMemoryStore()
+	idempotency := service.NewIdempotencyService(store, time.Hour, time.Minute)
+	intercept := interceptor.IdempotencyInterceptor(idempotency, zap.NewNop().Sugar())
+
+	calls := 0
+	handler := func(_ context.Context, req any) (any, error) {
+		calls++
+		if req.(*order.AcceptOrderRequest).GetId() == "missing" {
+			return nil, status.Error(codes.InvalidArgument, "неверный заказ")
+		}
+		return &order.AcceptOrderResponse{Message: "заказ принят"}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: order.OrderHandler_AcceptOrder_FullMethodName}
+	call := func(key, id string) (any, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("idempotency-key", key))
+		ctx = domain.ContextWithActor(ctx, "dev@ozon.ru")
+		return intercept(ctx, &order.AcceptOrderRequest{Id: id}, info, handler)
+	}
+
+	first, err := call("key-1", "1")
+	require.NoError(t, err)
+	retry, err := call("key-1", "1")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(first.(proto.Message), retry.(proto.Message)))
+
+	_, err = call("key-1", "2")
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = call("key-2", "missing")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = call("key-2", "missing")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "неверный заказ", status.Convert(err).Message())
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyServiceKeepsReservationOfRetry(t *testing.T) {
+	store := newMemoryStore()
+	idempotency := service.NewIdempotencyService(store, time.Hour, time.Minute)
+	ctx := domain.ContextWithActor(context.Background(), "dev@ozon.ru")
+	req := domain.IdempotentRequest{Scope: "http", Key: "key-1", Fingerprint: "f"}
+	storeKey := "http:dev@ozon.ru:key-1"
+
+	saved, first, err := idempotency.Begin(ctx, req)
+	require.NoError(t, err)
+	require.Nil(t, saved)
+	require.NotEmpty(t, first)
+
+	// Первый запрос выполняется дольше блокировки, ключ занимает повтор
+	store.Expire(storeKey)
+	_, retry, err := idempotency.Begin(ctx, req)
+	require.NoError(t, err)
+	require.NotEqual(t, first, retry)
+
+	// Опоздавший первый запрос не перезаписывает и не освобождает ключ
+	err = idempotency.Finish(ctx, req, first, domain.IdempotentResponse{Code: http.StatusInternalServerError})
+	assert.ErrorIs(t, err, cache.ErrReservationLost)
+	assert.ErrorIs(t, idempotency.Abort(ctx, req, first), cache.ErrReservationLost)
+	assert.True(t, store.Get(storeKey).Pending)
+
+	require.NoError(t, idempotency.Finish(ctx, req, retry, domain.IdempotentResponse{Code: http.StatusCreated}))
+	saved, _, err = idempotency.Begin(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, http.StatusCreated, saved.Code)
+	assert.Empty(t, saved.Token)
+}
+
+func TestIdempotencyMiddlewareDoesNotOverwriteRetry(t *testing.T) {
+	store := newMemoryStore()
+	var router *gin.Engine
+	calls := 0
+	router = setupRouter(store, func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			// Пока выполняется первый запрос, блокировка истекает и повтор
+			// выполняется полностью
+			store.Expire("http:dev@ozon.ru:key-1")
+			retry := doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`)
+			require.Equal(t, http.StatusCreated, retry.Code)
+			c.JSON(http.StatusConflict, gin.H{"error": domain.ErrDuplicateOrder.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"message": "заказ принят"})
+	})
+
+	assert.Equal(t, http.StatusConflict, doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`).Code)
+
+	// Сохранен ответ повтора, а не опоздавшего первого запроса
+	resp := doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "true", resp.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddlewareSavesResponseAfterClientLeft(t *testing.T) {
+	store := newMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	router := setupRouter(store, func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			// Терминал не дождался ответа, но заказ уже принят
+			cancel()
+			c.JSON(http.StatusCreated, gin.H{"message": "заказ принят"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": domain.ErrDuplicateOrder.Error()})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"id":"1"}`)).WithContext(ctx)
+	req.Header.Set("X-User", "dev@ozon.ru")
+	req.Header.Set(domain.IdempotencyHeader, "key-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	retry := doRequest(router, "dev@ozon.ru", "key-1", `{"id":"1"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencyInterceptorFinishesAfterClientLeft(t *testing.T) {
+	store := newMemoryStore()
+	idempotency := service.NewIdempotencyService(store, time.Hour, time.Minute)
+	intercept := interceptor.IdempotencyInterceptor(idempotency, zap.NewNop().Sugar())
+	info := &grpc.UnaryServerInfo{FullMethod: order.OrderHandler_AcceptOrder_FullMethodName}
+
+	call := func(handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("idempotency-key", "key-1"))
+		ctx = domain.ContextWithActor(ctx, "dev@ozon.ru")
+		return intercept(ctx, &order.AcceptOrderRequest{Id: "1"}, info, func(ctx context.Context, req any) (any, error) {
+			resp, err := handler(ctx, req)
+			// Клиент отключился, пока вызов выполнялся
+			cancel()
+			return resp, err
+		})
+	}
+
+	// Ключ освобождается и после отмены: повтор не ждет истечения блокировки
+	_, err := call(func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Unavailable, "база недоступна")
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Empty(t, store.Get("grpc:dev@ozon.ru:key-1").Fingerprint)
+
+	_, err = call(func(context.Context, any) (any, error) {
+		return &order.AcceptOrderResponse{Message: "заказ принят"}, nil
+	})
+	require.NoError(t, err)
+
+	retry, err := call(func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.AlreadyExists, domain.ErrDuplicateOrder.Error())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "заказ принят", retry.(*order.AcceptOrderResponse).GetMessage())
+}
@@ -0,0 +1,226 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/jwtkeys"
+)
+
+// secret — секрет HS256 минимальной допустимой длины.
+var secret = strings.Repeat("s", jwtkeys.MinSecretLength)
+
+type testKeys struct {
+	set       *jwtkeys.KeySet
+	rsa       *rsa.PrivateKey
+	rsaPublic []byte
+	ed25519   ed25519.PrivateKey
+}
+
+// newKeySet собирает набор из RSA (ключ подписи), Ed25519 и секрета HS256.
+func newKeySet(t *testing.T) testKeys {
+	t.Helper()
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPath := writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	rsaPublic := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	edPath := writePEM(t, dir, "ed.pem", "PRIVATE KEY", edDER)
+
+	secretPath := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte(secret+"\n"), 0o600))
+
+	set, err := jwtkeys.Load(map[string]string{"rsa": rsaPath, "ed": edPath, "hs": secretPath}, "rsa", "")
+	require.NoError(t, err)
+
+	return testKeys{set: set, rsa: rsaKey, rsaPublic: rsaPublic, ed25519: edKey}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "user@example.com"})
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	keys := newKeySet(t)
+
+	signed, err := keys.set.Sign(jwt.MapClaims{"sub": "user@example.com"})
+	require.NoError(t, err)
+
+	token, err := jwt.Parse(signed, keys.set.Keyfunc)
+	require.NoError(t, err)
+	assert.Equal(t, "rsa", token.Header["kid"])
+	assert.Equal(t, jwt.SigningMethodRS256.Alg(), token.Method.Alg())
+
+	// Токены, подписанные другими ключами набора, тоже проверяются
+	_, err = jwt.Parse(sign(t, jwt.SigningMethodEdDSA, "ed", keys.ed25519), keys.set.Keyfunc)
+	assert.NoError(t, err)
+	_, err = jwt.Parse(sign(t, jwt.SigningMethodHS256, "hs", []byte(secret)), keys.set.Keyfunc)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_RejectsForgedTokens(t *testing.T) {
+	keys := newKeySet(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:    "alg none",
+			token:   sign(t, jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType),
+			wantErr: jwtkeys.ErrAlgorithmMismatch,
+		},
+		{
+			// Публичный ключ RSA известен всем, подпись им как секретом HS256
+			// не должна приниматься
+			name:    "HS256 with RSA public key",
+			token:   sign(t, jwt.SigningMethodHS256, "rsa", keys.rsaPublic),
+			wantErr: jwtkeys.ErrAlgorithmMismatch,
+		},
+		{
+			name:    "RS256 with HS256 kid",
+			token:   sign(t, jwt.SigningMethodRS256, "hs", otherKey),
+			wantErr: jwtkeys.ErrAlgorithmMismatch,
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodRS256, "unknown", keys.rsa),
+			wantErr: jwtkeys.ErrUnknownKeyID,
+		},
+		{
+			name:    "no kid",
+			token:   sign(t, jwt.SigningMethodRS256, "", keys.rsa),
+			wantErr: jwtkeys.ErrUnknownKeyID,
+		},
+		{
+			name:    "signed by another RSA key",
+			token:   sign(t, jwt.SigningMethodRS256, "rsa", otherKey),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.Parse(tt.token, keys.set.Keyfunc)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	publicPath := writePEM(t, dir, "public.pem", "PUBLIC KEY", publicDER)
+
+	_, err = jwtkeys.Load(nil, "", "")
+	assert.ErrorIs(t, err, jwtkeys.ErrNoSigningKey)
+
+	// Публичным ключом подписывать нельзя
+	_, err = jwtkeys.Load(map[string]string{"pub": publicPath}, "pub", "")
+	assert.ErrorIs(t, err, jwtkeys.ErrNoSigningKey)
+
+	_, err = jwtkeys.Load(map[string]string{"missing": filepath.Join(dir, "missing.pem")}, "missing", "")
+	assert.Error(t, err)
+
+	set, err := jwtkeys.Load(nil, "", secret)
+	require.NoError(t, err)
+	signed, err := set.Sign(jwt.MapClaims{"sub": "user@example.com"})
+	require.NoError(t, err)
+	token, err := jwt.Parse(signed, set.Keyfunc)
+	require.NoError(t, err)
+	assert.Equal(t, "default", token.Header["kid"])
+}
+
+func TestLoad_RejectsWeakOrBrokenKeys(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	full := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	// Обрезанный PEM не должен стать секретом HS256
+	truncated := filepath.Join(dir, "truncated.pem")
+	require.NoError(t, os.WriteFile(truncated, full[:len(full)/2], 0o600))
+	_, err = jwtkeys.Load(map[string]string{"rsa": truncated}, "rsa", "")
+	assert.ErrorIs(t, err, jwtkeys.ErrMalformedPEM)
+
+	short := filepath.Join(dir, "short")
+	require.NoError(t, os.WriteFile(short, []byte("secret\n"), 0o600))
+	_, err = jwtkeys.Load(map[string]string{"hs": short}, "hs", "")
+	assert.ErrorIs(t, err, jwtkeys.ErrShortSecret)
+
+	_, err = jwtkeys.Load(nil, "", "secret")
+	assert.ErrorIs(t, err, jwtkeys.ErrShortSecret)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	keys := newKeySet(t)
+
+	jwks := keys.set.JWKS()
+
+	// Секрет HS256 не публикуется, ключи отсортированы по kid
+	require.Len(t, jwks.Keys, 2)
+
+	ed := jwks.Keys[0]
+	assert.Equal(t, jwtkeys.JWK{
+		Kty: "OKP",
+		Kid: "ed",
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(keys.ed25519.Public().(ed25519.PublicKey)),
+	}, ed)
+
+	rsaJWK := jwks.Keys[1]
+	assert.Equal(t, "RSA", rsaJWK.Kty)
+	assert.Equal(t, "rsa", rsaJWK.Kid)
+	assert.Equal(t, "sig", rsaJWK.Use)
+	assert.Equal(t, "RS256", rsaJWK.Alg)
+	assert.Empty(t, rsaJWK.Crv)
+	assert.Empty(t, rsaJWK.X)
+
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	require.NoError(t, err)
+	assert.Equal(t, 0, keys.rsa.N.Cmp(new(big.Int).SetBytes(n)))
+	assert.Equal(t, int64(keys.rsa.E), new(big.Int).SetBytes(e).Int64())
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/middleware"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func serve(t *testing.T, trustedProxies []string, header map[string]string) (domain.RequestInfo, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(trustedProxies))
+	router.Use(middleware.RequestInfoMiddleware())
+
+	var info domain.RequestInfo
+	router.GET("/", func(c *gin.Context) {
+		info = domain.RequestInfoFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return info, w
+}
+
+func TestRequestInfoMiddleware_RequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{"valid", "req-123_ABC", true},
+		{"max length", strings.Repeat("a", domain.MaxRequestIDLength), true},
+		{"empty", "", false},
+		{"too long", strings.Repeat("a", domain.MaxRequestIDLength+1), false},
+		{"spaces", "req 123", false},
+		{"newline", "req\n123", false},
+		{"cyrillic", "запрос", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, w := serve(t, nil, map[string]string{middleware.RequestIDHeader: tt.requestID})
+
+			assert.Equal(t, info.RequestID, w.Header().Get(middleware.RequestIDHeader))
+			if tt.keep {
+				assert.Equal(t, tt.requestID, info.RequestID)
+				return
+			}
+			_, err := uuid.Parse(info.RequestID)
+			assert.NoError(t, err, "вместо request ID клиента должен быть сгенерирован UUID")
+		})
+	}
+}
+
+func TestRequestInfoMiddleware_ClientIP(t *testing.T) {
+	header := map[string]string{"X-Forwarded-For": "203.0.113.7"}
+
+	// Без доверенных прокси заголовок игнорируется
+	info, _ := serve(t, nil, header)
+	assert.Equal(t, "10.0.0.1", info.ClientIP)
+
+	info, _ = serve(t, []string{"192.168.0.0/16"}, header)
+	assert.Equal(t, "10.0.0.1", info.ClientIP)
+
+	info, _ = serve(t, []string{"10.0.0.0/8"}, header)
+	assert.Equal(t, "203.0.113.7", info.ClientIP)
+}
+
+func TestRequestInfoInterceptor_ClientIP(t *testing.T) {
+	tests := []struct {
+		addr net.Addr
+		want string
+	}{
+		{&net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 54321}, "203.0.113.7"},
+		{&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 54321}, "2001:db8::1"},
+		{&net.UnixAddr{Name: "/tmp/grpc.sock", Net: "unix"}, "/tmp/grpc.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.addr})
+
+			var info domain.RequestInfo
+			_, err := interceptor.RequestInfoInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+				info = domain.RequestInfoFromContext(ctx)
+				return nil, nil
+			})
+
+			require.NoError(t, err)
+			// IP без порта, как в REST
+			assert.Equal(t, tt.want, info.ClientIP)
+		})
+	}
+}
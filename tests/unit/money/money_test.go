@@ -0,0 +1,117 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/storage/postgres/storageutils"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount  string
+		want    int64
+		wantErr bool
+	}{
+		{"1000", 100000, false},
+		{"99.9", 9990, false},
+		{"99.99", 9999, false},
+		{"0.1", 10, false},
+		{"99.999", 0, true},
+		{"-5", 0, true},
+		{"1e3", 0, true},
+		{".5", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		money, err := domain.ParseMoney(tt.amount, "")
+		if tt.wantErr {
+			assert.ErrorIs(t, err, domain.ErrInvalidMoney, tt.amount)
+			continue
+		}
+		require.NoError(t, err, tt.amount)
+		assert.Equal(t, domain.Money{MinorUnits: tt.want, Currency: domain.CurrencyRUB}, money, tt.amount)
+	}
+
+	_, err := domain.ParseMoney("10", "XXX")
+	assert.ErrorIs(t, err, domain.ErrUnknownCurrency)
+}
+
+func TestMoney_Amount(t *testing.T) {
+	assert.Equal(t, "0.05", domain.Money{MinorUnits: 5, Currency: domain.CurrencyRUB}.Amount())
+	assert.Equal(t, "1000.00", domain.Rubles(1000).Amount())
+	assert.Equal(t, "99.90 RUB", domain.Money{MinorUnits: 9990, Currency: domain.CurrencyRUB}.String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	var order domain.Order
+	require.NoError(t, json.Unmarshal([]byte(`{"base_price": {"minor_units": 9990, "currency": "USD"}}`), &order))
+	assert.Equal(t, domain.Money{MinorUnits: 9990, Currency: domain.CurrencyUSD}, order.BasePrice)
+
+	data, err := json.Marshal(order.BasePrice)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"minor_units": 9990, "currency": "USD"}`, string(data))
+
+	// Старый формат: число или строка в рублях
+	require.NoError(t, json.Unmarshal([]byte(`{"base_price": 0.3}`), &order))
+	assert.Equal(t, domain.Money{MinorUnits: 30, Currency: domain.CurrencyRUB}, order.BasePrice)
+	require.NoError(t, json.Unmarshal([]byte(`{"base_price": "1000"}`), &order))
+	assert.Equal(t, domain.Rubles(1000), order.BasePrice)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"base_price": {"minor_units": 1, "currency": "XXX"}}`), &order))
+	assert.Error(t, json.Unmarshal([]byte(`{"base_price": 0.001}`), &order))
+}
+
+func TestCompositePackaging_CalculatePrice(t *testing.T) {
+	packaging := domain.CompositePackaging{Strategies: []domain.PackagingStrategy{
+		domain.PackagingBox{},
+		domain.PackagingFilm{},
+	}}
+
+	assert.Equal(t, domain.Rubles(21), packaging.CalculatePrice())
+}
+
+func TestMoneyFromNumeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		numeric pgtype.Numeric
+		want    int64
+		wantErr bool
+	}{
+		{"two digits", pgtype.Numeric{Int: big.NewInt(9990), Exp: -2, Valid: true}, 9990, false},
+		{"positive exponent", pgtype.Numeric{Int: big.NewInt(1), Exp: 3, Valid: true}, 100000, false},
+		{"trailing zeros", pgtype.Numeric{Int: big.NewInt(50000), Exp: -4, Valid: true}, 500, false},
+		{"too many digits", pgtype.Numeric{Int: big.NewInt(12345), Exp: -3, Valid: true}, 0, true},
+		{"null", pgtype.Numeric{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		money, err := storageutils.MoneyFromNumeric(tt.numeric, domain.CurrencyRUB)
+		if tt.wantErr {
+			assert.Error(t, err, tt.name)
+			continue
+		}
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, money.MinorUnits, tt.name)
+	}
+
+	price := domain.Money{MinorUnits: 123456, Currency: domain.CurrencyRUB}
+	numeric, err := storageutils.Numeric(price)
+	require.NoError(t, err)
+	money, err := storageutils.MoneyFromNumeric(numeric, price.Currency)
+	require.NoError(t, err)
+	assert.Equal(t, price, money)
+}
+
+func TestNumeric_RejectsUnknownCurrency(t *testing.T) {
+	for _, currency := range []domain.Currency{"", "XXX"} {
+		_, err := storageutils.Numeric(domain.Money{MinorUnits: 100, Currency: currency})
+		assert.ErrorIs(t, err, domain.ErrUnknownCurrency, "валюта %q", currency)
+	}
+}
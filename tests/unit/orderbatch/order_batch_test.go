@@ -0,0 +1,121 @@
+package orderbatch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+var changedAt = time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+
+// step — итог обработки одного заказа: ошибка проверки или перевод в новый
+// статус.
+type step struct {
+	id  string
+	err error
+}
+
+// run обрабатывает пачку так же, как хранилище: заказ с ошибкой
+// отмечается через Fail, прошедший проверки — через Processed или Skip.
+func run(atomic bool, steps []step) domain.ProcessedOrders {
+	batch := domain.NewOrderBatch(atomic, len(steps))
+	ids := make([]string, 0, len(steps))
+	for _, s := range steps {
+		ids = append(ids, s.id)
+		switch {
+		case s.err != nil:
+			batch.Fail(s.id, s.err)
+		case !batch.CanApply():
+			batch.Skip(s.id)
+		default:
+			batch.Processed(s.id, changedAt)
+		}
+	}
+	return batch.Result("user1", ids)
+}
+
+func statuses(result domain.ProcessedOrders) []domain.OrderResultStatus {
+	var statuses []domain.OrderResultStatus
+	for _, r := range result.Results {
+		statuses = append(statuses, r.Status)
+	}
+	return statuses
+}
+
+var mixedBatch = []step{
+	{id: "1"},
+	{id: "2", err: fmt.Errorf("%w: 2", domain.ErrNotFoundOrder)},
+	{id: "3"},
+	{id: "4", err: domain.ErrExpiredOrder},
+	{id: "5", err: &domain.ErrUserDoesntOwnOrder{OrderID: "5", UserID: "user1"}},
+	{id: "6"},
+}
+
+func TestOrderBatch_MixedWithoutAtomic(t *testing.T) {
+	result := run(false, mixedBatch)
+
+	// Ошибка проверки не останавливает обработку следующих заказов
+	assert.Equal(t, []string{"1", "3", "6"}, result.OrderIDs)
+	assert.Equal(t, []string{"2", "4", "5"}, result.Failed)
+	assert.Equal(t, []domain.OrderResultStatus{
+		domain.OrderResultProcessed,
+		domain.OrderResultFailed,
+		domain.OrderResultProcessed,
+		domain.OrderResultFailed,
+		domain.OrderResultFailed,
+		domain.OrderResultProcessed,
+	}, statuses(result))
+
+	require.Len(t, result.Results, 6)
+	assert.Equal(t, domain.ReasonNotFound, result.Results[1].Reason)
+	assert.Equal(t, domain.ReasonExpired, result.Results[3].Reason)
+	assert.Equal(t, domain.ReasonNotOwner, result.Results[4].Reason)
+	assert.Equal(t, changedAt, *result.Results[5].ChangedAt)
+
+	// В Error — первая ошибка
+	assert.ErrorIs(t, result.Error, domain.ErrNotFoundOrder)
+}
+
+func TestOrderBatch_MixedAtomic(t *testing.T) {
+	result := run(true, mixedBatch)
+
+	assert.Empty(t, result.OrderIDs)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6"}, result.Failed)
+	assert.Equal(t, []domain.OrderResultStatus{
+		domain.OrderResultRolledBack,
+		domain.OrderResultFailed,
+		domain.OrderResultRolledBack,
+		domain.OrderResultFailed,
+		domain.OrderResultFailed,
+		domain.OrderResultRolledBack,
+	}, statuses(result))
+	assert.Equal(t, domain.ReasonBatchRolledBack, result.Results[0].Reason)
+	assert.ErrorIs(t, result.Error, domain.ErrNotFoundOrder)
+}
+
+func TestOrderBatch_AllProcessed(t *testing.T) {
+	for _, atomic := range []bool{false, true} {
+		batch := domain.NewOrderBatch(atomic, 2)
+		batch.Processed("1", changedAt)
+		batch.Processed("2", changedAt)
+
+		result := batch.Result("user1", []string{"1", "2"})
+
+		assert.False(t, batch.RolledBack())
+		assert.Equal(t, []string{"1", "2"}, result.OrderIDs)
+		assert.Empty(t, result.Failed)
+		assert.NoError(t, result.Error)
+	}
+}
+
+func TestUniqueOrderIDs(t *testing.T) {
+	// С atomic повтор заказа иначе откатил бы всю пачку: второе вхождение
+	// видит заказ уже выданным
+	assert.Equal(t, []string{"A", "B", "C"}, domain.UniqueOrderIDs([]string{"A", "B", "A", "C", "B"}))
+	assert.Equal(t, []string{"A"}, domain.UniqueOrderIDs([]string{"A", "A"}))
+	assert.Empty(t, domain.UniqueOrderIDs(nil))
+}
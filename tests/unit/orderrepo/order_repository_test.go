@@ -0,0 +1,102 @@
+package orderrepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/repository/orderrepo"
+	"go.uber.org/zap"
+)
+
+// fakeStorage — хранилище заказов в памяти.
+type fakeStorage struct {
+	orders    map[string]*domain.Order
+	history   map[string][]domain.OrderStatusChange
+	err       error
+	returnErr error
+}
+
+func (s *fakeStorage) SaveOrder(context.Context, domain.Order) error { return nil }
+
+func (s *fakeStorage) FindOrderByID(_ context.Context, id string) (*domain.Order, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	order, ok := s.orders[id]
+	if !ok {
+		return nil, domain.ErrNotFoundOrder
+	}
+	return order, nil
+}
+
+func (s *fakeStorage) FindOrdersByIDs(context.Context, []string) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+func (s *fakeStorage) ReturnOrder(context.Context, string) error { return s.returnErr }
+
+func (s *fakeStorage) GetStatusHistory(_ context.Context, id string) ([]domain.OrderStatusChange, error) {
+	return s.history[id], nil
+}
+
+func (s *fakeStorage) ReturnExpiredOrders(context.Context, int, bool) ([]domain.Order, error) {
+	return nil, nil
+}
+
+func TestGetStatusHistory(t *testing.T) {
+	change := domain.OrderStatusChange{OrderID: "1", To: domain.StatusStored, ChangedAt: time.Now().UTC()}
+	storage := &fakeStorage{
+		orders: map[string]*domain.Order{"1": {ID: "1"}, "2": {ID: "2"}},
+		history: map[string][]domain.OrderStatusChange{
+			"1": {change},
+			// Заказ, удаленный при возврате курьеру до появления статуса returned_to_courier
+			"3": {change},
+		},
+	}
+	repo := orderrepo.NewOrderRepository(storage, zap.NewNop().Sugar())
+
+	history, err := repo.GetStatusHistory(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.OrderStatusChange{change}, history)
+
+	history, err = repo.GetStatusHistory(context.Background(), "2")
+	require.NoError(t, err)
+	assert.NotNil(t, history)
+	assert.Empty(t, history)
+
+	history, err = repo.GetStatusHistory(context.Background(), "3")
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	_, err = repo.GetStatusHistory(context.Background(), "4")
+	assert.ErrorIs(t, err, domain.ErrNotFoundOrder)
+
+	storage.err = errors.New("connection refused")
+	_, err = repo.GetStatusHistory(context.Background(), "5")
+	assert.ErrorIs(t, err, domain.ErrDatabase)
+}
+
+func TestReturnOrder(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	storage := &fakeStorage{orders: map[string]*domain.Order{
+		"1": {ID: "1", Expiry: expired, State: domain.StatusStored},
+		"2": {ID: "2", Expiry: expired, State: domain.StatusIssued},
+	}}
+	repo := orderrepo.NewOrderRepository(storage, zap.NewNop().Sugar())
+
+	require.NoError(t, repo.ReturnOrder(context.Background(), "1"))
+	assert.ErrorIs(t, repo.ReturnOrder(context.Background(), "2"), domain.ErrNotStoredOrder)
+	assert.ErrorIs(t, repo.ReturnOrder(context.Background(), "3"), domain.ErrNotFoundOrder)
+
+	// Заказ вернула задача возврата между проверкой и блокировкой строки
+	storage.returnErr = &domain.ErrInvalidTransition{From: domain.StatusReturnedToCourier, To: domain.StatusReturnedToCourier}
+	assert.ErrorIs(t, repo.ReturnOrder(context.Background(), "1"), domain.ErrNotStoredOrder)
+
+	storage.returnErr = errors.New("connection refused")
+	assert.ErrorIs(t, repo.ReturnOrder(context.Background(), "1"), domain.ErrDatabase)
+}
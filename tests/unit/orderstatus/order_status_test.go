@@ -0,0 +1,72 @@
+package orderstatus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+)
+
+func TestCanTransitionTo(t *testing.T) {
+	statuses := []domain.OrderStatus{
+		"",
+		domain.StatusStored,
+		domain.StatusIssued,
+		domain.StatusRefunded,
+		domain.StatusReturnedToCourier,
+	}
+	allowed := map[domain.OrderStatus][]domain.OrderStatus{
+		"":                  {domain.StatusStored},
+		domain.StatusStored: {domain.StatusIssued, domain.StatusReturnedToCourier},
+		domain.StatusIssued: {domain.StatusRefunded},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, next := range allowed[from] {
+				if next == to {
+					want = true
+				}
+			}
+			assert.Equal(t, want, from.CanTransitionTo(to), "%q -> %q", from, to)
+		}
+	}
+}
+
+func TestTransitionTo(t *testing.T) {
+	ctx := domain.ContextWithActor(context.Background(), "manager@example.com")
+	storedAt := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	order := domain.Order{ID: "1", StoredAt: &storedAt}
+	issuedAt := storedAt.Add(time.Hour)
+
+	change, err := order.TransitionTo(ctx, domain.StatusIssued, issuedAt)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusChange{
+		OrderID:   "1",
+		From:      domain.StatusStored,
+		To:        domain.StatusIssued,
+		Actor:     "manager@example.com",
+		ChangedAt: issuedAt,
+	}, change)
+	assert.Equal(t, domain.StatusIssued, order.Status())
+	assert.Equal(t, &issuedAt, order.IssuedAt)
+}
+
+func TestTransitionTo_Invalid(t *testing.T) {
+	storedAt := time.Now().UTC()
+	order := domain.Order{ID: "1", StoredAt: &storedAt}
+
+	_, err := order.TransitionTo(context.Background(), domain.StatusRefunded, time.Now().UTC())
+
+	var transition *domain.ErrInvalidTransition
+	require.ErrorAs(t, err, &transition)
+	assert.Equal(t, domain.StatusStored, transition.From)
+	assert.Equal(t, domain.StatusRefunded, transition.To)
+	assert.Equal(t, domain.StatusStored, order.Status())
+	assert.Nil(t, order.RefundedAt)
+}
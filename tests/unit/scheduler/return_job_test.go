@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/scheduler"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/service"
+	"go.uber.org/zap"
+)
+
+// fakeOrderService реализует только ReturnExpiredOrders, остальные методы
+// в задаче не используются.
+type fakeOrderService struct {
+	service.OrderService
+
+	mu     sync.Mutex
+	orders []domain.Order
+	calls  []returnCall
+	called chan struct{}
+}
+
+type returnCall struct {
+	limit  int
+	dryRun bool
+	actor  string
+}
+
+func (s *fakeOrderService) ReturnExpiredOrders(ctx context.Context, limit int, dryRun bool) ([]domain.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, returnCall{limit: limit, dryRun: dryRun, actor: domain.ActorFromContext(ctx)})
+	if len(s.calls) == 1 {
+		close(s.called)
+	}
+
+	orders := s.orders
+	// Повторный запуск не находит уже возвращенные заказы
+	s.orders = nil
+	return orders, nil
+}
+
+type memorySink struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (s *memorySink) Write(_ context.Context, event domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func runJob(t *testing.T, svc *fakeOrderService, dryRun bool) *memorySink {
+	logger := zap.NewNop().Sugar()
+	pipeline := audit.NewPipeline(
+		audit.QueueConfig{Capacity: 10, Policy: audit.OverflowBlock, BlockTimeout: time.Second},
+		audit.BatchConfig{Size: 1, FlushInterval: 10 * time.Millisecond},
+		logger,
+	)
+	sink := &memorySink{}
+	require.NoError(t, pipeline.AddSink("memory", sink, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pipeline.StartWorkers(ctx)
+
+	job := scheduler.NewReturnJob(svc, pipeline, logger, 10*time.Millisecond, 50, dryRun)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job.Run(ctx)
+	}()
+
+	select {
+	case <-svc.called:
+	case <-time.After(time.Second):
+		t.Fatal("задача не запустилась")
+	}
+	cancel()
+	<-done
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+	defer shutdownCancel()
+	require.NoError(t, pipeline.Shutdown(shutdownCtx))
+
+	return sink
+}
+
+func TestReturnJob_ReturnsExpiredOrders(t *testing.T) {
+	svc := &fakeOrderService{
+		orders: []domain.Order{{ID: "1"}, {ID: "2"}},
+		called: make(chan struct{}),
+	}
+
+	sink := runJob(t, svc, false)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	assert.Equal(t, returnCall{limit: 50, dryRun: false, actor: domain.ActorReturnJob}, svc.calls[0])
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.events, 2)
+	for i, id := range []string{"1", "2"} {
+		event := sink.events[i]
+		assert.Equal(t, domain.EventStatusChange, event.Type)
+		assert.Equal(t, domain.ActorReturnJob, event.User)
+
+		var data map[string]any
+		require.NoError(t, json.Unmarshal(event.Data.(json.RawMessage), &data))
+		assert.Equal(t, id, data["order_id"])
+		assert.Equal(t, string(domain.StatusReturnedToCourier), data["status"])
+	}
+}
+
+func TestReturnJob_DryRunSendsNoEvents(t *testing.T) {
+	svc := &fakeOrderService{
+		orders: []domain.Order{{ID: "1"}},
+		called: make(chan struct{}),
+	}
+
+	sink := runJob(t, svc, true)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	assert.True(t, svc.calls[0].dryRun)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	assert.Empty(t, sink.events)
+}
@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/audit/schema"
+	"gitlab.ozon.dev/sadsnake2311/homework/internal/domain"
+	eventsv1 "gitlab.ozon.dev/sadsnake2311/homework/internal/transport/grpc/gen/events/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType domain.EventType
+		data      proto.Message
+		wantErr   string
+	}{
+		{"status change", domain.EventStatusChange, &eventsv1.OrderStatusChanged{OrderId: "42", Status: "issued"}, ""},
+		{"status change without order", domain.EventStatusChange, &eventsv1.OrderStatusChanged{Status: "issued"}, "order_id"},
+		{"unknown order status", domain.EventStatusChange, &eventsv1.OrderStatusChanged{OrderId: "42", Status: "Deleted"}, "Deleted"},
+		{"wrong message for type", domain.EventStatusChange, &eventsv1.ApiRequest{}, "ApiRequest"},
+		{"unknown type", domain.EventType("order_deleted"), &eventsv1.ApiRequest{}, "order_deleted"},
+
+		// Запрос: path обязателен для http и запрещен для grpc
+		{"http request", domain.EventAPIRequest, &eventsv1.ApiRequest{Transport: "http", Method: "GET", Path: "/orders"}, ""},
+		{"http request without path", domain.EventAPIRequest, &eventsv1.ApiRequest{Transport: "http", Method: "GET"}, "path"},
+		{"grpc request", domain.EventAPIRequest, &eventsv1.ApiRequest{Transport: "grpc", Method: "/order.OrderService/GetOrder"}, ""},
+		{"grpc request with path", domain.EventAPIRequest, &eventsv1.ApiRequest{Transport: "grpc", Method: "/order.OrderService/GetOrder", Path: "/orders"}, "path"},
+		{"request without method", domain.EventAPIRequest, &eventsv1.ApiRequest{Transport: "http", Path: "/orders"}, "method"},
+		{"request without transport", domain.EventAPIRequest, &eventsv1.ApiRequest{Method: "GET", Path: "/orders"}, "transport"},
+		{"unknown transport", domain.EventAPIRequest, &eventsv1.ApiRequest{Transport: "amqp", Method: "GET"}, "amqp"},
+
+		// Ответ: http — только status, grpc — только grpc_code
+		{"http response", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "http", Method: "GET", Path: "/orders", Status: 200}, ""},
+		{"http response with unknown status", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "http", Method: "GET", Path: "/orders", Status: 999}, "999"},
+		{"http response without status", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "http", Method: "GET", Path: "/orders"}, "HTTP"},
+		{"http response with grpc code", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "http", Method: "GET", Path: "/orders", Status: 200, GrpcCode: "OK"}, "grpc_code"},
+		{"grpc response", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder", GrpcCode: "NotFound"}, ""},
+		{"grpc response with ok", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder", GrpcCode: "OK"}, ""},
+		{"grpc response with unknown code", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder", GrpcCode: "NOT_FOUND"}, "NOT_FOUND"},
+		{"grpc response without code", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder"}, "gRPC"},
+		{"grpc response with status", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder", GrpcCode: "OK", Status: 200}, "status"},
+		{"grpc response with path", domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder", GrpcCode: "OK", Path: "/orders"}, "path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.eventType, tt.data)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, schema.ErrInvalidEvent)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	eventID := uuid.NewString()
+
+	tests := []struct {
+		name      string
+		eventType domain.EventType
+		eventID   string
+		version   int
+		raw       string
+		want      proto.Message
+		wantErr   string
+	}{
+		{
+			name: "current version", eventType: domain.EventStatusChange, eventID: eventID, version: schema.Version,
+			raw:  `{"order_id": "42", "status": "issued"}`,
+			want: &eventsv1.OrderStatusChanged{OrderId: "42", Status: "issued"},
+		},
+		{
+			name: "grpc response", eventType: domain.EventAPIResponse, eventID: eventID, version: schema.Version,
+			raw:  `{"transport": "grpc", "method": "/order.OrderService/GetOrder", "grpc_code": "OK"}`,
+			want: &eventsv1.ApiResponse{Transport: "grpc", Method: "/order.OrderService/GetOrder", GrpcCode: "OK"},
+		},
+		// Записи без версии созданы до появления схем и не проверяются
+		{name: "missing version", eventType: domain.EventStatusChange, raw: `{"anything": true}`},
+		{name: "future version", eventType: domain.EventStatusChange, eventID: eventID, version: schema.Version + 1, raw: `{}`, wantErr: "версия"},
+		{name: "negative version", eventType: domain.EventStatusChange, eventID: eventID, version: -1, raw: `{}`, wantErr: "версия"},
+		{name: "missing event id", eventType: domain.EventStatusChange, version: schema.Version, raw: `{"order_id": "42", "status": "issued"}`, wantErr: "EventID"},
+		{name: "bad event id", eventType: domain.EventStatusChange, eventID: "42", version: schema.Version, raw: `{"order_id": "42", "status": "issued"}`, wantErr: "EventID"},
+		{name: "unknown type", eventType: domain.EventType("order_deleted"), eventID: eventID, version: schema.Version, raw: `{}`, wantErr: "order_deleted"},
+		{name: "unknown field", eventType: domain.EventStatusChange, eventID: eventID, version: schema.Version, raw: `{"order_id": "42", "status": "issued", "from": "stored"}`, wantErr: "from"},
+		{name: "not json", eventType: domain.EventStatusChange, eventID: eventID, version: schema.Version, raw: `"42"`, wantErr: "status_change"},
+		{name: "invalid data", eventType: domain.EventAPIRequest, eventID: eventID, version: schema.Version, raw: `{"transport": "grpc", "method": "GET", "path": "/orders"}`, wantErr: "path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.Decode(tt.eventType, tt.eventID, tt.version, json.RawMessage(tt.raw))
+
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, schema.ErrInvalidEvent)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			assert.True(t, proto.Equal(tt.want, got), "получено %v", got)
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	raw, err := schema.Encode(domain.EventAPIResponse, &eventsv1.ApiResponse{
+		Transport: "http",
+		Method:    "GET",
+		Path:      "/orders",
+		Status:    200,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"transport": "http", "method": "GET", "path": "/orders", "status": 200}`, string(raw))
+
+	_, err = schema.Encode(domain.EventAPIResponse, &eventsv1.ApiResponse{Transport: "http", Method: "GET"})
+	assert.ErrorIs(t, err, schema.ErrInvalidEvent)
+}